@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/thornzero/project-manager/internal/adrs"
@@ -27,6 +28,9 @@ import (
 	"github.com/thornzero/project-manager/internal/templates"
 )
 
+// adrWatchInterval is how often the ADR directory is checked for changes.
+const adrWatchInterval = 2 * time.Second
+
 // debugLog prints debug messages only when PROJECT_MANAGER_DEBUG is set
 func debugLog(format string, v ...interface{}) {
 	if os.Getenv("PROJECT_MANAGER_DEBUG") != "" {
@@ -86,6 +90,16 @@ func main() {
 	logParserHandler := logparser.NewLogParserHandler(srv)
 	docsHandler := docs.NewDocsHandler(srv)
 
+	// Load ADRs kept as markdown files and follow changes to them
+	if synced, err := adrsHandler.Sync(); err != nil {
+		log.Printf("ADR sync failed: %v", err)
+	} else {
+		debugLog("Synced ADRs from %s: %d created, %d updated, %d removed", synced.Dir, synced.Created, synced.Updated, synced.Removed)
+	}
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	go adrsHandler.Watch(watchCtx, adrWatchInterval)
+
 	// Create MCP server
 	mcpServer := mcp.NewServer(&mcp.Implementation{
 		Name:    "project-manager",
@@ -95,7 +109,7 @@ func main() {
 	// Add tools
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name:        "goals_list",
-		Description: "List active goals from the project",
+		Description: "List project goals, filtered by status, tag, owner, due date or text",
 	}, goalsHandler.GoalsList)
 
 	mcp.AddTool(mcpServer, &mcp.Tool{
@@ -108,9 +122,99 @@ func main() {
 		Description: "Update an existing goal",
 	}, goalsHandler.GoalsUpdate)
 
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_depend",
+		Description: "Mark a goal as blocked by another goal",
+	}, goalsHandler.GoalsDepend)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_undepend",
+		Description: "Remove a dependency between two goals",
+	}, goalsHandler.GoalsUndepend)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_next",
+		Description: "List the highest-priority active goals whose dependencies are all done",
+	}, goalsHandler.GoalsNext)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_blocked",
+		Description: "List blocked goals and the dependencies each one is waiting on",
+	}, goalsHandler.GoalsBlocked)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_workflow_get",
+		Description: "Show the goal statuses and allowed transitions configured for this project",
+	}, goalsHandler.GoalsWorkflowGet)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_workflow_set",
+		Description: "Configure the goal statuses and allowed transitions for this project",
+	}, goalsHandler.GoalsWorkflowSet)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_history",
+		Description: "Show the status transitions of a goal with timestamps",
+	}, goalsHandler.GoalsHistory)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_add_items",
+		Description: "Add acceptance criteria to a goal",
+	}, goalsHandler.GoalsAddItems)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_check_item",
+		Description: "Check or uncheck an acceptance criterion of a goal",
+	}, goalsHandler.GoalsCheckItem)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_import_todos",
+		Description: "Create or update goals from TODO and FIXME comments in the repository, completing goals whose comment was removed",
+	}, goalsHandler.GoalsImportTodos)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_export",
+		Description: "Write goals to ROADMAP.md (by milestone and status), a Kanban BOARD.md and a self-contained board.html, or as todo.txt, Taskwarrior and GitHub issues JSON",
+	}, goalsHandler.GoalsExport)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_start",
+		Description: "Start a work session on a goal, pausing any other open session",
+	}, goalsHandler.GoalsStart)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_stop",
+		Description: "Stop the open work session",
+	}, goalsHandler.GoalsStop)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_time_report",
+		Description: "Summarize tracked time per goal, tag and week, flagging sessions left open",
+	}, goalsHandler.GoalsTimeReport)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_stats",
+		Description: "Report weekly throughput, cycle time, open versus closed trends and a milestone burndown, as JSON and markdown",
+	}, goalsHandler.GoalsStats)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_import",
+		Description: "Import goals from todo.txt, a Taskwarrior JSON export or GitHub issues JSON; re-imports update matching goals, dry_run previews the result",
+	}, goalsHandler.GoalsImport)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "goals_get",
+		Description: "Get a goal with its criteria, dependencies and linked ADRs, changelog entries, files and commits",
+	}, goalsHandler.GoalsGet)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "links_add",
+		Description: "Link a goal to an ADR, a changelog entry, a file or a git commit",
+	}, goalsHandler.LinksAdd)
+
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name:        "adrs_list",
-		Description: "List Architecture Decision Records (ADRs)",
+		Description: "List Architecture Decision Records (ADRs), kept in sync with the ADR directory, optionally filtered by status or relation",
 	}, adrsHandler.ADRsList)
 
 	mcp.AddTool(mcpServer, &mcp.Tool{
@@ -118,16 +222,111 @@ func main() {
 		Description: "Get the content of a specific ADR",
 	}, adrsHandler.ADRsGet)
 
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "adrs_create",
+		Description: "Record a new ADR with the next ADR-NNN number and write its markdown file (MCP_ADR_PATH, default docs/adr)",
+	}, adrsHandler.ADRsCreate)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "adrs_update",
+		Description: "Update an ADR's title, status or sections and rewrite its markdown file",
+	}, adrsHandler.ADRsUpdate)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "adrs_supersede",
+		Description: "Mark an ADR as superseded by a newer one and link the two markdown files",
+	}, adrsHandler.ADRsSupersede)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "adrs_relate",
+		Description: "Record that one ADR supersedes, amends or relates to another and rewrite both markdown files",
+	}, adrsHandler.ADRsRelate)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "adrs_graph",
+		Description: "Draw the ADRs and their relations as a Mermaid or Graphviz DOT graph, styled by status",
+	}, adrsHandler.ADRsGraph)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "adrs_index",
+		Description: "Regenerate README.md in the ADR directory as a table of number, title, status and date",
+	}, adrsHandler.ADRsIndex)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "adrs_lint",
+		Description: "Check ADR files for required sections, valid statuses, gapless numbering and working supersede links",
+	}, adrsHandler.ADRsLint)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "adrs_for_path",
+		Description: "List the accepted and proposed ADRs whose scope covers a file or package",
+	}, adrsHandler.ADRsForPath)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "adrs_sync",
+		Description: "Re-read the ADR markdown files (Nygard or MADR) into the database; this also happens on startup and when files change",
+	}, adrsHandler.ADRsSync)
+
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name:        "ci_run_tests",
-		Description: "Run tests for the project",
+		Description: "Run tests for the project with go test, or npm, vitest, jest, pytest or cargo by project type, and wait for the result; scope affected tests only the packages the working-tree changes may break; runs that exceed their timeout report status timeout",
 	}, ciHandler.CIRunTests)
 
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "ci_start",
+		Description: "Start a test run in the background and return a job ID to poll with ci_status and ci_output",
+	}, ciHandler.CIStart)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "ci_status",
+		Description: "Get the status, elapsed time and test counts so far of a background test run",
+	}, ciHandler.CIStatus)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "ci_output",
+		Description: "Get the output of a test run from an offset; pass the returned offset to get only new output",
+	}, ciHandler.CIOutput)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "ci_cancel",
+		Description: "Cancel a background test run",
+	}, ciHandler.CICancel)
+
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name:        "ci_last_failure",
-		Description: "Get information about the last test failure",
+		Description: "Get the failing tests of the last failed run with output excerpts, source locations and snippets, and whether they still fail",
 	}, ciHandler.CILastFailure)
 
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "ci_history",
+		Description: "List CI runs with scope, status, duration and test counts, filtered by scope, status and date",
+	}, ciHandler.CIHistory)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "ci_trends",
+		Description: "Show pass rate and duration percentiles per package by day or week, and the packages that are getting slower",
+	}, ciHandler.CITrends)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "ci_flaky",
+		Description: "Score tests that both passed and failed on the same commit or working tree; with rerun, retry the failures of a run to classify them as flaky or deterministic",
+	}, ciHandler.CIFlaky)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "ci_coverage",
+		Description: "Report coverage of a run by package, uncovered functions, the delta against the previous run or a baseline, and coverage of the lines changed in the git diff",
+	}, ciHandler.CICoverage)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "ci_bench",
+		Description: "Run benchmarks with -benchmem and -count, store the samples by name and commit, and compare with a baseline run benchstat-style, flagging significant regressions above a threshold",
+	}, ciHandler.CIBench)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "ci_vet",
+		Description: "Run go vet, plus staticcheck and golangci-lint when installed, store their findings with file, line, column and analyzer, and report the ones that are new since a baseline run",
+	}, ciHandler.CIVet)
+
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name:        "repo_search",
 		Description: "Search the repository for text patterns",
@@ -135,7 +334,7 @@ func main() {
 
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name:        "state_log_change",
-		Description: "Log a change to the project changelog",
+		Description: "Log a change to the project changelog, optionally linking it to a goal",
 	}, stateHandler.StateLogChange)
 
 	mcp.AddTool(mcpServer, &mcp.Tool{
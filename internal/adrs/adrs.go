@@ -2,7 +2,11 @@ package adrs
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/thornzero/project-manager/internal/models"
@@ -12,22 +16,38 @@ import (
 
 type ADRsHandler struct {
 	server *server.Server
+	syncMu sync.Mutex // serializes Sync between the tool and the watcher
 }
 
 func NewADRsHandler(s *server.Server) *ADRsHandler {
 	return &ADRsHandler{server: s}
 }
 
+// ADRsList returns ADRs in number order, optionally filtered by text, status
+// and relation.
 func (h *ADRsHandler) ADRsList(ctx context.Context, req *mcp.CallToolRequest, input types.ADRsListInput) (*mcp.CallToolResult, types.ADRsListOutput, error) {
+	if input.Relation != "" && !slices.Contains(relationNames, input.Relation) {
+		return nil, types.ADRsListOutput{}, fmt.Errorf("unknown relation %q (allowed: %s)", input.Relation, strings.Join(relationNames, ", "))
+	}
+
 	var adrs []models.ADR
-	query := h.server.GetDB()
+	db := h.server.GetDB()
+	query := db
 
 	if input.Query != nil && strings.TrimSpace(*input.Query) != "" {
 		searchTerm := "%" + *input.Query + "%"
 		query = query.Where("title LIKE ? OR id LIKE ? OR content LIKE ?", searchTerm, searchTerm, searchTerm)
 	}
+	if len(input.Statuses) > 0 {
+		query = query.Where("status IN ?", input.Statuses)
+	}
+
+	err := query.Order("number ASC, id ASC").Find(&adrs).Error
+	if err != nil {
+		return nil, types.ADRsListOutput{}, err
+	}
 
-	err := query.Find(&adrs).Error
+	relations, err := loadRelations(db)
 	if err != nil {
 		return nil, types.ADRsListOutput{}, err
 	}
@@ -35,12 +55,10 @@ func (h *ADRsHandler) ADRsList(ctx context.Context, req *mcp.CallToolRequest, in
 	// Convert to types.ADR
 	resultADRs := make([]types.ADR, 0, len(adrs))
 	for _, adr := range adrs {
-		resultADRs = append(resultADRs, types.ADR{
-			ID:        adr.ID,
-			Title:     adr.Title,
-			Content:   adr.Content,
-			UpdatedAt: adr.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
+		if (input.Relation != "" || input.RelatedTo != "") && !matchesRelation(relations[adr.ID], input.Relation, input.RelatedTo) {
+			continue
+		}
+		resultADRs = append(resultADRs, toTypesADR(adr, relations[adr.ID]))
 	}
 
 	return nil, types.ADRsListOutput{ADRs: resultADRs}, nil
@@ -48,14 +66,56 @@ func (h *ADRsHandler) ADRsList(ctx context.Context, req *mcp.CallToolRequest, in
 
 func (h *ADRsHandler) ADRsGet(ctx context.Context, req *mcp.CallToolRequest, input types.ADRsGetInput) (*mcp.CallToolResult, types.ADRsGetOutput, error) {
 	var adr models.ADR
-	err := h.server.GetDB().Where("id = ?", input.ID).First(&adr).Error
+	db := h.server.GetDB()
+	err := db.Where("id = ?", input.ID).First(&adr).Error
+	if err != nil {
+		return nil, types.ADRsGetOutput{}, err
+	}
+
+	relations, err := loadRelations(db)
 	if err != nil {
 		return nil, types.ADRsGetOutput{}, err
 	}
 
 	return nil, types.ADRsGetOutput{
-		ID:      adr.ID,
-		Title:   adr.Title,
-		Content: adr.Content,
+		ID:        adr.ID,
+		Title:     adr.Title,
+		Status:    adr.Status,
+		Date:      formatDate(adr.Date),
+		Deciders:  splitStored(adr.Deciders),
+		Scope:     splitStored(adr.Scope),
+		Path:      adr.FilePath,
+		Relations: relations[adr.ID],
+		Content:   adr.Content,
 	}, nil
 }
+
+func toTypesADR(adr models.ADR, relations []types.ADRLink) types.ADR {
+	return types.ADR{
+		ID:        adr.ID,
+		Title:     adr.Title,
+		Status:    adr.Status,
+		Date:      formatDate(adr.Date),
+		Deciders:  splitStored(adr.Deciders),
+		Scope:     splitStored(adr.Scope),
+		Path:      adr.FilePath,
+		Relations: relations,
+		Content:   adr.Content,
+		UpdatedAt: adr.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// splitStored splits a comma-separated column such as Deciders or Scope.
+func splitStored(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ", ")
+}
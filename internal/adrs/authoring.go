@@ -0,0 +1,370 @@
+package adrs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/markdown"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
+)
+
+// sectionPlaceholder is written for empty sections and read back as empty.
+const sectionPlaceholder = "TBD"
+
+var authoredStatuses = []string{statusProposed, statusAccepted, statusRejected, statusDeprecated}
+
+// ADRsCreate records a new decision as the next ADR-NNN and writes its
+// markdown file.
+func (h *ADRsHandler) ADRsCreate(ctx context.Context, req *mcp.CallToolRequest, input types.ADRsCreateInput) (*mcp.CallToolResult, types.ADRsCreateOutput, error) {
+	if strings.TrimSpace(input.Title) == "" {
+		return nil, types.ADRsCreateOutput{}, fmt.Errorf("title required")
+	}
+
+	adr := models.ADR{
+		Title:        strings.TrimSpace(input.Title),
+		Status:       statusProposed,
+		Date:         today(),
+		Context:      input.Context,
+		Decision:     input.Decision,
+		Consequences: input.Consequences,
+	}
+	scope, err := h.cleanScope(input.Scope)
+	if err != nil {
+		return nil, types.ADRsCreateOutput{}, err
+	}
+	adr.Scope = scope
+	if input.Status != nil {
+		if !slices.Contains(authoredStatuses, *input.Status) {
+			return nil, types.ADRsCreateOutput{}, fmt.Errorf("invalid status %q (allowed: %s)", *input.Status, strings.Join(authoredStatuses, ", "))
+		}
+		adr.Status = *input.Status
+	}
+	if input.Date != nil {
+		date, err := parseDate(*input.Date)
+		if err != nil {
+			return nil, types.ADRsCreateOutput{}, err
+		}
+		adr.Date = date
+	}
+
+	err = h.author(func(tx *gorm.DB) ([]adrFile, error) {
+		var last models.ADR
+		if err := tx.Order("number DESC").Limit(1).Find(&last).Error; err != nil {
+			return nil, err
+		}
+		adr.Number = last.Number + 1
+		adr.ID = fmt.Sprintf("ADR-%03d", adr.Number)
+		if err := tx.Create(&adr).Error; err != nil {
+			return nil, err
+		}
+		file, err := h.stageADR(tx, &adr)
+		return []adrFile{file}, err
+	})
+	if err != nil {
+		return nil, types.ADRsCreateOutput{}, err
+	}
+
+	return nil, types.ADRsCreateOutput{ID: adr.ID, Path: adr.FilePath}, nil
+}
+
+// ADRsUpdate changes an ADR and edits its markdown file to match.
+func (h *ADRsHandler) ADRsUpdate(ctx context.Context, req *mcp.CallToolRequest, input types.ADRsUpdateInput) (*mcp.CallToolResult, types.ADRsUpdateOutput, error) {
+	var adr models.ADR
+	err := h.author(func(tx *gorm.DB) ([]adrFile, error) {
+		if err := h.updateADR(tx, input, &adr); err != nil {
+			return nil, err
+		}
+		file, err := h.stageADR(tx, &adr)
+		return []adrFile{file}, err
+	})
+	if err != nil {
+		return nil, types.ADRsUpdateOutput{}, err
+	}
+
+	return nil, types.ADRsUpdateOutput{ID: adr.ID, Path: adr.FilePath}, nil
+}
+
+// updateADR applies the changes of an adrs_update call to an ADR and
+// stores them.
+func (h *ADRsHandler) updateADR(tx *gorm.DB, input types.ADRsUpdateInput, adr *models.ADR) error {
+	if err := findADR(tx, input.ID, adr); err != nil {
+		return err
+	}
+
+	if input.Title != nil {
+		if strings.TrimSpace(*input.Title) == "" {
+			return fmt.Errorf("title cannot be empty")
+		}
+		adr.Title = strings.TrimSpace(*input.Title)
+	}
+	if input.Context != nil {
+		adr.Context = *input.Context
+	}
+	if input.Decision != nil {
+		adr.Decision = *input.Decision
+	}
+	if input.Consequences != nil {
+		adr.Consequences = *input.Consequences
+	}
+	if input.Status != nil {
+		if !slices.Contains(authoredStatuses, *input.Status) {
+			return fmt.Errorf("invalid status %q (allowed: %s; use adrs_supersede to supersede)", *input.Status, strings.Join(authoredStatuses, ", "))
+		}
+		if !canTransition(adr.Status, *input.Status) {
+			return fmt.Errorf("cannot move %s from %s to %s (allowed: %s)", adr.ID, adr.Status, *input.Status, strings.Join(adrTransitions[adr.Status], ", "))
+		}
+		adr.Status = *input.Status
+	}
+	if input.Date != nil {
+		date, err := parseDate(*input.Date)
+		if err != nil {
+			return err
+		}
+		adr.Date = date
+	}
+	if input.Scope != nil {
+		scope, err := h.cleanScope(input.Scope)
+		if err != nil {
+			return err
+		}
+		adr.Scope = scope
+	}
+
+	return tx.Select("title", "context", "decision", "consequences", "status", "date", "scope").Updates(adr).Error
+}
+
+// ADRsSupersede marks an ADR as superseded by a newer one. Both markdown
+// files are rewritten to link to each other.
+func (h *ADRsHandler) ADRsSupersede(ctx context.Context, req *mcp.CallToolRequest, input types.ADRsSupersedeInput) (*mcp.CallToolResult, types.ADRsSupersedeOutput, error) {
+	if input.ID == "" || input.By == "" {
+		return nil, types.ADRsSupersedeOutput{}, fmt.Errorf("id and by are required")
+	}
+
+	var output types.ADRsSupersedeOutput
+	err := h.author(func(tx *gorm.DB) ([]adrFile, error) {
+		_, files, err := h.relate(tx, input.By, relationSupersedes, input.ID)
+		output.Paths = filePaths(h.server.GetRepoRoot(), files)
+		return files, err
+	})
+	if err != nil {
+		return nil, types.ADRsSupersedeOutput{}, err
+	}
+
+	return nil, output, nil
+}
+
+func findADR(db *gorm.DB, id string, adr *models.ADR) error {
+	if id == "" {
+		return fmt.Errorf("id required")
+	}
+	result := db.Where("id = ?", id).Limit(1).Find(adr)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("ADR %s not found", id)
+	}
+	return nil
+}
+
+// adrFile is an ADR file to write once the change to its ADR commits. It
+// replaces old when the file moves.
+type adrFile struct {
+	path, content, old string
+}
+
+// author runs an ADR change in a transaction and writes the files it
+// staged after the transaction commits. It holds syncMu throughout, so the
+// watcher neither imports the file of a change that is rolled back nor
+// syncs while the files are written.
+func (h *ADRsHandler) author(change func(tx *gorm.DB) ([]adrFile, error)) error {
+	h.syncMu.Lock()
+	defer h.syncMu.Unlock()
+
+	var files []adrFile
+	err := h.server.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		files, err = change(tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+			return fmt.Errorf("failed to create ADR directory: %v", err)
+		}
+		if err := os.WriteFile(f.path, []byte(f.content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", f.path, err)
+		}
+		if f.old != "" && f.old != f.path {
+			if err := os.Remove(f.old); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove %s: %v", f.old, err)
+			}
+		}
+	}
+	return nil
+}
+
+// filePaths returns the paths of staged files relative to the repository
+// root.
+func filePaths(root string, files []adrFile) []string {
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, repoPath(root, f.path))
+	}
+	return paths
+}
+
+// stageADR renders an ADR to its markdown file and stores the file path and
+// content; the file is written by author. A new ADR is written in the
+// Nygard format as NNNN-title-slug.md in the ADR directory, as adr-tools
+// and MADR name them. An existing file keeps its path and format, such as
+// MADR front matter, deciders and extra sections: only the lines of fields
+// that changed are edited. It is renamed with its title only if its name
+// was the slug of the old title.
+func (h *ADRsHandler) stageADR(tx *gorm.DB, adr *models.ADR) (adrFile, error) {
+	var relations []models.ADRRelation
+	if err := tx.Where("from_id = ? OR to_id = ?", adr.ID, adr.ID).Order("id ASC").Find(&relations).Error; err != nil {
+		return adrFile{}, err
+	}
+	var related []string
+	for _, r := range relations {
+		related = append(related, r.FromID, r.ToID)
+	}
+	files := make(map[string]string)
+	if len(related) > 0 {
+		var others []models.ADR
+		if err := tx.Select("id", "file_path").Where("id IN ?", related).Find(&others).Error; err != nil {
+			return adrFile{}, err
+		}
+		for _, o := range others {
+			if o.FilePath != "" {
+				files[o.ID] = filepath.Base(o.FilePath)
+			}
+		}
+	}
+	status := statusLines(*adr, relations, files)
+
+	root := h.server.GetRepoRoot()
+	file := adrFile{path: filepath.Join(h.server.GetADRPath(), fmt.Sprintf("%04d-%s.md", adr.Number, slugify(adr.Title)))}
+	if adr.FilePath != "" {
+		file.old = absPath(root, adr.FilePath)
+		name := filepath.Base(file.old)
+		data, err := os.ReadFile(file.old)
+		switch {
+		case err == nil && adrFileName.MatchString(name):
+			current, _ := parseADR(name, string(data))
+			file.content = editADR(string(data), current, *adr, status)
+			if current.Title == adr.Title || name != fmt.Sprintf("%04d-%s.md", adr.Number, slugify(current.Title)) {
+				file.path = file.old
+			}
+		case err != nil && !os.IsNotExist(err):
+			return adrFile{}, fmt.Errorf("failed to read %s: %v", adr.FilePath, err)
+		}
+	}
+	if file.content == "" {
+		file.content = renderADR(*adr, status)
+	}
+
+	rel := repoPath(root, file.path)
+	adr.FilePath, adr.Content = rel, file.content
+	return file, tx.Model(adr).Updates(map[string]interface{}{"file_path": rel, "content": file.content}).Error
+}
+
+// statusLines are the paragraphs of an ADR's status section: its status,
+// then its relations as adr-tools writes them, linked to the other ADR's
+// file when it has one.
+func statusLines(adr models.ADR, relations []models.ADRRelation, files map[string]string) []string {
+	link := func(id string) string {
+		if file, ok := files[id]; ok {
+			return fmt.Sprintf("[%s](%s)", id, file)
+		}
+		return id
+	}
+
+	lines := []string{capitalize(adr.Status)}
+	for _, r := range relations {
+		labels := relationLabels[r.Kind]
+		if r.FromID == adr.ID {
+			lines = append(lines, labels[0]+" "+link(r.ToID))
+		} else {
+			lines = append(lines, labels[1]+" "+link(r.FromID))
+		}
+	}
+	return lines
+}
+
+// renderADR writes an ADR in the Nygard format: title, date, scope, status,
+// context, decision and consequences.
+func renderADR(adr models.ADR, status []string) string {
+	md := markdown.NewBuilder()
+	md.AddHeader(1, fmt.Sprintf("%s: %s", adr.ID, adr.Title))
+	if !adr.Date.IsZero() {
+		md.AddParagraph("Date: " + adr.Date.Format("2006-01-02"))
+	}
+	if adr.Scope != "" {
+		md.AddParagraph("Scope: " + adr.Scope)
+	}
+
+	md.AddHeader(2, "Status")
+	for _, line := range status {
+		if line != "" {
+			md.AddParagraph(line)
+		}
+	}
+
+	for _, section := range []struct{ title, text string }{
+		{"Context", adr.Context},
+		{"Decision", adr.Decision},
+		{"Consequences", adr.Consequences},
+	} {
+		md.AddHeader(2, section.title)
+		md.AddParagraph(sectionBody(section.text))
+	}
+
+	return md.String()
+}
+
+// slugify turns a title into a lowercase, dash-separated file name.
+func slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "decision"
+	}
+	return b.String()
+}
+
+func parseDate(value string) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(value), time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", value)
+	}
+	return date, nil
+}
+
+func today() time.Time {
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
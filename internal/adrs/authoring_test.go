@@ -0,0 +1,233 @@
+package adrs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{title: "Use PostgreSQL", want: "use-postgresql"},
+		{title: "  Cache: Redis vs. Memcached?  ", want: "cache-redis-vs-memcached"},
+		{title: "!!!", want: "decision"},
+	}
+
+	for _, tt := range tests {
+		if got := slugify(tt.title); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestADRsHandler_Authoring(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewADRsHandler(srv)
+	ctx := context.Background()
+
+	read := func(path string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(tempDir, path))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		return string(data)
+	}
+
+	// New ADRs are numbered after the existing ones
+	srv.GetDB().Create(&models.ADR{ID: "ADR-001", Number: 1, Title: "Record decisions", Status: "accepted"})
+
+	if _, _, err := handler.ADRsCreate(ctx, nil, types.ADRsCreateInput{Title: " "}); err == nil {
+		t.Errorf("ADRsCreate() without title expected error, got nil")
+	}
+	bad := "superseded"
+	if _, _, err := handler.ADRsCreate(ctx, nil, types.ADRsCreateInput{Title: "Use SQLite", Status: &bad}); err == nil {
+		t.Errorf("ADRsCreate() with status superseded expected error, got nil")
+	}
+
+	date := "2025-03-04"
+	_, first, err := handler.ADRsCreate(ctx, nil, types.ADRsCreateInput{
+		Title:    "Use SQLite",
+		Context:  "We need a local store.",
+		Decision: "Use SQLite through GORM.",
+		Date:     &date,
+	})
+	if err != nil {
+		t.Fatalf("ADRsCreate() unexpected error: %v", err)
+	}
+	if first.ID != "ADR-002" || first.Path != "docs/adr/0002-use-sqlite.md" {
+		t.Fatalf("ADRsCreate() = %+v, want ADR-002 in docs/adr/0002-use-sqlite.md", first)
+	}
+	content := read(first.Path)
+	for _, want := range []string{"# ADR-002: Use SQLite\n", "Date: 2025-03-04", "## Status\n\nProposed", "## Decision\n\nUse SQLite through GORM.", "## Consequences\n\nTBD"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("ADR file missing %q:\n%s", want, content)
+		}
+	}
+
+	// Renaming moves the file
+	title, accepted := "Use SQLite for state", "accepted"
+	_, updated, err := handler.ADRsUpdate(ctx, nil, types.ADRsUpdateInput{ID: first.ID, Title: &title, Status: &accepted})
+	if err != nil {
+		t.Fatalf("ADRsUpdate() unexpected error: %v", err)
+	}
+	if updated.Path != "docs/adr/0002-use-sqlite-for-state.md" {
+		t.Errorf("ADRsUpdate() path = %q", updated.Path)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, first.Path)); !os.IsNotExist(err) {
+		t.Errorf("ADRsUpdate() left %s behind", first.Path)
+	}
+	if content := read(updated.Path); !strings.Contains(content, "Accepted") || !strings.Contains(content, "Use SQLite through GORM.") {
+		t.Errorf("ADRsUpdate() file =\n%s", content)
+	}
+	if _, _, err := handler.ADRsUpdate(ctx, nil, types.ADRsUpdateInput{ID: "ADR-099", Title: &title}); err == nil {
+		t.Errorf("ADRsUpdate() of unknown ADR expected error, got nil")
+	}
+	if _, _, err := handler.ADRsUpdate(ctx, nil, types.ADRsUpdateInput{ID: first.ID, Status: &bad}); err == nil {
+		t.Errorf("ADRsUpdate() to superseded expected error, got nil")
+	}
+
+	// Supersede links both files
+	os.Setenv("MCP_ADR_PATH", "decisions")
+	defer os.Unsetenv("MCP_ADR_PATH")
+	_, second, err := handler.ADRsCreate(ctx, nil, types.ADRsCreateInput{Title: "Use PostgreSQL"})
+	if err != nil {
+		t.Fatalf("ADRsCreate() unexpected error: %v", err)
+	}
+	if second.ID != "ADR-003" || second.Path != "decisions/0003-use-postgresql.md" {
+		t.Fatalf("ADRsCreate() = %+v, want ADR-003 in the configured directory", second)
+	}
+	os.Unsetenv("MCP_ADR_PATH")
+
+	if _, _, err := handler.ADRsSupersede(ctx, nil, types.ADRsSupersedeInput{ID: first.ID, By: first.ID}); err == nil {
+		t.Errorf("ADRsSupersede() by itself expected error, got nil")
+	}
+	_, superseded, err := handler.ADRsSupersede(ctx, nil, types.ADRsSupersedeInput{ID: first.ID, By: second.ID})
+	if err != nil {
+		t.Fatalf("ADRsSupersede() unexpected error: %v", err)
+	}
+	if len(superseded.Paths) != 2 {
+		t.Fatalf("ADRsSupersede() paths = %v, want 2", superseded.Paths)
+	}
+	if content := read(superseded.Paths[0]); !strings.Contains(content, "Superseded\n\nSuperseded by [ADR-003](0003-use-postgresql.md)") {
+		t.Errorf("superseded ADR file =\n%s", content)
+	}
+	if content := read(superseded.Paths[1]); !strings.Contains(content, "Supersedes [ADR-002](0002-use-sqlite-for-state.md)") {
+		t.Errorf("superseding ADR file =\n%s", content)
+	}
+
+	_, got, err := handler.ADRsGet(ctx, nil, types.ADRsGetInput{ID: first.ID})
+	if err != nil {
+		t.Fatalf("ADRsGet() unexpected error: %v", err)
+	}
+	if got.Status != "superseded" || got.Date != "2025-03-04" || len(got.Relations) != 1 || got.Relations[0] != (types.ADRLink{Kind: "superseded-by", ID: second.ID}) {
+		t.Errorf("ADRsGet() = %+v, want superseded by %s", got, second.ID)
+	}
+}
+
+func TestADRsHandler_EditSyncedFile(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewADRsHandler(srv)
+	ctx := context.Background()
+	dir := filepath.Join(tempDir, "docs", "adr")
+
+	path := filepath.Join(dir, "0001-use-postgres-database.md")
+	writeADRFile(t, dir, filepath.Base(path), "---\nstatus: proposed\ndate: 2024-01-02\ndeciders: [\"Ann\", Bob]\n---\n"+
+		"# Use Postgres\n\n## Context and Problem Statement\n\nWe need a database.\n\n"+
+		"## Considered Options\n\n* Postgres\n* MySQL\n\n## Decision Outcome\n\nChosen option: Postgres.\n")
+	if _, _, err := handler.ADRsSync(ctx, nil, types.ADRsSyncInput{}); err != nil {
+		t.Fatalf("ADRsSync() unexpected error: %v", err)
+	}
+
+	accepted := "accepted"
+	_, updated, err := handler.ADRsUpdate(ctx, nil, types.ADRsUpdateInput{ID: "ADR-001", Status: &accepted})
+	if err != nil {
+		t.Fatalf("ADRsUpdate() unexpected error: %v", err)
+	}
+	if updated.Path != "docs/adr/0001-use-postgres-database.md" {
+		t.Errorf("ADRsUpdate() path = %q, want the synced file", updated.Path)
+	}
+	want := "---\nstatus: accepted\ndate: 2024-01-02\ndeciders: [\"Ann\", Bob]\n---\n" +
+		"# Use Postgres\n\n## Context and Problem Statement\n\nWe need a database.\n\n" +
+		"## Considered Options\n\n* Postgres\n* MySQL\n\n## Decision Outcome\n\nChosen option: Postgres.\n"
+	data, _ := os.ReadFile(path)
+	if string(data) != want {
+		t.Errorf("ADRsUpdate() file =\n%s\nwant only the status changed:\n%s", data, want)
+	}
+
+	// Superseding adds the relation and keeps the rest
+	_, second, err := handler.ADRsCreate(ctx, nil, types.ADRsCreateInput{Title: "Use CockroachDB"})
+	if err != nil {
+		t.Fatalf("ADRsCreate() unexpected error: %v", err)
+	}
+	if _, _, err := handler.ADRsSupersede(ctx, nil, types.ADRsSupersedeInput{ID: "ADR-001", By: second.ID}); err != nil {
+		t.Fatalf("ADRsSupersede() unexpected error: %v", err)
+	}
+	scope := []string{"internal"}
+	os.MkdirAll(filepath.Join(tempDir, "internal"), 0755)
+	if _, _, err := handler.ADRsUpdate(ctx, nil, types.ADRsUpdateInput{ID: "ADR-001", Scope: scope}); err != nil {
+		t.Fatalf("ADRsUpdate() unexpected error: %v", err)
+	}
+	want = "---\nstatus: superseded\ndate: 2024-01-02\ndeciders: [\"Ann\", Bob]\nscope: internal\n---\n" +
+		"# Use Postgres\n\n## Status\n\nSuperseded by [ADR-002](0002-use-cockroachdb.md)\n\n" +
+		"## Context and Problem Statement\n\nWe need a database.\n\n" +
+		"## Considered Options\n\n* Postgres\n* MySQL\n\n## Decision Outcome\n\nChosen option: Postgres.\n"
+	data, _ = os.ReadFile(path)
+	if string(data) != want {
+		t.Errorf("ADRsSupersede() file =\n%s\nwant:\n%s", data, want)
+	}
+
+	// The edited file reads back as what was stored
+	_, synced, err := handler.ADRsSync(ctx, nil, types.ADRsSyncInput{})
+	if err != nil {
+		t.Fatalf("ADRsSync() unexpected error: %v", err)
+	}
+	if synced.Unchanged != 2 || synced.Updated != 0 || synced.Created != 0 {
+		t.Errorf("ADRsSync() after editing = %+v, want both ADRs unchanged", synced)
+	}
+	_, got, err := handler.ADRsGet(ctx, nil, types.ADRsGetInput{ID: "ADR-001"})
+	if err != nil {
+		t.Fatalf("ADRsGet() unexpected error: %v", err)
+	}
+	if got.Status != "superseded" || strings.Join(got.Deciders, ", ") != "Ann, Bob" {
+		t.Errorf("ADRsGet() = %+v, want superseded with its deciders", got)
+	}
+}
+
+func TestEditADR(t *testing.T) {
+	content := "# 3. Use Markdown\n\n* Status: proposed\n* Date: 2021-02-03\n\n" +
+		"## Context and Problem Statement\n\nHow?\n\n### Details\n\nMore.\n\n## Pros and Cons\n\n* Good\n"
+	current, _ := parseADR("0003-use-markdown.md", content)
+	adr := current
+	adr.Title, adr.Status, adr.Scope, adr.Context = "Use MADR", "accepted", "docs", "Which format?"
+	adr.Date = adr.Date.AddDate(0, 0, 1)
+
+	want := "# 3. Use MADR\n\n* Status: accepted\n* Date: 2021-02-04\n* Scope: docs\n\n" +
+		"## Context and Problem Statement\n\nWhich format?\n\n## Pros and Cons\n\n* Good\n"
+	if got := editADR(content, current, adr, []string{"Accepted"}); got != want {
+		t.Errorf("editADR() =\n%s\nwant:\n%s", got, want)
+	}
+}
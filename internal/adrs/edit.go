@@ -0,0 +1,278 @@
+package adrs
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/thornzero/project-manager/internal/models"
+)
+
+// editFields are the metadata fields an edit may change, in the order
+// missing ones are added.
+var editFields = []string{"status", "date", "deciders", "scope"}
+
+// editADR changes an ADR file in place from current, the ADR as parsed from
+// content, to adr, whose status section reads status. Only the lines of the
+// fields that differ are rewritten, where parseADR found them: front matter
+// keys, metadata lines, the title, the status section and the context,
+// decision and consequences sections. Everything else is kept as it is.
+// Missing fields are added in the style of the file, and missing sections
+// at its end.
+func editADR(content string, current, adr models.ADR, status []string) string {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(content, "\r\n", "\n"), "\n"), "\n")
+	replace := make(map[int][]string) // lines that replace line i; nil deletes it
+	insert := make(map[int][]string)  // lines to insert before line i
+
+	// Find the fields as parseADR does: front matter keys first, then the
+	// first metadata line of each
+	fields := make(map[string]int)
+	body, front := 0, -1
+	if strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				body, front = i+1, i
+				break
+			}
+		}
+		for i := 1; i < front; i++ {
+			if key, _, ok := strings.Cut(lines[i], ":"); ok {
+				setField(fields, strings.TrimSpace(key), i)
+			}
+		}
+	}
+
+	title, lastField := -1, -1
+	sections := make(map[string][2]int) // heading line and end of the first section for each field
+	firstSection := len(lines)
+	section, start := "", 0
+	closeSection := func(end int) {
+		if _, ok := sections[section]; !ok && section != "" {
+			sections[section] = [2]int{start, end}
+		}
+	}
+	fenced := false
+	for i := body; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "```") {
+			fenced = !fenced
+		}
+		if fenced {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "# ") && title < 0 {
+			title = i
+			continue
+		}
+		if heading, level := headingText(trimmed); level >= 2 {
+			field, ok := adrSections[strings.ToLower(heading)]
+			if !ok && level == 2 {
+				field, ok = "other", true
+			}
+			if ok {
+				closeSection(i)
+				section, start = field, i
+				firstSection = min(firstSection, i)
+				continue
+			}
+		}
+		if section == "" || section == "status" {
+			if m := adrField.FindStringSubmatch(trimmed); m != nil {
+				setField(fields, m[1], i)
+				if section == "" {
+					lastField = i
+				}
+			}
+		}
+	}
+	closeSection(len(lines))
+
+	// addSection adds a section before line at
+	addSection := func(at int, heading string, text ...string) {
+		block, last := insert[at], ""
+		if len(block) > 0 {
+			last = block[len(block)-1]
+		} else if at > 0 {
+			last = lines[at-1]
+		}
+		if strings.TrimSpace(last) != "" {
+			block = append(block, "")
+		}
+		block = append(block, heading, "")
+		for _, t := range text {
+			block = append(block, t, "")
+		}
+		insert[at] = block
+	}
+
+	if current.Title != adr.Title {
+		if title >= 0 {
+			text := strings.TrimSpace(strings.TrimSpace(lines[title])[2:])
+			replace[title] = []string{"# " + adrTitlePrefix.FindString(text) + adr.Title}
+		} else {
+			insert[body] = append(insert[body], "# "+adr.Title, "")
+		}
+	}
+
+	values := map[string][2]string{
+		"status":   {current.Status, adr.Status},
+		"date":     {formatDate(current.Date), formatDate(adr.Date)},
+		"deciders": {current.Deciders, adr.Deciders},
+		"scope":    {current.Scope, adr.Scope},
+	}
+	for _, key := range editFields {
+		old, value := values[key][0], values[key][1]
+		if old == value {
+			continue
+		}
+		if i, ok := fields[key]; ok {
+			if value != "" {
+				prefix, _, _ := strings.Cut(lines[i], ":")
+				replace[i] = []string{prefix + ": " + value}
+				continue
+			}
+			replace[i] = nil
+			// A metadata paragraph goes with the blank line after it
+			if i > front && !isListItem(lines[i]) && i+1 < len(lines) && strings.TrimSpace(lines[i+1]) == "" {
+				replace[i+1] = nil
+			}
+			continue
+		}
+		if value == "" || key == "status" {
+			continue
+		}
+		switch {
+		case front >= 0:
+			insert[front] = append(insert[front], key+": "+value)
+		case lastField >= 0 && isListItem(lines[lastField]):
+			bullet := lines[lastField][:strings.IndexAny(lines[lastField], "*-")+2]
+			insert[lastField+1] = append(insert[lastField+1], bullet+capitalize(key)+": "+value)
+		case lastField >= 0:
+			insert[lastField+1] = append(insert[lastField+1], "", capitalize(key)+": "+value)
+		case title >= 0:
+			insert[title+1] = append(insert[title+1], "", capitalize(key)+": "+value)
+		default:
+			insert[body] = append(insert[body], capitalize(key)+": "+value, "")
+		}
+	}
+
+	// The status section states the status, unless a metadata field does,
+	// and the relations. It is rewritten when either changes; other lines
+	// in it are kept.
+	_, statusField := fields["status"]
+	statusChanged := current.Status != adr.Status && !statusField
+	relations, stated := status[1:], status
+	if statusField {
+		stated = relations
+	}
+	if s, ok := sections["status"]; ok {
+		var found, kept []string
+		paragraph := statusField
+		for i := s[0] + 1; i < s[1]; i++ {
+			trimmed := strings.TrimSpace(lines[i])
+			if _, ok := parseRelation(adr.ID, trimmed); ok {
+				found = append(found, trimmed)
+				continue
+			}
+			if trimmed != "" && !paragraph && !adrField.MatchString(trimmed) {
+				paragraph = true
+				continue
+			}
+			if r, ok := replace[i]; ok {
+				kept = append(kept, r...)
+			} else {
+				kept = append(kept, lines[i])
+			}
+		}
+		if statusChanged || !slices.Equal(found, relations) {
+			block := []string{""}
+			if kept = trimBlank(kept); len(kept) > 0 {
+				block = append(append(block, kept...), "")
+			}
+			for _, line := range stated {
+				block = append(block, line, "")
+			}
+			for i := s[0] + 1; i < s[1]; i++ {
+				replace[i] = nil
+			}
+			insert[s[1]] = append(block, insert[s[1]]...)
+		}
+	} else if statusChanged || len(relations) > 0 {
+		addSection(firstSection, "## Status", stated...)
+	}
+
+	for _, s := range []struct{ field, title, old, text string }{
+		{"context", "Context", current.Context, adr.Context},
+		{"decision", "Decision", current.Decision, adr.Decision},
+		{"consequences", "Consequences", current.Consequences, adr.Consequences},
+	} {
+		if strings.TrimSpace(s.old) == strings.TrimSpace(s.text) {
+			continue
+		}
+		r, ok := sections[s.field]
+		if !ok {
+			addSection(len(lines), "## "+s.title, sectionBody(s.text))
+			continue
+		}
+		for i := r[0] + 1; i < r[1]; i++ {
+			replace[i] = nil
+		}
+		insert[r[1]] = append([]string{"", sectionBody(s.text), ""}, insert[r[1]]...)
+	}
+
+	var out []string
+	for i := 0; i <= len(lines); i++ {
+		out = append(out, insert[i]...)
+		if i == len(lines) {
+			break
+		}
+		if r, ok := replace[i]; ok {
+			out = append(out, r...)
+			continue
+		}
+		out = append(out, lines[i])
+	}
+	return strings.Join(trimBlank(out), "\n") + "\n"
+}
+
+// setField records the line of a metadata field the first time it is
+// seen; decision-makers is MADR 3's name for deciders.
+func setField(fields map[string]int, key string, line int) {
+	key = strings.ToLower(key)
+	if key == "decision-makers" {
+		key = "deciders"
+	}
+	if _, ok := fields[key]; !ok && slices.Contains(editFields, key) {
+		fields[key] = line
+	}
+}
+
+// sectionBody is the text of a section, or the placeholder if it is empty.
+func sectionBody(text string) string {
+	if text = strings.TrimSpace(text); text != "" {
+		return text
+	}
+	return sectionPlaceholder
+}
+
+func isListItem(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "- ")
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// trimBlank drops the blank lines at the start and end of lines.
+func trimBlank(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
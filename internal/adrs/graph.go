@@ -0,0 +1,140 @@
+package adrs
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+// statusStyles is how each status is drawn: a Mermaid classDef and DOT node
+// attributes. Decisions that no longer apply are greyed out.
+var statusStyles = map[string]struct{ mermaid, dot string }{
+	statusProposed:   {"stroke-dasharray: 5 5", `style=dashed`},
+	statusAccepted:   {"fill:#e6f4ea,stroke:#1e8e3e", `style=filled, fillcolor="#e6f4ea"`},
+	statusRejected:   {"fill:#fce8e6,stroke:#d93025,color:#777", `style=filled, fillcolor="#fce8e6", fontcolor="#777777"`},
+	statusDeprecated: {"fill:#f1f3f4,stroke:#999,color:#777", `style=filled, fillcolor="#f1f3f4", fontcolor="#777777"`},
+	statusSuperseded: {"fill:#f1f3f4,stroke:#999,color:#777,stroke-dasharray: 2 2", `style="filled,dotted", fillcolor="#f1f3f4", fontcolor="#777777"`},
+}
+
+// edgeStyles is how each relation kind is drawn: a Mermaid arrow and DOT
+// edge attributes. relates-to has no direction.
+var edgeStyles = map[string]struct{ mermaid, dot string }{
+	relationSupersedes: {"-->", ""},
+	relationAmends:     {"-.->", `, style=dashed`},
+	relationRelatesTo:  {"---", `, dir=none`},
+}
+
+// ADRsGraph draws the ADRs and their relations as a Mermaid flowchart or a
+// Graphviz DOT digraph, with nodes styled by status.
+func (h *ADRsHandler) ADRsGraph(ctx context.Context, req *mcp.CallToolRequest, input types.ADRsGraphInput) (*mcp.CallToolResult, types.ADRsGraphOutput, error) {
+	format := input.Format
+	if format == "" {
+		format = "mermaid"
+	}
+	if format != "mermaid" && format != "dot" {
+		return nil, types.ADRsGraphOutput{}, fmt.Errorf("unknown graph format %q (allowed: mermaid, dot)", format)
+	}
+
+	db := h.server.GetDB()
+	query := db.Order("number ASC, id ASC")
+	if len(input.Statuses) > 0 {
+		query = query.Where("status IN ?", input.Statuses)
+	}
+	var adrs []models.ADR
+	if err := query.Find(&adrs).Error; err != nil {
+		return nil, types.ADRsGraphOutput{}, err
+	}
+	var all []models.ADRRelation
+	if err := db.Order("id ASC").Find(&all).Error; err != nil {
+		return nil, types.ADRsGraphOutput{}, err
+	}
+
+	included := make(map[string]bool, len(adrs))
+	for _, adr := range adrs {
+		included[adr.ID] = true
+	}
+	var relations []models.ADRRelation
+	for _, r := range all {
+		if included[r.FromID] && included[r.ToID] {
+			relations = append(relations, r)
+		}
+	}
+
+	output := types.ADRsGraphOutput{Format: format, Nodes: len(adrs), Edges: len(relations)}
+	if format == "dot" {
+		output.Graph = renderDOT(adrs, relations)
+	} else {
+		output.Graph = renderMermaid(adrs, relations)
+	}
+	return nil, output, nil
+}
+
+func renderMermaid(adrs []models.ADR, relations []models.ADRRelation) string {
+	// Node IDs keep only the characters Mermaid allows in them, with a
+	// suffix for IDs that end up the same
+	nodes := make(map[string]string, len(adrs))
+	taken := make(map[string]bool, len(adrs))
+	for _, adr := range adrs {
+		base := strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+				return r
+			}
+			return '_'
+		}, adr.ID)
+		id := base
+		for i := 2; taken[id]; i++ {
+			id = fmt.Sprintf("%s_%d", base, i)
+		}
+		nodes[adr.ID], taken[id] = id, true
+	}
+	node := func(id string) string { return nodes[id] }
+
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	byStatus := make(map[string][]string)
+	for _, adr := range adrs {
+		title := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(adr.Title)
+		fmt.Fprintf(&b, "    %s[\"%s: %s<br/>%s\"]\n", node(adr.ID), adr.ID, title, adr.Status)
+		byStatus[adr.Status] = append(byStatus[adr.Status], node(adr.ID))
+	}
+	for _, r := range relations {
+		label := strings.ToLower(relationLabels[r.Kind][0])
+		fmt.Fprintf(&b, "    %s %s|%s| %s\n", node(r.FromID), edgeStyles[r.Kind].mermaid, label, node(r.ToID))
+	}
+	for _, status := range slices.Sorted(maps.Keys(byStatus)) {
+		style, ok := statusStyles[status]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "    classDef %s %s\n", status, style.mermaid)
+		fmt.Fprintf(&b, "    class %s %s\n", strings.Join(byStatus[status], ","), status)
+	}
+	return b.String()
+}
+
+func renderDOT(adrs []models.ADR, relations []models.ADRRelation) string {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace
+
+	var b strings.Builder
+	b.WriteString("digraph adrs {\n    rankdir=LR;\n    node [shape=box];\n")
+	for _, adr := range adrs {
+		attrs := ""
+		if style, ok := statusStyles[adr.Status]; ok {
+			attrs = ", " + style.dot
+		}
+		label := quote(adr.ID) + `\n` + quote(adr.Title) + `\n(` + quote(adr.Status) + ")"
+		fmt.Fprintf(&b, "    \"%s\" [label=\"%s\"%s];\n", quote(adr.ID), label, attrs)
+	}
+	for _, r := range relations {
+		label := strings.ToLower(relationLabels[r.Kind][0])
+		fmt.Fprintf(&b, "    \"%s\" -> \"%s\" [label=\"%s\"%s];\n", quote(r.FromID), quote(r.ToID), label, edgeStyles[r.Kind].dot)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
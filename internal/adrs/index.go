@@ -0,0 +1,51 @@
+package adrs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/markdown"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+// ADRsIndex regenerates README.md in the ADR directory as a table of every
+// ADR's number, title, status and date.
+func (h *ADRsHandler) ADRsIndex(ctx context.Context, req *mcp.CallToolRequest, input types.ADRsIndexInput) (*mcp.CallToolResult, types.ADRsIndexOutput, error) {
+	var adrs []models.ADR
+	if err := h.server.GetDB().Order("number ASC, id ASC").Find(&adrs).Error; err != nil {
+		return nil, types.ADRsIndexOutput{}, err
+	}
+
+	root := h.server.GetRepoRoot()
+	dir := h.server.GetADRPath()
+	cell := strings.NewReplacer("|", `\|`, "\n", " ").Replace
+
+	rows := make([][]string, 0, len(adrs))
+	for _, adr := range adrs {
+		number := adr.ID
+		if adr.FilePath != "" && inDir(root, dir, adr.FilePath) {
+			number = fmt.Sprintf("[%s](%s)", adr.ID, filepath.Base(adr.FilePath))
+		}
+		rows = append(rows, []string{number, cell(adr.Title), adr.Status, formatDate(adr.Date)})
+	}
+
+	md := markdown.NewBuilder()
+	md.AddHeader(1, "Architecture Decision Records")
+	md.AddParagraph("This index is generated by `adrs_index`; edit the ADR files rather than this table.")
+	if len(rows) == 0 {
+		md.AddParagraph("No decisions have been recorded yet.")
+	} else {
+		md.AddTable([]string{"Number", "Title", "Status", "Date"}, rows)
+	}
+
+	path := filepath.Join(dir, "README.md")
+	if err := md.WriteToFile(path); err != nil {
+		return nil, types.ADRsIndexOutput{}, fmt.Errorf("failed to write %s: %v", path, err)
+	}
+
+	return nil, types.ADRsIndexOutput{Path: repoPath(root, path), ADRs: len(adrs)}, nil
+}
@@ -0,0 +1,68 @@
+package adrs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestADRsHandler_Index(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewADRsHandler(srv)
+	ctx := context.Background()
+
+	_, output, err := handler.ADRsIndex(ctx, nil, types.ADRsIndexInput{})
+	if err != nil {
+		t.Fatalf("ADRsIndex() unexpected error: %v", err)
+	}
+	if output.Path != "docs/adr/README.md" || output.ADRs != 0 {
+		t.Errorf("ADRsIndex() = %+v, want an empty docs/adr/README.md", output)
+	}
+
+	date := "2025-01-02"
+	if _, _, err := handler.ADRsCreate(ctx, nil, types.ADRsCreateInput{Title: "Use SQLite | Postgres", Date: &date}); err != nil {
+		t.Fatalf("ADRsCreate() unexpected error: %v", err)
+	}
+	srv.GetDB().Create(&models.ADR{ID: "ADR-002", Number: 2, Title: "Only in the database", Status: "accepted"})
+
+	_, output, err = handler.ADRsIndex(ctx, nil, types.ADRsIndexInput{})
+	if err != nil {
+		t.Fatalf("ADRsIndex() unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(tempDir, output.Path))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", output.Path, err)
+	}
+	for _, want := range []string{
+		"# Architecture Decision Records\n",
+		"| Number | Title | Status | Date |\n",
+		"| [ADR-001](0001-use-sqlite-postgres.md) | Use SQLite \\| Postgres | proposed | 2025-01-02 |\n",
+		"| ADR-002 | Only in the database | accepted |  |\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("ADRsIndex() README missing %q:\n%s", want, data)
+		}
+	}
+
+	// The index is not an ADR
+	_, synced, err := handler.ADRsSync(ctx, nil, types.ADRsSyncInput{})
+	if err != nil {
+		t.Fatalf("ADRsSync() unexpected error: %v", err)
+	}
+	if synced.Created != 0 || len(synced.Skipped) != 0 {
+		t.Errorf("ADRsSync() = %+v, want README.md ignored", synced)
+	}
+}
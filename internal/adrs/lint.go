@@ -0,0 +1,307 @@
+package adrs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+var (
+	// adrTitleNumber finds the number a title claims, as in "ADR-004: Title"
+	// or "4. Title".
+	adrTitleNumber = regexp.MustCompile(`(?i)^(?:ADR[- ]?0*(\d+)|0*(\d+)\s*[:.])`)
+	// markdownLink finds the targets of inline links.
+	markdownLink = regexp.MustCompile(`\]\(([^)#\s]+)[^)]*\)`)
+)
+
+// adrLine is a line of an ADR file with its 1-based line number.
+type adrLine struct {
+	text string
+	line int
+}
+
+// adrOutline is the structure of an ADR file that adrs_lint checks: its
+// format, headings, scope and status lines.
+type adrOutline struct {
+	madr     bool
+	title    adrLine
+	scope    adrLine
+	headings map[string]int // lowercase heading text to line number
+	status   []adrLine      // status section, front matter or metadata list
+}
+
+// ADRsLint checks the ADR files for the sections their format requires, a
+// valid status, scopes that still match something in the repository,
+// numbering without gaps or duplicates, and relation links that point at
+// existing ADRs and are stated on both sides.
+func (h *ADRsHandler) ADRsLint(ctx context.Context, req *mcp.CallToolRequest, input types.ADRsLintInput) (*mcp.CallToolResult, types.ADRsLintOutput, error) {
+	root := h.server.GetRepoRoot()
+	dir := h.server.GetADRPath()
+	output := types.ADRsLintOutput{Dir: repoPath(root, dir), Issues: []types.LintIssue{}}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, types.ADRsLintOutput{}, fmt.Errorf("failed to read ADR directory: %v", err)
+	}
+
+	type adrFile struct {
+		path      string
+		number    int
+		status    string
+		scope     []string
+		outline   adrOutline
+		relations map[models.ADRRelation]adrLine // relations to the line stating them
+	}
+	var files []adrFile
+	byNumber := make(map[int][]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !adrFileName.MatchString(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, types.ADRsLintOutput{}, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		adr, _ := parseADR(entry.Name(), string(data))
+		f := adrFile{
+			path:      repoPath(root, path),
+			number:    adr.Number,
+			status:    adr.Status,
+			scope:     splitStored(adr.Scope),
+			outline:   outlineADR(string(data)),
+			relations: make(map[models.ADRRelation]adrLine),
+		}
+		for _, l := range f.outline.status {
+			if r, ok := parseRelation(adr.ID, l.text); ok {
+				f.relations[r] = l
+			}
+		}
+		files = append(files, f)
+		byNumber[f.number] = append(byNumber[f.number], f.path)
+	}
+	output.Checked = len(files)
+
+	issue := func(file string, line int, rule, format string, args ...any) {
+		output.Issues = append(output.Issues, types.LintIssue{File: file, Line: line, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, f := range files {
+		id := fmt.Sprintf("ADR-%03d", f.number)
+
+		// Sections
+		for _, missing := range f.outline.missingSections() {
+			issue(f.path, 0, "adr-sections", "missing %s section", missing)
+		}
+
+		// Status
+		if first := f.outline.firstStatus(id); first.text == "" && f.status != statusSuperseded {
+			issue(f.path, 0, "adr-status", "no status")
+		} else if first.text != "" && !slices.Contains(adrStatuses, f.status) {
+			issue(f.path, first.line, "adr-status", "invalid status %q (allowed: %s)", f.status, strings.Join(adrStatuses, ", "))
+		}
+
+		// Scope
+		for _, entry := range f.scope {
+			if cleaned, err := scopePath(root, entry); err != nil {
+				issue(f.path, f.outline.scope.line, "adr-scope", "scope %s is outside the repository", entry)
+			} else if !scopeExists(root, cleaned) {
+				issue(f.path, f.outline.scope.line, "adr-scope", "scope %s matches nothing in the repository", entry)
+			}
+		}
+
+		// Numbering
+		if m := adrTitleNumber.FindStringSubmatch(f.outline.title.text); m != nil {
+			if n, _ := strconv.Atoi(m[1] + m[2]); n != f.number {
+				issue(f.path, f.outline.title.line, "adr-numbering", "title is numbered %d but the file is %04d", n, f.number)
+			}
+		}
+		if paths := byNumber[f.number]; len(paths) > 1 && paths[0] != f.path {
+			issue(f.path, 0, "adr-numbering", "%s is also numbered by %s", id, paths[0])
+		}
+
+		// Links
+		for r, l := range f.relations {
+			other := r.ToID
+			if r.ToID == id {
+				other = r.FromID
+			}
+			number, _ := strconv.Atoi(strings.TrimPrefix(other, "ADR-"))
+			for _, m := range markdownLink.FindAllStringSubmatch(l.text, -1) {
+				if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(m[1]))); err != nil {
+					issue(f.path, l.line, "adr-links", "link to %s is broken", m[1])
+				}
+			}
+			if len(byNumber[number]) == 0 {
+				issue(f.path, l.line, "adr-links", "%s has no file", other)
+				continue
+			}
+			if r.Kind != relationSupersedes {
+				continue
+			}
+			for _, o := range files {
+				if o.number != number {
+					continue
+				}
+				if _, ok := o.relations[r]; !ok {
+					issue(f.path, l.line, "adr-links", "%s does not link back to %s", other, id)
+				}
+				if r.ToID == other && o.status != statusSuperseded {
+					issue(f.path, l.line, "adr-links", "%s is superseded by %s but its status is %s", other, id, o.status)
+				}
+				break
+			}
+		}
+	}
+
+	// Gaps in the numbering
+	var numbers []int
+	for n := range byNumber {
+		numbers = append(numbers, n)
+	}
+	slices.Sort(numbers)
+	next := 1
+	for _, n := range numbers {
+		for missing := next; missing < n; missing++ {
+			issue(byNumber[n][0], 0, "adr-numbering", "ADR-%03d is missing before ADR-%03d", missing, n)
+		}
+		next = n + 1
+	}
+
+	slices.SortStableFunc(output.Issues, func(a, b types.LintIssue) int {
+		if c := strings.Compare(a.File, b.File); c != 0 {
+			return c
+		}
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return strings.Compare(a.Message, b.Message)
+	})
+	return nil, output, nil
+}
+
+// outlineADR reads the title, headings and status lines of an ADR file.
+func outlineADR(content string) adrOutline {
+	outline := adrOutline{headings: make(map[string]int)}
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		outline.madr = true
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				start = i + 1
+				break
+			}
+			key, value, ok := strings.Cut(lines[i], ":")
+			switch key = strings.ToLower(strings.TrimSpace(key)); {
+			case ok && key == "status":
+				outline.status = append(outline.status, adrLine{unquote(value), i + 1})
+			case ok && key == "scope":
+				outline.scope = adrLine{value, i + 1}
+			}
+		}
+	}
+
+	section := ""
+	fenced := false
+	for i := start; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "```") {
+			fenced = !fenced
+		}
+		if fenced || trimmed == "" {
+			continue
+		}
+		if title, ok := strings.CutPrefix(trimmed, "# "); ok && outline.title.text == "" {
+			outline.title = adrLine{strings.TrimSpace(title), i + 1}
+			continue
+		}
+		if heading, level := headingText(trimmed); level >= 2 {
+			heading = strings.ToLower(heading)
+			if _, ok := outline.headings[heading]; !ok {
+				outline.headings[heading] = i + 1
+			}
+			if heading == "context and problem statement" || heading == "decision outcome" {
+				outline.madr = true
+			}
+			if level == 2 {
+				section = heading
+			}
+			continue
+		}
+		switch {
+		case section == "status":
+			outline.status = append(outline.status, adrLine{trimmed, i + 1})
+		case section == "":
+			// MADR 2 keeps the status in a metadata list under the title
+			m := adrField.FindStringSubmatch(trimmed)
+			switch {
+			case m != nil && strings.EqualFold(m[1], "status"):
+				outline.madr = outline.madr || strings.HasPrefix(trimmed, "*") || strings.HasPrefix(trimmed, "-")
+				outline.status = append(outline.status, adrLine{m[2], i + 1})
+			case m != nil && strings.EqualFold(m[1], "scope"):
+				outline.scope = adrLine{m[2], i + 1}
+			}
+		}
+	}
+	return outline
+}
+
+// missingSections lists the sections the ADR's format requires but the
+// file lacks: Status, Context, Decision and Consequences for Nygard;
+// Context and Problem Statement, Decision Outcome and Consequences (at any
+// level) for MADR.
+func (o adrOutline) missingSections() []string {
+	has := func(field string) bool {
+		for heading := range o.headings {
+			if adrSections[heading] == field {
+				return true
+			}
+			if field == "consequences" && o.madr && strings.Contains(heading, "consequences") {
+				return true
+			}
+		}
+		return false
+	}
+
+	var missing []string
+	if o.madr {
+		for _, s := range []struct{ field, name string }{
+			{"context", "Context and Problem Statement"},
+			{"decision", "Decision Outcome"},
+			{"consequences", "Consequences"},
+		} {
+			if !has(s.field) {
+				missing = append(missing, s.name)
+			}
+		}
+		return missing
+	}
+	for _, field := range []string{"status", "context", "decision", "consequences"} {
+		// Some Nygard files keep "Status: Accepted" under the title instead
+		if !has(field) && (field != "status" || len(o.status) == 0) {
+			missing = append(missing, strings.ToUpper(field[:1])+field[1:])
+		}
+	}
+	return missing
+}
+
+// firstStatus returns the first status line that is not a relation.
+func (o adrOutline) firstStatus(id string) adrLine {
+	for _, l := range o.status {
+		if _, ok := parseRelation(id, l.text); !ok && l.text != "" {
+			return l
+		}
+	}
+	return adrLine{}
+}
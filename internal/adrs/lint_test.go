@@ -0,0 +1,119 @@
+package adrs
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestADRsHandler_Lint(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string // "file:line rule message"
+	}{
+		{
+			name: "Clean Nygard and MADR",
+			files: map[string]string{
+				"0001-use-go.md": "# 1. Use Go\n\nDate: 2024-05-06\n\n## Status\n\nSuperseded by [ADR-002](0002-use-go-125.md)\n\n" +
+					"## Context\n\nx\n\n## Decision\n\nx\n\n## Consequences\n\nx\n",
+				"0002-use-go-125.md": "# ADR-002: Use Go 1.25\n\n## Status\n\nAccepted\n\nSupersedes [ADR-001](0001-use-go.md)\n\n" +
+					"## Context\n\nx\n\n## Decision\n\nx\n\n## Consequences\n\nx\n",
+				"0003-cache.md": "---\nstatus: proposed\n---\n# Cache\n\n## Context and Problem Statement\n\nx\n\n" +
+					"## Decision Outcome\n\nx\n\n### Positive Consequences\n\n* x\n",
+			},
+		},
+		{
+			name: "Missing sections and invalid status",
+			files: map[string]string{
+				"0001-draft.md": "# 1. Draft\n\n## Status\n\nDraft\n\n## Context\n\nx\n",
+				"0002-madr.md":  "# Madr\n\n* Status: accepted\n\n## Context and Problem Statement\n\nx\n\n## Decision Outcome\n\nx\n",
+				"0003-none.md":  "# 3. None\n\n## Context\n\nx\n\n## Decision\n\nx\n\n## Consequences\n\nx\n",
+			},
+			want: []string{
+				"docs/adr/0001-draft.md:0 adr-sections missing Consequences section",
+				"docs/adr/0001-draft.md:0 adr-sections missing Decision section",
+				`docs/adr/0001-draft.md:5 adr-status invalid status "draft" (allowed: proposed, accepted, rejected, deprecated, superseded)`,
+				"docs/adr/0002-madr.md:0 adr-sections missing Consequences section",
+				"docs/adr/0003-none.md:0 adr-sections missing Status section",
+				"docs/adr/0003-none.md:0 adr-status no status",
+			},
+		},
+		{
+			name: "Stale scope",
+			files: map[string]string{
+				"0001-layout.md": "# 1. Layout\n\nScope: docs/adr, docs/*.md, legacy/**/*.go\n\n## Status\n\nAccepted\n\n" +
+					"## Context\n\nx\n\n## Decision\n\nx\n\n## Consequences\n\nx\n",
+			},
+			want: []string{
+				"docs/adr/0001-layout.md:3 adr-scope scope docs/*.md matches nothing in the repository",
+				"docs/adr/0001-layout.md:3 adr-scope scope legacy/**/*.go matches nothing in the repository",
+			},
+		},
+		{
+			name: "Numbering",
+			files: map[string]string{
+				"0001-a.md": "# 1. A\n\nStatus: Accepted\n\n## Context\n\nx\n\n## Decision\n\nx\n\n## Consequences\n\nx\n",
+				"0001-b.md": "# 1. B\n\nStatus: Accepted\n\n## Context\n\nx\n\n## Decision\n\nx\n\n## Consequences\n\nx\n",
+				"0004-d.md": "# ADR-003: D\n\nStatus: Accepted\n\n## Context\n\nx\n\n## Decision\n\nx\n\n## Consequences\n\nx\n",
+			},
+			want: []string{
+				"docs/adr/0001-b.md:0 adr-numbering ADR-001 is also numbered by docs/adr/0001-a.md",
+				"docs/adr/0004-d.md:0 adr-numbering ADR-002 is missing before ADR-004",
+				"docs/adr/0004-d.md:0 adr-numbering ADR-003 is missing before ADR-004",
+				"docs/adr/0004-d.md:1 adr-numbering title is numbered 3 but the file is 0004",
+			},
+		},
+		{
+			name: "Broken supersede links",
+			files: map[string]string{
+				"0001-old.md": "# 1. Old\n\n## Status\n\nAccepted\n\n## Context\n\nx\n\n## Decision\n\nx\n\n## Consequences\n\nx\n",
+				"0002-new.md": "# 2. New\n\n## Status\n\nAccepted\n\nSupersedes [ADR-001](0001-renamed.md)\n\nAmends ADR-009\n\n" +
+					"## Context\n\nx\n\n## Decision\n\nx\n\n## Consequences\n\nx\n",
+			},
+			want: []string{
+				"docs/adr/0002-new.md:7 adr-links ADR-001 does not link back to ADR-002",
+				"docs/adr/0002-new.md:7 adr-links ADR-001 is superseded by ADR-002 but its status is accepted",
+				"docs/adr/0002-new.md:7 adr-links link to 0001-renamed.md is broken",
+				"docs/adr/0002-new.md:9 adr-links ADR-009 has no file",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			srv, err := server.NewServer(tempDir)
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+			defer srv.Close()
+
+			dir := filepath.Join(tempDir, "docs", "adr")
+			for name, content := range tt.files {
+				writeADRFile(t, dir, name, content)
+			}
+			writeADRFile(t, dir, "README.md", "# Index\n")
+
+			_, output, err := NewADRsHandler(srv).ADRsLint(context.Background(), nil, types.ADRsLintInput{})
+			if err != nil {
+				t.Fatalf("ADRsLint() unexpected error: %v", err)
+			}
+			if output.Checked != len(tt.files) {
+				t.Errorf("ADRsLint() checked %d files, want %d", output.Checked, len(tt.files))
+			}
+			var got []string
+			for _, issue := range output.Issues {
+				got = append(got, issue.File+":"+strconv.Itoa(issue.Line)+" "+issue.Rule+" "+issue.Message)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("ADRsLint() issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
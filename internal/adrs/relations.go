@@ -0,0 +1,208 @@
+package adrs
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ADR statuses. superseded is only set by superseding an ADR.
+const (
+	statusProposed   = "proposed"
+	statusAccepted   = "accepted"
+	statusRejected   = "rejected"
+	statusDeprecated = "deprecated"
+	statusSuperseded = "superseded"
+)
+
+// adrStatuses are the statuses an ADR may have.
+var adrStatuses = []string{statusProposed, statusAccepted, statusRejected, statusDeprecated, statusSuperseded}
+
+// adrTransitions is the ADR status lifecycle: the statuses each status may
+// move to. Statuses outside of it, e.g. from hand-written files, may move
+// anywhere.
+var adrTransitions = map[string][]string{
+	statusProposed:   {statusAccepted, statusRejected},
+	statusAccepted:   {statusDeprecated, statusSuperseded},
+	statusRejected:   {statusProposed},
+	statusDeprecated: {statusAccepted, statusSuperseded},
+	statusSuperseded: {},
+}
+
+// ADRRelation kinds, stored from the ADR that makes the statement: ADR-002
+// supersedes ADR-001.
+const (
+	relationSupersedes = "supersedes"
+	relationAmends     = "amends"
+	relationRelatesTo  = "relates-to"
+)
+
+var relationKinds = []string{relationSupersedes, relationAmends, relationRelatesTo}
+
+// relationInverse names a relation as seen from its target.
+var relationInverse = map[string]string{
+	relationSupersedes: "superseded-by",
+	relationAmends:     "amended-by",
+	relationRelatesTo:  relationRelatesTo,
+}
+
+// relationNames are the relation kinds and their inverses, for filtering.
+var relationNames = []string{"supersedes", "superseded-by", "amends", "amended-by", "relates-to"}
+
+// relationLabels are how relations read in the status section of an ADR
+// file, as adr-tools writes them: forward for the source, inverse for the
+// target.
+var relationLabels = map[string][2]string{
+	relationSupersedes: {"Supersedes", "Superseded by"},
+	relationAmends:     {"Amends", "Amended by"},
+	relationRelatesTo:  {"Relates to", "Relates to"},
+}
+
+var (
+	// relationLine matches a relation in an ADR's status section.
+	relationLine = regexp.MustCompile(`(?i)^(supersedes|superseded by|amends|amended by|relates to)\s+(.+)$`)
+	// relationRef finds the ADR a relation line points to: "ADR-002",
+	// a link to "0002-title.md" or adr-tools' "[2. Title]".
+	relationRef = regexp.MustCompile(`(?i)ADR[- ]?0*(\d+)|\((?:[^)]*/)?0*(\d+)-[^)]*\.md\)|^\[0*(\d+)\.`)
+)
+
+// canTransition reports whether an ADR may move from one status to another.
+func canTransition(from, to string) bool {
+	allowed, ok := adrTransitions[from]
+	return from == to || !ok || slices.Contains(allowed, to)
+}
+
+// ADRsRelate records a typed relation between two ADRs and rewrites both
+// markdown files. Superseding an ADR also marks it superseded.
+func (h *ADRsHandler) ADRsRelate(ctx context.Context, req *mcp.CallToolRequest, input types.ADRsRelateInput) (*mcp.CallToolResult, types.ADRsRelateOutput, error) {
+	var output types.ADRsRelateOutput
+	err := h.author(func(tx *gorm.DB) ([]adrFile, error) {
+		added, files, err := h.relate(tx, input.From, input.Kind, input.To)
+		output.Added, output.Paths = added, filePaths(h.server.GetRepoRoot(), files)
+		return files, err
+	})
+	if err != nil {
+		return nil, types.ADRsRelateOutput{}, err
+	}
+	return nil, output, nil
+}
+
+// relate adds a relation from one ADR to another and stages both files.
+func (h *ADRsHandler) relate(tx *gorm.DB, fromID, kind, toID string) (bool, []adrFile, error) {
+	if !slices.Contains(relationKinds, kind) {
+		return false, nil, fmt.Errorf("unknown relation %q (allowed: %s)", kind, strings.Join(relationKinds, ", "))
+	}
+	if fromID == toID {
+		return false, nil, fmt.Errorf("%s cannot be related to itself", fromID)
+	}
+
+	var from, to models.ADR
+	if err := findADR(tx, fromID, &from); err != nil {
+		return false, nil, err
+	}
+	if err := findADR(tx, toID, &to); err != nil {
+		return false, nil, err
+	}
+
+	if kind == relationSupersedes && to.Status != statusSuperseded {
+		if !canTransition(to.Status, statusSuperseded) {
+			return false, nil, fmt.Errorf("%s is %s and cannot be superseded (allowed: %s)", to.ID, to.Status, strings.Join(adrTransitions[to.Status], ", "))
+		}
+		to.Status = statusSuperseded
+		if err := tx.Model(&to).Update("status", to.Status).Error; err != nil {
+			return false, nil, err
+		}
+	}
+
+	// relates-to reads the same both ways, so it is only stored once
+	if kind == relationRelatesTo {
+		var count int64
+		if err := tx.Model(&models.ADRRelation{}).Where("from_id = ? AND kind = ? AND to_id = ?", to.ID, kind, from.ID).Count(&count).Error; err != nil {
+			return false, nil, err
+		}
+		if count > 0 {
+			return false, nil, nil
+		}
+	}
+
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ADRRelation{FromID: from.ID, Kind: kind, ToID: to.ID})
+	if result.Error != nil {
+		return false, nil, result.Error
+	}
+
+	var files []adrFile
+	for _, adr := range []*models.ADR{&to, &from} {
+		file, err := h.stageADR(tx, adr)
+		if err != nil {
+			return false, nil, err
+		}
+		files = append(files, file)
+	}
+	return result.RowsAffected > 0, files, nil
+}
+
+// loadRelations returns every ADR's relations as seen from that ADR, with
+// incoming relations under their inverse name.
+func loadRelations(db *gorm.DB) (map[string][]types.ADRLink, error) {
+	var relations []models.ADRRelation
+	if err := db.Order("id ASC").Find(&relations).Error; err != nil {
+		return nil, err
+	}
+	links := make(map[string][]types.ADRLink)
+	for _, r := range relations {
+		links[r.FromID] = append(links[r.FromID], types.ADRLink{Kind: r.Kind, ID: r.ToID})
+		links[r.ToID] = append(links[r.ToID], types.ADRLink{Kind: relationInverse[r.Kind], ID: r.FromID})
+	}
+	return links, nil
+}
+
+// matchesRelation reports whether an ADR has a relation of the given kind
+// (forward or inverse name) to the given ADR; empty arguments match anything.
+func matchesRelation(links []types.ADRLink, kind, id string) bool {
+	for _, l := range links {
+		if (kind == "" || l.Kind == kind) && (id == "" || l.ID == id) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRelation reads a relation line from an ADR's status section, such as
+// "Superseded by [ADR-005](0005-x.md)", as a relation of the ADR with the
+// given ID.
+func parseRelation(id, line string) (models.ADRRelation, bool) {
+	m := relationLine.FindStringSubmatch(line)
+	if m == nil {
+		return models.ADRRelation{}, false
+	}
+	ref := relationRef.FindStringSubmatch(strings.TrimSpace(m[2]))
+	if ref == nil {
+		return models.ADRRelation{}, false
+	}
+	digits := ref[1] + ref[2] + ref[3]
+	number, err := strconv.Atoi(digits)
+	if err != nil {
+		return models.ADRRelation{}, false
+	}
+	other := fmt.Sprintf("ADR-%03d", number)
+
+	label := strings.ToLower(m[1])
+	for kind, labels := range relationLabels {
+		switch label {
+		case strings.ToLower(labels[0]):
+			return models.ADRRelation{FromID: id, Kind: kind, ToID: other}, true
+		case strings.ToLower(labels[1]):
+			return models.ADRRelation{FromID: other, Kind: kind, ToID: id}, true
+		}
+	}
+	return models.ADRRelation{}, false
+}
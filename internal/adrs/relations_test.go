@@ -0,0 +1,224 @@
+package adrs
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestParseRelation(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   models.ADRRelation
+		wantOK bool
+	}{
+		{line: "Superseded by [ADR-005](0005-x.md)", want: models.ADRRelation{FromID: "ADR-005", Kind: "supersedes", ToID: "ADR-002"}, wantOK: true},
+		{line: "Amends [1. Record decisions](0001-record-decisions.md)", want: models.ADRRelation{FromID: "ADR-002", Kind: "amends", ToID: "ADR-001"}, wantOK: true},
+		{line: "Relates to ADR 7", want: models.ADRRelation{FromID: "ADR-002", Kind: "relates-to", ToID: "ADR-007"}, wantOK: true},
+		{line: "Accepted", wantOK: false},
+		{line: "Supersedes the old approach", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRelation("ADR-002", tt.line)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseRelation(%q) = %+v, %v, want %+v, %v", tt.line, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestADRsHandler_Relations(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewADRsHandler(srv)
+	ctx := context.Background()
+
+	accepted := "accepted"
+	var ids []string
+	for _, title := range []string{"Use MySQL", "Use PostgreSQL", "Tune indexes"} {
+		_, created, err := handler.ADRsCreate(ctx, nil, types.ADRsCreateInput{Title: title})
+		if err != nil {
+			t.Fatalf("ADRsCreate() unexpected error: %v", err)
+		}
+		ids = append(ids, created.ID)
+	}
+
+	// The lifecycle is enforced
+	deprecated := "deprecated"
+	if _, _, err := handler.ADRsUpdate(ctx, nil, types.ADRsUpdateInput{ID: ids[0], Status: &deprecated}); err == nil {
+		t.Errorf("ADRsUpdate() proposed to deprecated expected error, got nil")
+	}
+	if _, _, err := handler.ADRsRelate(ctx, nil, types.ADRsRelateInput{From: ids[1], Kind: "supersedes", To: ids[0]}); err == nil {
+		t.Errorf("ADRsRelate() superseding a proposed ADR expected error, got nil")
+	}
+	if _, _, err := handler.ADRsRelate(ctx, nil, types.ADRsRelateInput{From: ids[1], Kind: "replaces", To: ids[0]}); err == nil {
+		t.Errorf("ADRsRelate() with unknown kind expected error, got nil")
+	}
+	for _, id := range ids[:2] {
+		if _, _, err := handler.ADRsUpdate(ctx, nil, types.ADRsUpdateInput{ID: id, Status: &accepted}); err != nil {
+			t.Fatalf("ADRsUpdate() unexpected error: %v", err)
+		}
+	}
+
+	relate := func(from, kind, to string) types.ADRsRelateOutput {
+		t.Helper()
+		_, output, err := handler.ADRsRelate(ctx, nil, types.ADRsRelateInput{From: from, Kind: kind, To: to})
+		if err != nil {
+			t.Fatalf("ADRsRelate(%s %s %s) unexpected error: %v", from, kind, to, err)
+		}
+		return output
+	}
+	relate(ids[1], "supersedes", ids[0])
+	if output := relate(ids[2], "amends", ids[1]); !output.Added || len(output.Paths) != 2 {
+		t.Errorf("ADRsRelate() = %+v, want added and both files rewritten", output)
+	}
+	relate(ids[0], "relates-to", ids[2])
+	if output := relate(ids[2], "relates-to", ids[0]); output.Added {
+		t.Errorf("ADRsRelate() relates-to in reverse = %+v, want not added", output)
+	}
+
+	_, got, err := handler.ADRsGet(ctx, nil, types.ADRsGetInput{ID: ids[1]})
+	if err != nil {
+		t.Fatalf("ADRsGet() unexpected error: %v", err)
+	}
+	if len(got.Relations) != 2 || got.Relations[0] != (types.ADRLink{Kind: "supersedes", ID: ids[0]}) || got.Relations[1] != (types.ADRLink{Kind: "amended-by", ID: ids[2]}) {
+		t.Errorf("ADRsGet() relations = %+v, want supersedes %s and amended-by %s", got.Relations, ids[0], ids[2])
+	}
+	if !strings.Contains(got.Content, "Amended by [") {
+		t.Errorf("ADRsGet() content missing the amended-by line:\n%s", got.Content)
+	}
+
+	// List filters
+	tests := []struct {
+		name    string
+		input   types.ADRsListInput
+		want    []string
+		wantErr bool
+	}{
+		{name: "By status", input: types.ADRsListInput{Statuses: []string{"superseded", "proposed"}}, want: []string{ids[0], ids[2]}},
+		{name: "By relation", input: types.ADRsListInput{Relation: "superseded-by"}, want: []string{ids[0]}},
+		{name: "By related ADR", input: types.ADRsListInput{RelatedTo: ids[0]}, want: []string{ids[1], ids[2]}},
+		{name: "By relation and ADR", input: types.ADRsListInput{Relation: "relates-to", RelatedTo: ids[0]}, want: []string{ids[2]}},
+		{name: "Unknown relation", input: types.ADRsListInput{Relation: "replaces"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, list, err := handler.ADRsList(ctx, nil, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ADRsList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var gotIDs []string
+			for _, adr := range list.ADRs {
+				gotIDs = append(gotIDs, adr.ID)
+			}
+			if strings.Join(gotIDs, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ADRsList() = %v, want %v", gotIDs, tt.want)
+			}
+		})
+	}
+
+	// Relations written to the files survive a fresh database
+	srv2, err := server.NewServer(filepath.Join(tempDir, "other"))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv2.Close()
+	t.Setenv("MCP_ADR_PATH", filepath.Join(tempDir, "docs", "adr"))
+	_, synced, err := NewADRsHandler(srv2).ADRsSync(ctx, nil, types.ADRsSyncInput{})
+	if err != nil {
+		t.Fatalf("ADRsSync() unexpected error: %v", err)
+	}
+	if synced.Created != 3 || synced.Relations != 3 {
+		t.Errorf("ADRsSync() = %+v, want 3 ADRs and 3 relations", synced)
+	}
+}
+
+func TestADRsHandler_Graph(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewADRsHandler(srv)
+	ctx := context.Background()
+
+	db := srv.GetDB()
+	db.Create(&models.ADR{ID: "ADR-001", Number: 1, Title: `Use "MySQL"`, Status: "superseded"})
+	db.Create(&models.ADR{ID: "ADR-002", Number: 2, Title: "Use PostgreSQL", Status: "accepted"})
+	db.Create(&models.ADR{ID: "ADR-003", Number: 3, Title: "Shard", Status: "rejected"})
+	db.Create(&models.ADRRelation{FromID: "ADR-002", Kind: "supersedes", ToID: "ADR-001"})
+	db.Create(&models.ADRRelation{FromID: "ADR-003", Kind: "relates-to", ToID: "ADR-002"})
+	db.Create(&models.ADR{ID: "RFC 4.1", Number: 4, Title: "Cache", Status: "proposed"})
+	db.Create(&models.ADR{ID: "RFC_4_1", Number: 5, Title: "Cache more", Status: "proposed"})
+	db.Create(&models.ADRRelation{FromID: "RFC_4_1", Kind: "relates-to", ToID: "RFC 4.1"})
+
+	tests := []struct {
+		name      string
+		input     types.ADRsGraphInput
+		wantNodes int
+		wantEdges int
+		contains  []string
+		wantErr   bool
+	}{
+		{
+			name:      "Mermaid",
+			input:     types.ADRsGraphInput{},
+			wantNodes: 5,
+			wantEdges: 3,
+			contains: []string{
+				"graph LR\n",
+				`RFC_4_1["RFC 4.1: Cache<br/>proposed"]`,
+				`RFC_4_1_2["RFC_4_1: Cache more<br/>proposed"]`,
+				"RFC_4_1_2 ---|relates to| RFC_4_1",
+				`ADR_001["ADR-001: Use #quot;MySQL#quot;<br/>superseded"]`,
+				"ADR_002 -->|supersedes| ADR_001",
+				"ADR_003 ---|relates to| ADR_002",
+				"class ADR_002 accepted",
+			},
+		},
+		{
+			name:      "DOT by status",
+			input:     types.ADRsGraphInput{Format: "dot", Statuses: []string{"accepted", "superseded"}},
+			wantNodes: 2,
+			wantEdges: 1,
+			contains: []string{
+				"digraph adrs {",
+				`"ADR-001" [label="ADR-001\nUse \"MySQL\"\n(superseded)"`,
+				`"ADR-002" -> "ADR-001" [label="supersedes"];`,
+			},
+		},
+		{name: "Unknown format", input: types.ADRsGraphInput{Format: "svg"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := handler.ADRsGraph(ctx, nil, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ADRsGraph() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if output.Nodes != tt.wantNodes || output.Edges != tt.wantEdges {
+				t.Errorf("ADRsGraph() = %d nodes, %d edges, want %d, %d", output.Nodes, output.Edges, tt.wantNodes, tt.wantEdges)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(output.Graph, want) {
+					t.Errorf("ADRsGraph() missing %q in:\n%s", want, output.Graph)
+				}
+			}
+		})
+	}
+}
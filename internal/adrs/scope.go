@@ -0,0 +1,214 @@
+package adrs
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+// activeStatuses are the statuses of decisions that still govern their scope.
+var activeStatuses = []string{statusProposed, statusAccepted}
+
+// ADRsForPath returns the ADRs whose scope covers a file or package. A scope
+// entry covers a path when it matches the path or one of its parent
+// directories; a package is covered when the directory or any file directly
+// in it is.
+func (h *ADRsHandler) ADRsForPath(ctx context.Context, req *mcp.CallToolRequest, input types.ADRsForPathInput) (*mcp.CallToolResult, types.ADRsForPathOutput, error) {
+	if strings.TrimSpace(input.Path) == "" {
+		return nil, types.ADRsForPathOutput{}, fmt.Errorf("path required")
+	}
+	root := h.server.GetRepoRoot()
+	target, err := scopePath(root, input.Path)
+	if err != nil {
+		return nil, types.ADRsForPathOutput{}, err
+	}
+
+	candidates := []string{target}
+	if entries, err := os.ReadDir(absPath(root, target)); err == nil {
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				candidates = append(candidates, path.Join(target, entry.Name()))
+			}
+		}
+	}
+
+	db := h.server.GetDB()
+	query := db.Where("scope <> ''")
+	if !input.Inactive {
+		query = query.Where("status IN ?", activeStatuses)
+	}
+	var adrs []models.ADR
+	if err := query.Order("number ASC, id ASC").Find(&adrs).Error; err != nil {
+		return nil, types.ADRsForPathOutput{}, err
+	}
+	relations, err := loadRelations(db)
+	if err != nil {
+		return nil, types.ADRsForPathOutput{}, err
+	}
+
+	output := types.ADRsForPathOutput{Path: target, ADRs: []types.ADR{}}
+	for _, adr := range adrs {
+		// Hand-written files may scope by Go import path
+		var scope []string
+		for _, entry := range splitStored(adr.Scope) {
+			if cleaned, err := scopePath(root, entry); err == nil {
+				scope = append(scope, cleaned)
+			}
+		}
+		if coversAny(scope, candidates) {
+			output.ADRs = append(output.ADRs, toTypesADR(adr, relations[adr.ID]))
+		}
+	}
+	return nil, output, nil
+}
+
+// cleanScope checks scope entries and joins them for storage.
+func (h *ADRsHandler) cleanScope(entries []string) (string, error) {
+	root := h.server.GetRepoRoot()
+	var scope []string
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		if strings.Contains(entry, ",") {
+			return "", fmt.Errorf("scope %q cannot contain commas", entry)
+		}
+		cleaned, err := scopePath(root, entry)
+		if err != nil {
+			return "", err
+		}
+		if _, err := path.Match(cleaned, ""); err != nil {
+			return "", fmt.Errorf("invalid scope %q: %v", entry, err)
+		}
+		scope = append(scope, cleaned)
+	}
+	return strings.Join(scope, ", "), nil
+}
+
+// scopePath turns a path, glob or Go import path into a slash-separated
+// path relative to the repository root.
+func scopePath(root, p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if filepath.IsAbs(p) {
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return "", fmt.Errorf("%s is outside the repository", p)
+		}
+		p = rel
+	}
+	p = filepath.ToSlash(p)
+	if module := modulePath(root); module != "" {
+		if p == module {
+			p = "."
+		} else if rest, ok := strings.CutPrefix(p, module+"/"); ok {
+			p = rest
+		}
+	}
+	p = path.Clean(p)
+	if p == ".." || strings.HasPrefix(p, "../") {
+		return "", fmt.Errorf("%s is outside the repository", p)
+	}
+	return p, nil
+}
+
+// modulePath returns the module path declared in the repository's go.mod,
+// or "" if there is none.
+func modulePath(root string) string {
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return unquote(module)
+		}
+	}
+	return ""
+}
+
+func coversAny(scope, paths []string) bool {
+	for _, pattern := range scope {
+		for _, p := range paths {
+			if covers(pattern, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// covers reports whether a scope entry matches p or one of its parents, so
+// that "internal/server" and "internal/*" both cover
+// "internal/server/server.go".
+func covers(pattern, p string) bool {
+	if pattern == "." {
+		return true
+	}
+	segments := strings.Split(p, "/")
+	for i := len(segments); i > 0; i-- {
+		if matchSegments(strings.Split(pattern, "/"), segments[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against glob segments, where "**"
+// matches any number of segments and the rest follow path.Match.
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], segments[0])
+	return ok && matchSegments(pattern[1:], segments[1:])
+}
+
+// scopeExists reports whether anything in the repository matches a scope
+// entry.
+func scopeExists(root, pattern string) bool {
+	segments := strings.Split(pattern, "/")
+	literal := 0
+	for literal < len(segments) && !strings.ContainsAny(segments[literal], `*?[\`) {
+		literal++
+	}
+	base := absPath(root, path.Join(segments[:literal]...))
+	if literal == len(segments) {
+		_, err := os.Stat(base)
+		return err == nil
+	}
+
+	found := false
+	filepath.WalkDir(base, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if matchSegments(segments, strings.Split(repoPath(root, p), "/")) {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
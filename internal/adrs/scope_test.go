@@ -0,0 +1,131 @@
+package adrs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestCovers(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "internal/server", path: "internal/server", want: true},
+		{pattern: "internal/server", path: "internal/server/server.go", want: true},
+		{pattern: "internal/server", path: "internal/serverless/x.go", want: false},
+		{pattern: "internal/*", path: "internal/server/server.go", want: true},
+		{pattern: "internal/*/*_test.go", path: "internal/server/server_test.go", want: true},
+		{pattern: "internal/*/*_test.go", path: "internal/server/server.go", want: false},
+		{pattern: "**/*.sql", path: "db/migrations/001.sql", want: true},
+		{pattern: "cmd/**", path: "cmd", want: true},
+		{pattern: ".", path: "README.md", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := covers(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("covers(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestADRsHandler_ForPath(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewADRsHandler(srv)
+	ctx := context.Background()
+
+	if err := os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte("module example.com/app\n\ngo 1.25\n"), 0644); err != nil {
+		t.Fatalf("Failed to write go.mod: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tempDir, "internal", "server"), 0755); err != nil {
+		t.Fatalf("Failed to create package: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "internal", "server", "server_test.go"), []byte("package server\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	create := func(title string, scope ...string) string {
+		t.Helper()
+		_, created, err := handler.ADRsCreate(ctx, nil, types.ADRsCreateInput{Title: title, Scope: scope})
+		if err != nil {
+			t.Fatalf("ADRsCreate() unexpected error: %v", err)
+		}
+		return created.ID
+	}
+	sqlite := create("Use SQLite", "example.com/app/internal/server")
+	tests := create("Table-driven tests", "internal/**/*_test.go")
+	create("Use Cobra", "cmd")
+	old := create("Use BoltDB", "internal/server/**")
+	accepted := "accepted"
+	if _, _, err := handler.ADRsUpdate(ctx, nil, types.ADRsUpdateInput{ID: old, Status: &accepted}); err != nil {
+		t.Fatalf("ADRsUpdate() unexpected error: %v", err)
+	}
+	if _, _, err := handler.ADRsSupersede(ctx, nil, types.ADRsSupersedeInput{ID: old, By: sqlite}); err != nil {
+		t.Fatalf("ADRsSupersede() unexpected error: %v", err)
+	}
+
+	if _, _, err := handler.ADRsCreate(ctx, nil, types.ADRsCreateInput{Title: "Bad", Scope: []string{"../elsewhere"}}); err == nil {
+		t.Errorf("ADRsCreate() with scope outside the repository expected error, got nil")
+	}
+	if _, _, err := handler.ADRsCreate(ctx, nil, types.ADRsCreateInput{Title: "Bad", Scope: []string{"internal/[x"}}); err == nil {
+		t.Errorf("ADRsCreate() with malformed glob expected error, got nil")
+	}
+
+	// The scope is written to the file and read back by sync
+	_, got, err := handler.ADRsGet(ctx, nil, types.ADRsGetInput{ID: sqlite})
+	if err != nil {
+		t.Fatalf("ADRsGet() unexpected error: %v", err)
+	}
+	if strings.Join(got.Scope, ",") != "internal/server" || !strings.Contains(got.Content, "Scope: internal/server\n") {
+		t.Errorf("ADRsGet() scope = %v, content:\n%s", got.Scope, got.Content)
+	}
+	_, synced, err := handler.ADRsSync(ctx, nil, types.ADRsSyncInput{})
+	if err != nil {
+		t.Fatalf("ADRsSync() unexpected error: %v", err)
+	}
+	if synced.Updated != 0 {
+		t.Errorf("ADRsSync() = %+v, want the written files unchanged", synced)
+	}
+
+	lookups := []struct {
+		name     string
+		input    types.ADRsForPathInput
+		wantPath string
+		want     []string
+	}{
+		{name: "Package", input: types.ADRsForPathInput{Path: "internal/server"}, wantPath: "internal/server", want: []string{sqlite, tests}},
+		{name: "Import path", input: types.ADRsForPathInput{Path: "example.com/app/internal/server"}, wantPath: "internal/server", want: []string{sqlite, tests}},
+		{name: "File", input: types.ADRsForPathInput{Path: filepath.Join(tempDir, "internal", "server", "server.go")}, wantPath: "internal/server/server.go", want: []string{sqlite}},
+		{name: "Inactive", input: types.ADRsForPathInput{Path: "internal/server/server.go", Inactive: true}, wantPath: "internal/server/server.go", want: []string{sqlite, old}},
+		{name: "Unscoped", input: types.ADRsForPathInput{Path: "README.md"}, wantPath: "README.md"},
+	}
+
+	for _, tt := range lookups {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := handler.ADRsForPath(ctx, nil, tt.input)
+			if err != nil {
+				t.Fatalf("ADRsForPath() unexpected error: %v", err)
+			}
+			var ids []string
+			for _, adr := range output.ADRs {
+				ids = append(ids, adr.ID)
+			}
+			if output.Path != tt.wantPath || strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ADRsForPath() = %s %v, want %s %v", output.Path, ids, tt.wantPath, tt.want)
+			}
+		})
+	}
+}
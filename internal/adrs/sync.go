@@ -0,0 +1,422 @@
+package adrs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
+)
+
+var (
+	// adrFileName matches NNNN-title.md, the naming used by adr-tools and MADR.
+	adrFileName = regexp.MustCompile(`^(\d+)-.*\.md$`)
+	// adrTitlePrefix matches the number in titles such as "ADR-001: Title" or
+	// "1. Title".
+	adrTitlePrefix = regexp.MustCompile(`(?i)^(?:ADR[- ]?\d+\s*[:.]?|\d+\s*[:.])\s*`)
+	// adrField matches metadata lines such as "Date: 2025-01-02" (Nygard) or
+	// "* Status: accepted" (MADR 2).
+	adrField = regexp.MustCompile(`(?i)^(?:[*-]\s+)?(status|date|deciders|decision-makers|scope)\s*:\s*(.*)$`)
+)
+
+// adrSections maps section headings of the Nygard and MADR templates to the
+// ADR field they fill.
+var adrSections = map[string]string{
+	"status":                        "status",
+	"context":                       "context",
+	"context and problem statement": "context",
+	"problem statement":             "context",
+	"decision":                      "decision",
+	"decision outcome":              "decision",
+	"consequences":                  "consequences",
+}
+
+// ADRsSync reads the ADR directory and updates the database to match it.
+func (h *ADRsHandler) ADRsSync(ctx context.Context, req *mcp.CallToolRequest, input types.ADRsSyncInput) (*mcp.CallToolResult, types.ADRsSyncOutput, error) {
+	output, err := h.Sync()
+	if err != nil {
+		return nil, types.ADRsSyncOutput{}, err
+	}
+	return nil, output, nil
+}
+
+// Sync parses every NNNN-title.md file in the ADR directory and creates or
+// updates the matching ADR-NNN records. Records whose file was in the ADR
+// directory but has been deleted are removed; ADRs that only exist in the
+// database are left alone.
+func (h *ADRsHandler) Sync() (types.ADRsSyncOutput, error) {
+	h.syncMu.Lock()
+	defer h.syncMu.Unlock()
+
+	root := h.server.GetRepoRoot()
+	dir := h.server.GetADRPath()
+	output := types.ADRsSyncOutput{Dir: repoPath(root, dir), Skipped: []types.SkippedADRFile{}}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return types.ADRsSyncOutput{}, fmt.Errorf("failed to read ADR directory: %v", err)
+	}
+
+	parsed := make(map[string]models.ADR)
+	var ids []string
+	var relations []models.ADRRelation
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		rel := repoPath(root, path)
+		if !adrFileName.MatchString(entry.Name()) {
+			// README.md, templates and indexes are not ADRs
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			output.Skipped = append(output.Skipped, types.SkippedADRFile{Path: rel, Reason: err.Error()})
+			continue
+		}
+		adr, adrRelations := parseADR(entry.Name(), string(data))
+		if other, ok := parsed[adr.ID]; ok {
+			output.Skipped = append(output.Skipped, types.SkippedADRFile{Path: rel, Reason: fmt.Sprintf("%s is already %s", adr.ID, other.FilePath)})
+			continue
+		}
+		adr.FilePath = rel
+		parsed[adr.ID] = adr
+		ids = append(ids, adr.ID)
+		relations = append(relations, adrRelations...)
+	}
+
+	err = h.server.GetDB().Transaction(func(tx *gorm.DB) error {
+		var existing []models.ADR
+		if err := tx.Find(&existing).Error; err != nil {
+			return err
+		}
+		known := make(map[string]models.ADR, len(existing))
+		for _, adr := range existing {
+			known[adr.ID] = adr
+		}
+
+		for _, id := range ids {
+			adr := parsed[id]
+			current, ok := known[adr.ID]
+			switch {
+			case !ok:
+				if err := tx.Create(&adr).Error; err != nil {
+					return err
+				}
+				output.Created++
+			case sameADR(current, adr):
+				output.Unchanged++
+			default:
+				err := tx.Model(&current).
+					Select("number", "title", "status", "date", "deciders", "scope", "context", "decision", "consequences", "file_path", "content").
+					Updates(&adr).Error
+				if err != nil {
+					return err
+				}
+				output.Updated++
+			}
+		}
+
+		// Relations found in files are added, never removed: they may have
+		// been recorded by adrs_relate for ADRs without a file
+		for _, r := range relations {
+			if !exists(known, parsed, r.FromID) || !exists(known, parsed, r.ToID) {
+				continue
+			}
+			query := tx.Model(&models.ADRRelation{}).Where("from_id = ? AND kind = ? AND to_id = ?", r.FromID, r.Kind, r.ToID)
+			if r.Kind == relationRelatesTo {
+				query = query.Or("from_id = ? AND kind = ? AND to_id = ?", r.ToID, r.Kind, r.FromID)
+			}
+			var count int64
+			if err := query.Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+			if err := tx.Create(&r).Error; err != nil {
+				return err
+			}
+			output.Relations++
+		}
+
+		for _, adr := range existing {
+			if _, ok := parsed[adr.ID]; ok || adr.FilePath == "" || !inDir(root, dir, adr.FilePath) {
+				continue
+			}
+			if _, err := os.Stat(absPath(root, adr.FilePath)); !os.IsNotExist(err) {
+				continue
+			}
+			if err := tx.Where("from_id = ? OR to_id = ?", adr.ID, adr.ID).Delete(&models.ADRRelation{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&adr).Error; err != nil {
+				return err
+			}
+			output.Removed++
+		}
+		return nil
+	})
+	if err != nil {
+		return types.ADRsSyncOutput{}, err
+	}
+
+	return output, nil
+}
+
+// Watch keeps the database in sync with the ADR directory, checking it for
+// added, changed or removed markdown files every interval until ctx is done.
+func (h *ADRsHandler) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := h.snapshot()
+		if current == last {
+			continue
+		}
+		last = current
+		if _, err := h.Sync(); err != nil {
+			log.Printf("ADR sync failed: %v", err)
+		}
+	}
+}
+
+// snapshot describes the markdown files in the ADR directory by name, size
+// and modification time.
+func (h *ADRsHandler) snapshot() string {
+	dir := h.server.GetADRPath()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return dir
+	}
+	var b strings.Builder
+	b.WriteString(dir)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s %d %d", entry.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String()
+}
+
+// parseADR reads an ADR in the Nygard or MADR format. The number comes from
+// the file name; title, status, date, deciders, scope and the context,
+// decision and consequences sections from the content, and relations to
+// other ADRs from the status section. Missing fields are left empty, and a
+// missing status counts as proposed.
+func parseADR(name, content string) (models.ADR, []models.ADRRelation) {
+	m := adrFileName.FindStringSubmatch(name)
+	number, _ := strconv.Atoi(m[1])
+	adr := models.ADR{
+		ID:      fmt.Sprintf("ADR-%03d", number),
+		Number:  number,
+		Content: content,
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	fields := make(map[string]string)
+
+	// MADR 3 and later keep the metadata in YAML front matter
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				lines = lines[i+1:]
+				break
+			}
+			if key, value, ok := strings.Cut(lines[i], ":"); ok {
+				fields[strings.ToLower(strings.TrimSpace(key))] = unquote(value)
+			}
+		}
+	}
+
+	sections := make(map[string][]string)
+	section := ""
+	fenced := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			fenced = !fenced
+		}
+		if !fenced {
+			if title, ok := strings.CutPrefix(trimmed, "# "); ok && adr.Title == "" {
+				adr.Title = adrTitlePrefix.ReplaceAllString(strings.TrimSpace(title), "")
+				continue
+			}
+			if heading, level := headingText(trimmed); level >= 2 {
+				if field, ok := adrSections[strings.ToLower(heading)]; ok {
+					section = field
+					continue
+				}
+				if level == 2 {
+					section = "other"
+					continue
+				}
+			}
+			if section == "" || section == "status" {
+				if m := adrField.FindStringSubmatch(trimmed); m != nil {
+					key := strings.ToLower(m[1])
+					if _, ok := fields[key]; !ok {
+						fields[key] = unquote(m[2])
+					}
+					continue
+				}
+			}
+		}
+		sections[section] = append(sections[section], line)
+	}
+
+	if adr.Title == "" {
+		adr.Title = strings.TrimSuffix(name[len(m[1])+1:], ".md")
+	}
+	adr.Context = sectionText(sections["context"])
+	adr.Decision = sectionText(sections["decision"])
+	adr.Consequences = sectionText(sections["consequences"])
+
+	// The status section holds the status and lines such as "Amends ADR-002"
+	var relations []models.ADRRelation
+	status := ""
+	for _, line := range sections["status"] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if r, ok := parseRelation(adr.ID, line); ok {
+			relations = append(relations, r)
+			if r.Kind == relationSupersedes && r.ToID == adr.ID && status == "" {
+				status = statusSuperseded
+			}
+			continue
+		}
+		if status == "" {
+			status = normalizeStatus(line)
+		}
+	}
+	if fields["status"] != "" {
+		status = normalizeStatus(fields["status"])
+	}
+	if status == "" {
+		status = statusProposed
+	}
+	adr.Status = status
+
+	if date, err := time.ParseInLocation("2006-01-02", firstWord(fields["date"]), time.Local); err == nil {
+		adr.Date = date
+	}
+
+	deciders := fields["deciders"]
+	if deciders == "" {
+		deciders = fields["decision-makers"]
+	}
+	adr.Deciders = strings.Join(splitList(deciders), ", ")
+	adr.Scope = strings.Join(splitList(fields["scope"]), ", ")
+
+	return adr, relations
+}
+
+// normalizeStatus reduces a status paragraph such as "**Accepted**" or
+// "Superseded by [ADR-005](0005-x.md)" to its lowercase first word.
+func normalizeStatus(text string) string {
+	word := strings.ToLower(strings.Trim(firstWord(text), "*_`[]().,:;"))
+	if word == "" {
+		return statusProposed
+	}
+	return word
+}
+
+func sectionText(lines []string) string {
+	text := strings.TrimSpace(strings.Join(lines, "\n"))
+	if text == sectionPlaceholder {
+		return ""
+	}
+	return text
+}
+
+// headingText returns the text and level of an ATX heading, or level 0.
+func headingText(line string) (string, int) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level >= len(line) || line[level] != ' ' {
+		return "", 0
+	}
+	return strings.TrimSpace(strings.TrimRight(line[level:], "#")), level
+}
+
+func firstWord(s string) string {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+func unquote(s string) string {
+	return strings.Trim(strings.TrimSpace(s), "\"'`")
+}
+
+// splitList splits a metadata value such as `Ann, Bob` or `["Ann", Bob]`.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(strings.Trim(strings.TrimSpace(value), "[]"), ",") {
+		if item = unquote(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func exists(known, parsed map[string]models.ADR, id string) bool {
+	_, inDB := known[id]
+	_, onDisk := parsed[id]
+	return inDB || onDisk
+}
+
+func sameADR(a, b models.ADR) bool {
+	return a.Number == b.Number && a.Title == b.Title && a.Status == b.Status && a.Date.Equal(b.Date) &&
+		a.Deciders == b.Deciders && a.Scope == b.Scope && a.Context == b.Context && a.Decision == b.Decision &&
+		a.Consequences == b.Consequences && a.FilePath == b.FilePath && a.Content == b.Content
+}
+
+// repoPath returns path relative to the repository root with forward
+// slashes, or unchanged if it lies outside of it.
+func repoPath(root, path string) string {
+	if r, err := filepath.Rel(root, path); err == nil && r != ".." && !strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(r)
+	}
+	return path
+}
+
+func absPath(root, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, filepath.FromSlash(path))
+}
+
+// inDir reports whether a stored file path is directly inside dir.
+func inDir(root, dir, path string) bool {
+	return filepath.Dir(absPath(root, path)) == filepath.Clean(dir)
+}
@@ -0,0 +1,209 @@
+package adrs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestParseADR(t *testing.T) {
+	tests := []struct {
+		name         string
+		file         string
+		content      string
+		wantID       string
+		wantTitle    string
+		wantStatus   string
+		wantDate     string
+		wantDeciders string
+		wantContext  string
+		wantDecision string
+		wantConseq   string
+	}{
+		{
+			name: "Nygard as written by adr-tools",
+			file: "0001-record-architecture-decisions.md",
+			content: "# 1. Record architecture decisions\n\nDate: 2024-05-06\n\n## Status\n\nAccepted\n\n" +
+				"## Context\n\nWe need to record decisions.\n\n## Decision\n\nWe will use ADRs.\n\n## Consequences\n\nSee Nygard.\n",
+			wantID:       "ADR-001",
+			wantTitle:    "Record architecture decisions",
+			wantStatus:   "accepted",
+			wantDate:     "2024-05-06",
+			wantContext:  "We need to record decisions.",
+			wantDecision: "We will use ADRs.",
+			wantConseq:   "See Nygard.",
+		},
+		{
+			name: "Nygard superseded",
+			file: "0012-use-mysql.md",
+			content: "# ADR-012: Use MySQL\n\n## Status\n\nSuperseded by [ADR-013](0013-use-postgresql.md)\n\n" +
+				"## Context\n\n```\n# not a title\n## Decision\n```\n",
+			wantID:      "ADR-012",
+			wantTitle:   "Use MySQL",
+			wantStatus:  "superseded",
+			wantContext: "```\n# not a title\n## Decision\n```",
+		},
+		{
+			name: "MADR 2 with a metadata list",
+			file: "0003-use-markdown.md",
+			content: "# Use Markdown Architectural Decision Records\n\n* Status: **Accepted**\n* Deciders: Ann, Bob\n* Date: 2021-02-03\n\n" +
+				"## Context and Problem Statement\n\nHow do we write ADRs?\n\n## Considered Options\n\n* MADR\n* Nygard\n\n" +
+				"## Decision Outcome\n\nChosen option: MADR.\n\n### Positive Consequences\n\n* Structured\n",
+			wantID:       "ADR-003",
+			wantTitle:    "Use Markdown Architectural Decision Records",
+			wantStatus:   "accepted",
+			wantDate:     "2021-02-03",
+			wantDeciders: "Ann, Bob",
+			wantContext:  "How do we write ADRs?",
+			wantDecision: "Chosen option: MADR.\n\n### Positive Consequences\n\n* Structured",
+		},
+		{
+			name: "MADR 3 front matter",
+			file: "0004-cache.md",
+			content: "---\nstatus: proposed\ndate: 2023-01-02\ndecision-makers: [\"Ann\", Cy]\n---\n" +
+				"# Cache responses\n\n## Context and Problem Statement\n\nToo slow.\n\n## Decision Outcome\n\nUse Redis.\n\n### Consequences\n\n* Good, faster\n",
+			wantID:       "ADR-004",
+			wantTitle:    "Cache responses",
+			wantStatus:   "proposed",
+			wantDate:     "2023-01-02",
+			wantDeciders: "Ann, Cy",
+			wantContext:  "Too slow.",
+			wantDecision: "Use Redis.",
+			wantConseq:   "* Good, faster",
+		},
+		{
+			name:       "No title or status",
+			file:       "0005-empty.md",
+			content:    "Nothing here yet.\n",
+			wantID:     "ADR-005",
+			wantTitle:  "empty",
+			wantStatus: "proposed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adr, _ := parseADR(tt.file, tt.content)
+
+			if adr.ID != tt.wantID || adr.Title != tt.wantTitle || adr.Status != tt.wantStatus || adr.Deciders != tt.wantDeciders {
+				t.Errorf("parseADR() = %s %q %q %q, want %s %q %q %q", adr.ID, adr.Title, adr.Status, adr.Deciders, tt.wantID, tt.wantTitle, tt.wantStatus, tt.wantDeciders)
+			}
+			if got := formatDate(adr.Date); got != tt.wantDate {
+				t.Errorf("parseADR() date = %q, want %q", got, tt.wantDate)
+			}
+			if adr.Context != tt.wantContext || adr.Decision != tt.wantDecision || adr.Consequences != tt.wantConseq {
+				t.Errorf("parseADR() sections = %q / %q / %q, want %q / %q / %q", adr.Context, adr.Decision, adr.Consequences, tt.wantContext, tt.wantDecision, tt.wantConseq)
+			}
+			if adr.Content != tt.content {
+				t.Errorf("parseADR() content not kept")
+			}
+		})
+	}
+}
+
+func TestADRsHandler_Sync(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewADRsHandler(srv)
+	ctx := context.Background()
+	dir := filepath.Join(tempDir, "docs", "adr")
+
+	// A missing directory is not an error
+	_, output, err := handler.ADRsSync(ctx, nil, types.ADRsSyncInput{})
+	if err != nil {
+		t.Fatalf("ADRsSync() without directory unexpected error: %v", err)
+	}
+	if output.Dir != "docs/adr" || output.Created != 0 {
+		t.Errorf("ADRsSync() = %+v, want nothing from docs/adr", output)
+	}
+
+	// An ADR written by adrs_create is already in sync
+	_, created, err := handler.ADRsCreate(ctx, nil, types.ADRsCreateInput{Title: "Use SQLite"})
+	if err != nil {
+		t.Fatalf("ADRsCreate() unexpected error: %v", err)
+	}
+	writeADRFile(t, dir, "0002-use-go.md", "# 2. Use Go\n\n## Status\n\nAccepted\n")
+	writeADRFile(t, dir, "0002-use-rust.md", "# 2. Use Rust\n")
+	writeADRFile(t, dir, "README.md", "# Decisions\n")
+	srv.GetDB().Create(&models.ADR{ID: "ADR-099", Number: 99, Title: "Only in the database"})
+
+	_, output, err = handler.ADRsSync(ctx, nil, types.ADRsSyncInput{})
+	if err != nil {
+		t.Fatalf("ADRsSync() unexpected error: %v", err)
+	}
+	if output.Created != 1 || output.Unchanged != 1 || len(output.Skipped) != 1 || output.Skipped[0].Path != "docs/adr/0002-use-rust.md" {
+		t.Errorf("ADRsSync() = %+v, want 1 created, 1 unchanged and the duplicate skipped", output)
+	}
+
+	// Editing and deleting files is picked up
+	writeADRFile(t, dir, "0002-use-go.md", "# 2. Use Go 1.25\n\n## Status\n\nDeprecated\n")
+	if err := os.Remove(filepath.Join(tempDir, created.Path)); err != nil {
+		t.Fatalf("Failed to remove %s: %v", created.Path, err)
+	}
+	_, output, err = handler.ADRsSync(ctx, nil, types.ADRsSyncInput{})
+	if err != nil {
+		t.Fatalf("ADRsSync() unexpected error: %v", err)
+	}
+	if output.Updated != 1 || output.Removed != 1 {
+		t.Errorf("ADRsSync() = %+v, want 1 updated and 1 removed", output)
+	}
+
+	_, list, err := handler.ADRsList(ctx, nil, types.ADRsListInput{})
+	if err != nil {
+		t.Fatalf("ADRsList() unexpected error: %v", err)
+	}
+	if len(list.ADRs) != 2 || list.ADRs[0].Title != "Use Go 1.25" || list.ADRs[0].Status != "deprecated" || list.ADRs[1].ID != "ADR-099" {
+		t.Errorf("ADRsList() = %+v, want ADR-002 updated and ADR-099 kept", list.ADRs)
+	}
+}
+
+func TestADRsHandler_Watch(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewADRsHandler(srv)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handler.Watch(ctx, 10*time.Millisecond)
+
+	writeADRFile(t, filepath.Join(tempDir, "docs", "adr"), "0001-use-go.md", "# 1. Use Go\n")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var adr models.ADR
+		if srv.GetDB().Where("id = ?", "ADR-001").Limit(1).Find(&adr); adr.Title == "Use Go" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Watch() did not pick up the new ADR file")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func writeADRFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", dir, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
@@ -0,0 +1,206 @@
+package ci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+// affectedScope is the scope that tests only the packages affected by the
+// changes in the working tree.
+const affectedScope = "affected"
+
+// listedPackage is the part of `go list -json` output the affected scope
+// needs.
+type listedPackage struct {
+	ImportPath   string
+	Dir          string
+	Standard     bool
+	DepOnly      bool
+	Imports      []string
+	TestImports  []string
+	XTestImports []string
+	Module       *struct {
+		Path string
+		Dir  string
+		Main bool
+	}
+}
+
+// affectedPackages finds the packages of the main module that the changes
+// since base, HEAD by default, may break: those with changed files, and
+// every package that imports one of them, directly or not. Packages whose
+// tests alone import an affected package are tested too, but their
+// importers are not.
+func affectedPackages(ctx context.Context, root, base string) (*types.CIAffected, error) {
+	if base == "" {
+		base = "HEAD"
+	}
+	if strings.HasPrefix(base, "-") {
+		return nil, fmt.Errorf("invalid base %q", base)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	// Both sides of renames, and untracked files, count as changed. Names
+	// are NUL-terminated, so that git neither quotes nor splits them.
+	diff, err := git(ctx, root, nil, "diff", "-z", "--name-only", "--no-renames", "--relative", base, "--", ".", stateExclude)
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s failed: %v", base, err)
+	}
+	untracked, err := git(ctx, root, nil, "ls-files", "-z", "--others", "--exclude-standard", "--", ".", stateExclude)
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed: %v", err)
+	}
+	affected := &types.CIAffected{Base: base, Changed: []string{}, Packages: []types.CIAffectedPackage{}}
+	for _, file := range strings.Split(diff+"\x00"+untracked, "\x00") {
+		if file != "" && !slices.Contains(affected.Changed, file) {
+			affected.Changed = append(affected.Changed, file)
+		}
+	}
+	slices.Sort(affected.Changed)
+	if len(affected.Changed) == 0 {
+		return affected, nil
+	}
+
+	packages, err := listPackages(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	byDir := make(map[string]string)
+	local := make(map[string]bool) // main module packages matched by ./...
+	var moduleDir, modulePath string
+	for _, p := range packages {
+		if p.Standard || p.Module == nil || !p.Module.Main {
+			continue
+		}
+		byDir[p.Dir] = p.ImportPath
+		moduleDir, modulePath = p.Module.Dir, p.Module.Path
+		if !p.DepOnly {
+			local[p.ImportPath] = true
+		}
+	}
+
+	reasons := make(map[string]string)
+	var queue []string
+	mark := func(pkg, reason string, spread bool) {
+		if _, ok := reasons[pkg]; ok {
+			return
+		}
+		reasons[pkg] = reason
+		if spread {
+			queue = append(queue, pkg)
+		}
+	}
+	changedFiles := make(map[string][]string)
+	for _, file := range affected.Changed {
+		abs := filepath.Join(root, filepath.FromSlash(file))
+		if filepath.Dir(abs) == moduleDir && (filepath.Base(file) == "go.mod" || filepath.Base(file) == "go.sum") {
+			for pkg := range local {
+				mark(pkg, filepath.Base(file)+" changed", true)
+			}
+			continue
+		}
+		if pkg := filePackage(abs, byDir, moduleDir, modulePath); pkg != "" {
+			changedFiles[pkg] = append(changedFiles[pkg], file)
+		}
+	}
+	for _, pkg := range slices.Sorted(maps.Keys(changedFiles)) {
+		mark(pkg, "changed: "+strings.Join(changedFiles[pkg], ", "), true)
+	}
+
+	// Walk the reverse import graph from the changed packages
+	importers := make(map[string][]string)
+	testImporters := make(map[string][]string)
+	for _, p := range packages {
+		if !local[p.ImportPath] {
+			continue
+		}
+		for _, imp := range p.Imports {
+			importers[imp] = append(importers[imp], p.ImportPath)
+		}
+		for _, imp := range slices.Concat(p.TestImports, p.XTestImports) {
+			testImporters[imp] = append(testImporters[imp], p.ImportPath)
+		}
+	}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		for _, importer := range importers[pkg] {
+			mark(importer, "imports "+pkg, true)
+		}
+		for _, importer := range testImporters[pkg] {
+			mark(importer, "tests import "+pkg, false)
+		}
+	}
+
+	for _, pkg := range slices.Sorted(maps.Keys(reasons)) {
+		if local[pkg] {
+			affected.Packages = append(affected.Packages, types.CIAffectedPackage{Package: pkg, Reason: reasons[pkg]})
+		}
+	}
+	return affected, nil
+}
+
+// filePackage returns the package a changed file belongs to. A Go file in
+// a directory that is not a package, because the package was removed or
+// no longer builds, still names the package its importers expect there;
+// other files, such as test data, belong to the closest package above
+// them.
+func filePackage(file string, byDir map[string]string, moduleDir, modulePath string) string {
+	dir := filepath.Dir(file)
+	if pkg, ok := byDir[dir]; ok {
+		return pkg
+	}
+	if rel, err := filepath.Rel(moduleDir, dir); err == nil && filepath.Ext(file) == ".go" && !strings.HasPrefix(rel, "..") {
+		if rel == "." {
+			return modulePath
+		}
+		return modulePath + "/" + filepath.ToSlash(rel)
+	}
+	for dir != filepath.Dir(dir) && strings.HasPrefix(dir, moduleDir) {
+		dir = filepath.Dir(dir)
+		if pkg, ok := byDir[dir]; ok {
+			return pkg
+		}
+	}
+	return ""
+}
+
+// listPackages runs `go list -e -deps -json ./...` in root, so that
+// packages with errors are listed too.
+func listPackages(ctx context.Context, root string) ([]listedPackage, error) {
+	cmd := exec.CommandContext(ctx, "go", "list", "-e", "-deps", "-json", "./...")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("go list failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("go list failed: %v", err)
+	}
+	var packages []listedPackage
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var p listedPackage
+		if err := decoder.Decode(&p); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read go list output: %v", err)
+		}
+		packages = append(packages, p)
+	}
+	return packages, nil
+}
@@ -0,0 +1,128 @@
+package ci
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestCIHandler_Affected(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewCIHandler(srv)
+	ctx := context.Background()
+
+	// c imports b, which imports a; only e's tests import a; d is apart
+	test := func(pkg string) string {
+		return "package " + pkg + "\n\nimport \"testing\"\n\nfunc TestOK(t *testing.T) {}\n"
+	}
+	writeModule(t, tempDir, map[string]string{
+		"a/a.go":      "package a\n\nfunc A() int { return 1 }\n",
+		"a/a_test.go": test("a"),
+		"b/b.go":      "package b\n\nimport \"example.com/m/a\"\n\nfunc B() int { return a.A() }\n",
+		"b/b_test.go": test("b"),
+		"c/c.go":      "package c\n\nimport \"example.com/m/b\"\n\nfunc C() int { return b.B() }\n",
+		"c/c_test.go": test("c"),
+		"d/d.go":      "package d\n\nfunc D() int { return 4 }\n",
+		"d/d_test.go": test("d"),
+		"e/e.go":      "package e\n\nfunc E() int { return 5 }\n",
+		"e/e_test.go": "package e_test\n\nimport (\n\t\"testing\"\n\n\t\"example.com/m/a\"\n)\n\nfunc TestA(t *testing.T) { a.A() }\n",
+	})
+	commit := func() {
+		t.Helper()
+		for _, args := range [][]string{{"add", "-A", "--", ".", stateExclude}, {"commit", "-q", "-m", "change"}} {
+			if _, err := git(ctx, tempDir, nil, append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...); err != nil {
+				t.Fatalf("git %v: %v", args, err)
+			}
+		}
+	}
+	if _, err := git(ctx, tempDir, nil, "init", "-q"); err != nil {
+		t.Fatalf("git init: %v", err)
+	}
+	commit()
+
+	scope := affectedScope
+	_, output, err := handler.CIRunTests(ctx, nil, types.CIRunTestsInput{Scope: &scope})
+	if err != nil {
+		t.Fatalf("CIRunTests() unexpected error: %v", err)
+	}
+	if output.RunID != 0 || output.Status != "pass" || output.Affected == nil || len(output.Affected.Changed) != 0 || len(output.Affected.Packages) != 0 {
+		t.Fatalf("CIRunTests() on a clean tree = %+v, want no run", output)
+	}
+	_, started, err := handler.CIStart(ctx, nil, types.CIStartInput{Scope: &scope})
+	if err != nil {
+		t.Fatalf("CIStart() unexpected error: %v", err)
+	}
+	if started.JobID == 0 || started.Status != "pass" {
+		t.Fatalf("CIStart() on a clean tree = %+v, want a finished job", started)
+	}
+	if _, status, err := handler.CIStatus(ctx, nil, types.CIStatusInput{JobID: started.JobID}); err != nil || !status.Done || status.Status != "pass" {
+		t.Errorf("CIStatus() of the empty job = %+v, %v, want it done and passed", status, err)
+	}
+	if _, _, err := handler.CIOutput(ctx, nil, types.CIOutputInput{JobID: started.JobID}); err != nil {
+		t.Errorf("CIOutput() of the empty job unexpected error: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(tempDir, "a", "a.go"), []byte("package a\n\nfunc A() int { return 2 }\n"), 0644)
+	want := []types.CIAffectedPackage{
+		{Package: "example.com/m/a", Reason: "changed: a/a.go"},
+		{Package: "example.com/m/b", Reason: "imports example.com/m/a"},
+		{Package: "example.com/m/c", Reason: "imports example.com/m/b"},
+		{Package: "example.com/m/e", Reason: "tests import example.com/m/a"},
+	}
+	_, output, err = handler.CIRunTests(ctx, nil, types.CIRunTestsInput{Scope: &scope})
+	if err != nil {
+		t.Fatalf("CIRunTests() unexpected error: %v", err)
+	}
+	if output.Status != "pass" || !reflect.DeepEqual(output.Affected.Packages, want) || !reflect.DeepEqual(output.Affected.Changed, []string{"a/a.go"}) {
+		t.Errorf("CIRunTests() = %s with %+v, want a, b, c and e", output.Status, output.Affected)
+	}
+	var tested []string
+	for _, p := range output.Packages {
+		tested = append(tested, p.Package)
+	}
+	if !reflect.DeepEqual(tested, []string{"example.com/m/a", "example.com/m/b", "example.com/m/c", "example.com/m/e"}) {
+		t.Errorf("CIRunTests() tested %v, want the affected packages", tested)
+	}
+	var ciRun models.CIRun
+	srv.GetDB().First(&ciRun, output.RunID)
+	if ciRun.Scope != affectedScope {
+		t.Errorf("run recorded with scope %q, want %q", ciRun.Scope, affectedScope)
+	}
+
+	// Against an older base, with test data of d left untracked, under a
+	// name git would otherwise quote
+	commit()
+	os.MkdirAll(filepath.Join(tempDir, "d", "testdata"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "d", "testdata", "ü x.txt"), []byte("x"), 0644)
+	_, start, err := handler.CIStart(ctx, nil, types.CIStartInput{Scope: &scope, Base: "HEAD~1"})
+	if err != nil {
+		t.Fatalf("CIStart() unexpected error: %v", err)
+	}
+	want = append(want, types.CIAffectedPackage{Package: "example.com/m/d", Reason: "changed: d/testdata/ü x.txt"})
+	if start.JobID == 0 || len(start.Affected.Packages) != 5 || start.Affected.Packages[3] != want[4] {
+		t.Errorf("CIStart() = %+v, want a job for a to e", start)
+	}
+	handler.job(start.JobID).wait(ctx)
+
+	if _, _, err := handler.CIRunTests(ctx, nil, types.CIRunTestsInput{Scope: &scope, Base: "--cached"}); err == nil {
+		t.Errorf("CIRunTests() with an option as base succeeded, want an error")
+	}
+}
@@ -0,0 +1,395 @@
+package ci
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+const (
+	// benchAlpha is the significance level, as in benchstat.
+	benchAlpha = 0.05
+	// defaultBenchThreshold is the regression threshold in percent.
+	defaultBenchThreshold = 5.0
+	maxBenchCount         = 50
+)
+
+var (
+	// benchLine matches a benchmark result such as
+	// "BenchmarkX/n=1-8   100   1.96 ns/op   0 B/op   0 allocs/op".
+	benchLine = regexp.MustCompile(`^(Benchmark\S+)\s+(\d+)\s+(.+)$`)
+	// benchProcs matches the GOMAXPROCS suffix of a benchmark name.
+	benchProcs = regexp.MustCompile(`-(\d+)$`)
+)
+
+// benchUnits are the metrics stored and compared; for all of them more is
+// worse.
+var benchUnits = []string{"ns/op", "B/op", "allocs/op"}
+
+// parseBenchmarks reads the benchmark results in a run's output.
+func parseBenchmarks(results []models.CITestResult) []models.CIBenchmark {
+	var benchmarks []models.CIBenchmark
+	for _, r := range results {
+		for _, line := range strings.Split(r.Output, "\n") {
+			m := benchLine.FindStringSubmatch(strings.TrimSpace(line))
+			if m == nil {
+				continue
+			}
+			b := models.CIBenchmark{Package: r.Package, Name: m[1]}
+			if p := benchProcs.FindStringSubmatch(b.Name); p != nil {
+				b.Name = strings.TrimSuffix(b.Name, p[0])
+				b.Procs, _ = strconv.Atoi(p[1])
+			}
+			b.Iterations, _ = strconv.ParseInt(m[2], 10, 64)
+			fields := strings.Fields(m[3])
+			for i := 0; i+1 < len(fields); i += 2 {
+				value, err := strconv.ParseFloat(fields[i], 64)
+				if err != nil {
+					continue
+				}
+				switch fields[i+1] {
+				case "ns/op":
+					b.NsPerOp = value
+				case "B/op":
+					b.BytesPerOp = value
+				case "allocs/op":
+					b.AllocsPerOp = value
+				}
+			}
+			benchmarks = append(benchmarks, b)
+		}
+	}
+	return benchmarks
+}
+
+// CIBench runs benchmarks with -benchmem, stores every sample keyed by
+// benchmark and commit, and compares the medians against a baseline run
+// with a Mann-Whitney U test, as benchstat does. A change is a regression
+// when it is significant and worse than the threshold.
+func (h *CIHandler) CIBench(ctx context.Context, req *mcp.CallToolRequest, input types.CIBenchInput) (*mcp.CallToolResult, types.CIBenchOutput, error) {
+	threshold := defaultBenchThreshold
+	if input.Threshold != nil {
+		if *input.Threshold < 0 {
+			return nil, types.CIBenchOutput{}, fmt.Errorf("threshold cannot be negative")
+		}
+		threshold = *input.Threshold
+	}
+
+	db := h.server.GetDB()
+	var ciRun models.CIRun
+	if input.RunID != 0 {
+		result := db.Where("id = ? AND id IN (?)", input.RunID, db.Model(&models.CIBenchmark{}).Select("run_id")).Limit(1).Find(&ciRun)
+		if result.Error != nil {
+			return nil, types.CIBenchOutput{}, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, types.CIBenchOutput{}, fmt.Errorf("run %d has no benchmarks", input.RunID)
+		}
+	} else {
+		run, err := h.runBenchmarks(ctx, input)
+		if err != nil {
+			return nil, types.CIBenchOutput{}, err
+		}
+		ciRun = run
+	}
+
+	var baseline models.CIRun
+	query := db.Where("id IN (?)", db.Model(&models.CIBenchmark{}).Select("run_id"))
+	switch {
+	case input.Baseline != 0:
+		query = query.Where("id = ?", input.Baseline)
+	case input.BaselineCommit != "":
+		query = query.Where("`commit` LIKE ?", input.BaselineCommit+"%")
+	default:
+		query = query.Where("scope = ? AND id <> ? AND started_at <= ?", ciRun.Scope, ciRun.ID, ciRun.StartedAt)
+	}
+	result := query.Order("started_at DESC, id DESC").Limit(1).Find(&baseline)
+	if result.Error != nil {
+		return nil, types.CIBenchOutput{}, result.Error
+	}
+	if result.RowsAffected == 0 && (input.Baseline != 0 || input.BaselineCommit != "") {
+		return nil, types.CIBenchOutput{}, fmt.Errorf("no benchmark run found for the baseline")
+	}
+
+	output := types.CIBenchOutput{
+		RunID:       ciRun.ID,
+		Status:      ciRun.Status,
+		Commit:      ciRun.Commit,
+		Threshold:   threshold,
+		Benchmarks:  []types.CIBenchResult{},
+		Regressions: []types.CIBenchRegression{},
+	}
+	if ciRun.Status != "pass" {
+		output.Output = ciRun.Output
+	}
+	if baseline.ID != 0 {
+		output.BaselineRunID = baseline.ID
+		output.BaselineCommit = baseline.Commit
+	}
+
+	current, err := h.loadBenchmarks(ciRun.ID)
+	if err != nil {
+		return nil, types.CIBenchOutput{}, err
+	}
+	previous, err := h.loadBenchmarks(baseline.ID)
+	if err != nil {
+		return nil, types.CIBenchOutput{}, err
+	}
+
+	for _, key := range slices.SortedFunc(maps.Keys(current), compareBenchKeys) {
+		samples := current[key]
+		result := types.CIBenchResult{Package: key[0], Name: key[1], Samples: len(samples), Metrics: []types.CIBenchMetric{}}
+		old := previous[key]
+		for _, unit := range benchUnits {
+			values := benchValues(samples, unit)
+			metric := types.CIBenchMetric{Unit: unit, Median: median(values), Spread: spread(values)}
+			if len(old) > 0 {
+				before := benchValues(old, unit)
+				metric.Baseline = ptr(median(before))
+				p := mannWhitney(before, values)
+				metric.P = ptr(math.Round(p*1000) / 1000)
+				metric.Significant = p < benchAlpha
+				if *metric.Baseline != 0 {
+					metric.Delta = ptr(roundPercent((metric.Median - *metric.Baseline) / *metric.Baseline * 100))
+				}
+				if metric.Significant && metric.Delta != nil && *metric.Delta > threshold {
+					metric.Regression = true
+					output.Regressions = append(output.Regressions, types.CIBenchRegression{
+						Package: key[0], Name: key[1], Unit: unit, Delta: *metric.Delta, P: *metric.P,
+					})
+				}
+			}
+			result.Metrics = append(result.Metrics, metric)
+		}
+		output.Benchmarks = append(output.Benchmarks, result)
+	}
+	return nil, output, nil
+}
+
+// runBenchmarks runs go test -bench as a job, waits for it and stores the
+// samples with the run.
+func (h *CIHandler) runBenchmarks(ctx context.Context, input types.CIBenchInput) (models.CIRun, error) {
+	scope := defaultScope
+	if input.Scope != nil && *input.Scope != "" {
+		scope = *input.Scope
+	}
+	timeout, err := h.timeout(input.Timeout)
+	if err != nil {
+		return models.CIRun{}, err
+	}
+	bench := input.Bench
+	if bench == "" {
+		bench = "."
+	}
+	count := input.Count
+	if count <= 0 {
+		count = 6
+	}
+	if count > maxBenchCount {
+		return models.CIRun{}, fmt.Errorf("count %d is above the maximum of %d", count, maxBenchCount)
+	}
+	args := []string{"-bench", bench, "-benchmem", "-count", strconv.Itoa(count)}
+	if input.Benchtime != "" {
+		args = append(args, "-benchtime", input.Benchtime)
+	}
+
+	j, err := h.startJob(jobSpec{scope: scope, run: "^$", timeout: timeout, args: args})
+	if err != nil {
+		return models.CIRun{}, err
+	}
+	j.wait(ctx)
+	ciRun, err := h.findRun(j.runID)
+	if err != nil {
+		return models.CIRun{}, err
+	}
+
+	benchmarks := parseBenchmarks(ciRun.Results)
+	for i := range benchmarks {
+		benchmarks[i].RunID, benchmarks[i].Commit = ciRun.ID, ciRun.Commit
+	}
+	if len(benchmarks) == 0 {
+		if ciRun.Status == "pass" {
+			return models.CIRun{}, fmt.Errorf("no benchmarks matched %q in %s", bench, scope)
+		}
+		return models.CIRun{}, fmt.Errorf("benchmark run %d ended with status %s:\n%s", ciRun.ID, ciRun.Status, ciRun.Output)
+	}
+	if err := h.server.GetDB().CreateInBatches(benchmarks, 200).Error; err != nil {
+		return models.CIRun{}, err
+	}
+	return ciRun, nil
+}
+
+// loadBenchmarks returns a run's samples by package and benchmark name.
+func (h *CIHandler) loadBenchmarks(runID uint) (map[[2]string][]models.CIBenchmark, error) {
+	samples := make(map[[2]string][]models.CIBenchmark)
+	if runID == 0 {
+		return samples, nil
+	}
+	var rows []models.CIBenchmark
+	if err := h.server.GetDB().Where("run_id = ?", runID).Order("id ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		key := [2]string{r.Package, r.Name}
+		samples[key] = append(samples[key], r)
+	}
+	return samples, nil
+}
+
+func compareBenchKeys(a, b [2]string) int {
+	return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+}
+
+func benchValues(samples []models.CIBenchmark, unit string) []float64 {
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		switch unit {
+		case "ns/op":
+			values[i] = s.NsPerOp
+		case "B/op":
+			values[i] = s.BytesPerOp
+		case "allocs/op":
+			values[i] = s.AllocsPerOp
+		}
+	}
+	return values
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Sorted(slices.Values(values))
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// spread returns the largest deviation from the median as a percentage of
+// it, the ± benchstat prints.
+func spread(values []float64) float64 {
+	m := median(values)
+	if m == 0 {
+		return 0
+	}
+	largest := 0.0
+	for _, v := range values {
+		largest = max(largest, math.Abs(v-m))
+	}
+	return roundPercent(largest / m * 100)
+}
+
+// mannWhitney returns the two-sided p-value of the Mann-Whitney U test for
+// the samples x and y. Small samples without ties use the exact
+// distribution of U; others the normal approximation with a tie
+// correction.
+func mannWhitney(x, y []float64) float64 {
+	n, m := len(x), len(y)
+	if n == 0 || m == 0 {
+		return 1
+	}
+
+	// Rank the pooled samples, giving ties their average rank
+	type sample struct {
+		value float64
+		fromX bool
+	}
+	pooled := make([]sample, 0, n+m)
+	for _, v := range x {
+		pooled = append(pooled, sample{v, true})
+	}
+	for _, v := range y {
+		pooled = append(pooled, sample{v, false})
+	}
+	slices.SortFunc(pooled, func(a, b sample) int { return cmp.Compare(a.value, b.value) })
+	rankSumX := 0.0
+	ties := false
+	tieTerm := 0.0 // sum of t³ - t over groups of t tied values
+	for i := 0; i < len(pooled); {
+		j := i
+		for j+1 < len(pooled) && pooled[j+1].value == pooled[i].value {
+			j++
+		}
+		rank := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			if pooled[k].fromX {
+				rankSumX += rank
+			}
+		}
+		if t := float64(j - i + 1); t > 1 {
+			ties = true
+			tieTerm += t*t*t - t
+		}
+		i = j + 1
+	}
+	u := rankSumX - float64(n*(n+1))/2
+
+	if !ties && n*m <= 400 {
+		// P(U <= u) and P(U >= u) from the exact distribution
+		counts := uDistribution(n, m)
+		total, below, above := 0.0, 0.0, 0.0
+		for k, c := range counts {
+			total += c
+			if float64(k) <= u {
+				below += c
+			}
+			if float64(k) >= u {
+				above += c
+			}
+		}
+		return math.Min(1, 2*math.Min(below, above)/total)
+	}
+
+	mean := float64(n*m) / 2
+	N := float64(n + m)
+	variance := float64(n*m) / 12 * ((N + 1) - tieTerm/(N*(N-1)))
+	if variance == 0 {
+		return 1
+	}
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		return 1
+	}
+	return math.Min(1, math.Erfc(z/math.Sqrt2))
+}
+
+// uDistribution returns how many arrangements of n and m samples give each
+// value of U, using the recurrence f(n, m, u) = f(n-1, m, u-m) + f(n, m-1, u).
+func uDistribution(n, m int) []float64 {
+	memo := make(map[[2]int][]float64)
+	var f func(n, m int) []float64
+	f = func(n, m int) []float64 {
+		if n == 0 || m == 0 {
+			return []float64{1}
+		}
+		if d, ok := memo[[2]int{n, m}]; ok {
+			return d
+		}
+		d := make([]float64, n*m+1)
+		for u, c := range f(n-1, m) {
+			d[u+m] += c
+		}
+		for u, c := range f(n, m-1) {
+			d[u] += c
+		}
+		memo[[2]int{n, m}] = d
+		return d
+	}
+	return f(n, m)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,165 @@
+package ci
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestCIHandler_Bench(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewCIHandler(srv)
+	ctx := context.Background()
+
+	writeModule(t, tempDir, map[string]string{
+		"a/a.go": "package a\n\nfunc Sum(n int) []int {\n\ts := make([]int, n)\n\tfor i := range s {\n\t\ts[i] = i\n\t}\n\treturn s\n}\n",
+		"a/a_test.go": "package a\n\nimport \"testing\"\n\n" +
+			"func TestSum(t *testing.T) {\n\tt.Fatal(\"not run\")\n}\n\n" +
+			"var sink []int\n\nfunc BenchmarkSum(b *testing.B) {\n\tfor i := 0; i < b.N; i++ {\n\t\tsink = Sum(64)\n\t}\n}\n",
+	})
+
+	scope := "./..."
+	_, output, err := handler.CIBench(ctx, nil, types.CIBenchInput{Scope: &scope, Count: 2, Benchtime: "100x"})
+	if err != nil {
+		t.Fatalf("CIBench() unexpected error: %v", err)
+	}
+	if output.Status != "pass" || output.BaselineRunID != 0 || len(output.Benchmarks) != 1 {
+		t.Fatalf("CIBench() = %+v, want one benchmark passing without a baseline", output)
+	}
+	sum := output.Benchmarks[0]
+	if sum.Package != "example.com/m/a" || sum.Name != "BenchmarkSum" || sum.Samples != 2 || len(sum.Metrics) != 3 {
+		t.Errorf("CIBench() benchmark = %+v, want BenchmarkSum with 2 samples of 3 metrics", sum)
+	}
+	if m := sum.Metrics[2]; m.Unit != "allocs/op" || m.Median != 1 || m.Baseline != nil {
+		t.Errorf("CIBench() allocs = %+v, want 1 allocation without a baseline", m)
+	}
+
+	// The next run compares with the first
+	_, second, err := handler.CIBench(ctx, nil, types.CIBenchInput{Scope: &scope, Count: 2, Benchtime: "100x"})
+	if err != nil {
+		t.Fatalf("CIBench() unexpected error: %v", err)
+	}
+	if second.BaselineRunID != output.RunID || second.Benchmarks[0].Metrics[2].Delta == nil || *second.Benchmarks[0].Metrics[2].Delta != 0 {
+		t.Errorf("CIBench() = %+v, want run %d as the baseline with unchanged allocations", second, output.RunID)
+	}
+
+	if _, _, err := handler.CIBench(ctx, nil, types.CIBenchInput{Scope: &scope, Bench: "Missing", Benchtime: "1x"}); err == nil {
+		t.Errorf("CIBench() without matching benchmarks succeeded, want an error")
+	}
+}
+
+func TestCIHandler_BenchCompare(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewCIHandler(srv)
+	ctx := context.Background()
+
+	// BenchmarkA gets 10% slower, BenchmarkB stays within its noise
+	start := time.Now().Add(-time.Hour)
+	runs := []struct {
+		commit string
+		a, b   []float64
+	}{
+		{"aaaa1111", []float64{100, 101, 99, 100, 102, 98}, []float64{50, 55, 45, 52, 48, 50}},
+		{"bbbb2222", []float64{110, 111, 109, 110, 112, 108}, []float64{51, 46, 54, 49, 53, 47}},
+	}
+	var ids []uint
+	for i, r := range runs {
+		run := models.CIRun{Scope: "./...", Run: "^$", Commit: r.commit, Status: "pass", StartedAt: start.Add(time.Duration(i) * time.Minute)}
+		if err := srv.GetDB().Create(&run).Error; err != nil {
+			t.Fatalf("Failed to create run: %v", err)
+		}
+		ids = append(ids, run.ID)
+		for name, samples := range map[string][]float64{"BenchmarkA": r.a, "BenchmarkB": r.b} {
+			for _, ns := range samples {
+				b := models.CIBenchmark{RunID: run.ID, Package: "example.com/m/a", Name: name, Commit: r.commit, Procs: 8, Iterations: 1000, NsPerOp: ns, AllocsPerOp: 2}
+				if err := srv.GetDB().Create(&b).Error; err != nil {
+					t.Fatalf("Failed to create benchmark: %v", err)
+				}
+			}
+		}
+	}
+
+	_, output, err := handler.CIBench(ctx, nil, types.CIBenchInput{RunID: ids[1]})
+	if err != nil {
+		t.Fatalf("CIBench() unexpected error: %v", err)
+	}
+	if output.BaselineRunID != ids[0] || output.BaselineCommit != "aaaa1111" || output.Threshold != 5 {
+		t.Errorf("CIBench() = %+v, want run %d as the baseline at a 5%% threshold", output, ids[0])
+	}
+	want := []types.CIBenchRegression{{Package: "example.com/m/a", Name: "BenchmarkA", Unit: "ns/op", Delta: 10, P: 0.005}}
+	if !reflect.DeepEqual(output.Regressions, want) {
+		t.Errorf("CIBench() regressions = %+v, want %+v", output.Regressions, want)
+	}
+	if m := output.Benchmarks[1].Metrics[0]; m.Significant || m.Regression || m.Spread != 8 {
+		t.Errorf("CIBench() BenchmarkB ns/op = %+v, want an insignificant change with 8%% spread", m)
+	}
+
+	// Above the threshold nothing regresses
+	threshold := 15.0
+	_, output, err = handler.CIBench(ctx, nil, types.CIBenchInput{RunID: ids[1], BaselineCommit: "aaaa", Threshold: &threshold})
+	if err != nil || len(output.Regressions) != 0 {
+		t.Errorf("CIBench() at 15%% = %+v, %v, want no regressions", output.Regressions, err)
+	}
+
+	if _, _, err := handler.CIBench(ctx, nil, types.CIBenchInput{RunID: ids[1], BaselineCommit: "cccc"}); err == nil {
+		t.Errorf("CIBench() with an unknown baseline commit succeeded, want an error")
+	}
+	if _, _, err := handler.CIBench(ctx, nil, types.CIBenchInput{RunID: ids[1] + 1}); err == nil {
+		t.Errorf("CIBench() with a run without benchmarks succeeded, want an error")
+	}
+}
+
+func TestParseBenchmarks(t *testing.T) {
+	results := []models.CITestResult{
+		{Package: "example.com/m/a", Test: "BenchmarkX/n=1", Output: "BenchmarkX/n=1-8   \t"},
+		{Package: "example.com/m/a", Output: "goos: linux\n   1000\t      1234 ns/op\t      64 B/op\t       1 allocs/op\n" +
+			"BenchmarkY-4\t500\t2.5 ns/op\t0 B/op\t0 allocs/op\nPASS\n"},
+	}
+	want := []models.CIBenchmark{
+		{Package: "example.com/m/a", Name: "BenchmarkY", Procs: 4, Iterations: 500, NsPerOp: 2.5},
+	}
+	if got := parseBenchmarks(results); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBenchmarks() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMannWhitney(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y []float64
+		want float64
+	}{
+		{"identical", []float64{1, 1, 1}, []float64{1, 1, 1}, 1},
+		{"separated", []float64{1, 2, 3, 4, 5, 6}, []float64{7, 8, 9, 10, 11, 12}, 0.002},
+		{"interleaved", []float64{1, 3, 5, 7}, []float64{2, 4, 6, 8}, 0.686},
+		{"ties", []float64{1, 1, 2, 2, 3, 3}, []float64{4, 4, 5, 5, 6, 6}, 0.005},
+		{"empty", nil, []float64{1}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := math.Round(mannWhitney(tt.x, tt.y)*1000) / 1000; got != tt.want {
+				t.Errorf("mannWhitney() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,9 @@ package ci
 
 import (
 	"context"
-	"os/exec"
-	"time"
+	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/thornzero/project-manager/internal/models"
@@ -11,63 +12,117 @@ import (
 	"github.com/thornzero/project-manager/internal/types"
 )
 
+// defaultScope is the package pattern tested when no scope is given.
+const defaultScope = "./internal/..."
+
 type CIHandler struct {
 	server *server.Server
+
+	mu   sync.Mutex
+	jobs map[uint]*job // background runs by run ID
 }
 
 func NewCIHandler(s *server.Server) *CIHandler {
-	return &CIHandler{server: s}
+	// Runs still marked as running were cut short when the server stopped
+	s.GetDB().Model(&models.CIRun{}).Where("status = ?", "running").Updates(map[string]interface{}{
+		"status": "error", "output": "the server stopped before the run finished",
+	})
+	return &CIHandler{server: s, jobs: make(map[uint]*job)}
 }
 
+// CIRunTests runs go test with -json, or the project's runner, waits for
+// it and records the outcome of every package and test with the run. The
+// run is cancelled if the request is.
 func (h *CIHandler) CIRunTests(ctx context.Context, req *mcp.CallToolRequest, input types.CIRunTestsInput) (*mcp.CallToolResult, types.CIRunTestsOutput, error) {
-	scope := "./internal/..."
-	if input.Scope != nil && *input.Scope != "" {
-		scope = *input.Scope
+	spec, affected, err := h.testSpec(ctx, input.Runner, input.Scope, input.Base, input.Timeout, input.Coverage)
+	if err != nil {
+		return nil, types.CIRunTestsOutput{}, err
+	}
+	if affected != nil && len(affected.Packages) == 0 {
+		return nil, types.CIRunTestsOutput{
+			Status:   "pass",
+			Runner:   spec.runner,
+			Packages: []types.CIPackageResult{},
+			Failures: []types.CITestFailure{},
+			Affected: affected,
+		}, nil
 	}
 
-	start := time.Now()
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "go", "test", scope, "-count=1")
-	cmd.Dir = h.server.GetRepoRoot()
-	output, err := cmd.CombinedOutput()
+	j, err := h.startJob(spec)
+	if err != nil {
+		return nil, types.CIRunTestsOutput{}, err
+	}
+	j.wait(ctx)
 
-	status := "pass"
+	ciRun, err := h.findRun(j.runID)
 	if err != nil {
-		status = "fail"
+		return nil, types.CIRunTestsOutput{}, err
+	}
+	output := types.CIRunTestsOutput{
+		RunID:    ciRun.ID,
+		Status:   ciRun.Status,
+		Runner:   ciRun.Runner,
+		Packages: []types.CIPackageResult{},
+		Failures: []types.CITestFailure{},
+		Output:   ciRun.Output,
+		Coverage: ciRun.Coverage,
+		Affected: affected,
 	}
+	output.Passed, output.Failed, output.Skipped = testSummary(ciRun.Results)
 
-	// Log to database
-	ciRun := models.CIRun{
-		Scope:      scope,
-		Status:     status,
-		StartedAt:  start,
-		FinishedAt: &[]time.Time{time.Now()}[0],
-	}
-	h.server.GetDB().Create(&ciRun)
+	for _, r := range ciRun.Results {
+		if r.Test == "" {
+			output.Packages = append(output.Packages, types.CIPackageResult{Package: r.Package, Status: r.Status, Elapsed: r.Elapsed})
+		}
+	}
+	for _, r := range failedResults(ciRun.Results) {
+		output.Failures = append(output.Failures, types.CITestFailure{Package: r.Package, Test: r.Test, Elapsed: r.Elapsed, Output: r.Output})
+	}
 
-	return nil, types.CIRunTestsOutput{
-		Status: status,
-		Output: string(output),
-	}, nil
+	return nil, output, nil
 }
 
-func (h *CIHandler) CILastFailure(ctx context.Context, req *mcp.CallToolRequest, input types.CILastFailureInput) (*mcp.CallToolResult, types.CILastFailureOutput, error) {
-	var ciRun models.CIRun
-	err := h.server.GetDB().
-		Where("status = ?", "fail").
-		Order("started_at DESC").
-		First(&ciRun).Error
-
+// testSpec describes a run of the tests asked for by ci_run_tests or
+// ci_start. Only go test has a default scope; other runners run all their
+// tests. With the affected scope it also returns the packages selected,
+// which may be none.
+func (h *CIHandler) testSpec(ctx context.Context, runner string, scope *string, base string, timeout *string, coverage bool) (jobSpec, *types.CIAffected, error) {
+	runner, err := h.testRunner(runner)
 	if err != nil {
-		return nil, types.CILastFailureOutput{Status: "none"}, nil
+		return jobSpec{}, nil, err
+	}
+	spec := jobSpec{runner: runner, coverage: coverage}
+	if runner == "go" {
+		spec.scope = defaultScope
+	} else if coverage {
+		return jobSpec{}, nil, fmt.Errorf("coverage is only collected by go test, not %s", runner)
+	}
+	if scope != nil && *scope != "" {
+		spec.scope = *scope
+	}
+	// Scopes are passed as arguments, so none may be taken for a flag
+	for _, arg := range strings.Fields(spec.scope) {
+		if strings.HasPrefix(arg, "-") {
+			return jobSpec{}, nil, fmt.Errorf("invalid scope %q", arg)
+		}
+	}
+	spec.timeout, err = h.timeout(timeout)
+	if err != nil {
+		return jobSpec{}, nil, err
+	}
+	if spec.scope != affectedScope {
+		return spec, nil, nil
 	}
 
-	startedAt := ciRun.StartedAt.Format("2006-01-02 15:04:05")
-	return nil, types.CILastFailureOutput{
-		Status:    ciRun.Status,
-		Scope:     &ciRun.Scope,
-		StartedAt: &startedAt,
-	}, nil
+	if runner != "go" {
+		return jobSpec{}, nil, fmt.Errorf("the %s scope is only supported by go test, not %s", affectedScope, runner)
+	}
+	affected, err := affectedPackages(ctx, h.server.GetRepoRoot(), base)
+	if err != nil {
+		return jobSpec{}, nil, err
+	}
+	for _, p := range affected.Packages {
+		spec.packages = append(spec.packages, p.Package)
+	}
+	return spec, affected, nil
 }
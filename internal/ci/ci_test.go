@@ -0,0 +1,160 @@
+package ci
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+// writeModule writes a throwaway Go module with the given files.
+func writeModule(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	files["go.mod"] = "module example.com/m\n\ngo 1.21\n"
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestCIHandler_RunTests(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewCIHandler(srv)
+	ctx := context.Background()
+
+	writeModule(t, tempDir, map[string]string{
+		"a/a_test.go": "package a\n\nimport \"testing\"\n\nfunc TestOK(t *testing.T) {}\n\n" +
+			"func TestBad(t *testing.T) { t.Fatal(\"boom\") }\n\nfunc TestLater(t *testing.T) { t.Skip(\"later\") }\n",
+		"b/b.go":      "package b\n\nfunc X() int { return \"x\" }\n",
+		"b/b_test.go": "package b\n\nimport \"testing\"\n\nfunc TestB(t *testing.T) {}\n",
+		"c/c.go":      "package c\n",
+	})
+
+	scope := "./..."
+	_, output, err := handler.CIRunTests(ctx, nil, types.CIRunTestsInput{Scope: &scope})
+	if err != nil {
+		t.Fatalf("CIRunTests() unexpected error: %v", err)
+	}
+	if output.Status != "fail" || output.Passed != 1 || output.Failed != 1 || output.Skipped != 1 {
+		t.Errorf("CIRunTests() = %s %d/%d/%d, want fail 1/1/1", output.Status, output.Passed, output.Failed, output.Skipped)
+	}
+	if len(output.Packages) != 3 || output.Packages[2].Package != "example.com/m/c" || output.Packages[2].Status != "skip" {
+		t.Errorf("CIRunTests() packages = %+v, want a, b and c skipped", output.Packages)
+	}
+	if len(output.Failures) != 2 || output.Failures[0].Test != "TestBad" || output.Failures[1].Package != "example.com/m/b" || output.Failures[1].Test != "" {
+		t.Fatalf("CIRunTests() failures = %+v, want TestBad and the build failure of b", output.Failures)
+	}
+
+	var results []models.CITestResult
+	srv.GetDB().Where("run_id = ?", output.RunID).Find(&results)
+	if len(results) != 6 {
+		t.Errorf("stored %d results for run %d, want 6", len(results), output.RunID)
+	}
+}
+
+func TestCIHandler_LastFailure(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewCIHandler(srv)
+	ctx := context.Background()
+
+	_, output, err := handler.CILastFailure(ctx, nil, types.CILastFailureInput{})
+	if err != nil {
+		t.Fatalf("CILastFailure() unexpected error: %v", err)
+	}
+	if output.Status != "none" || len(output.Failures) != 0 {
+		t.Errorf("CILastFailure() without runs = %+v, want none", output)
+	}
+
+	writeModule(t, tempDir, map[string]string{
+		"a/a.go": "package a\n\nfunc Index(s []int) int {\n\treturn s[3]\n}\n",
+		"a/a_test.go": "package a\n\nimport \"testing\"\n\nfunc TestEqual(t *testing.T) {\n\tif 1+1 != 3 {\n" +
+			"\t\tt.Errorf(\"1+1 = %d\", 1+1)\n\t}\n}\n\nfunc TestPanic(t *testing.T) {\n\tIndex(nil)\n}\n",
+		"b/b.go":      "package b\n\nfunc X() int { return \"x\" }\n",
+		"b/b_test.go": "package b\n",
+	})
+
+	scope := "./..."
+	if _, _, err := handler.CIRunTests(ctx, nil, types.CIRunTestsInput{Scope: &scope}); err != nil {
+		t.Fatalf("CIRunTests() unexpected error: %v", err)
+	}
+	around := 1
+	_, output, err = handler.CILastFailure(ctx, nil, types.CILastFailureInput{Context: &around})
+	if err != nil {
+		t.Fatalf("CILastFailure() unexpected error: %v", err)
+	}
+	if output.Status != "fail" || !output.StillFailing || len(output.Failures) != 3 {
+		t.Fatalf("CILastFailure() = %+v, want 3 failures still failing", output)
+	}
+
+	tests := []struct {
+		test     string
+		excerpt  string
+		location string
+		snippet  string
+	}{
+		{test: "TestEqual", excerpt: "    a_test.go:7: 1+1 = 2", location: "a/a_test.go:7", snippet: "  6 | \tif 1+1 != 3 {\n>    7 | \t\tt.Errorf(\"1+1 = %d\", 1+1)\n     8 | \t}\n"},
+		{test: "TestPanic", excerpt: "panic: runtime error: index out of range", location: "a/a.go:4", snippet: ">    4 | \treturn s[3]\n"},
+		{test: "", excerpt: "cannot use \"x\"", location: "b/b.go:3", snippet: ">    3 | func X() int { return \"x\" }\n"},
+	}
+	for i, tt := range tests {
+		f := output.Failures[i]
+		if f.Test != tt.test || !strings.Contains(f.Excerpt, tt.excerpt) || strings.Contains(f.Excerpt, "=== RUN") {
+			t.Errorf("failure %d = %s %q, want %s containing %q", i, f.Test, f.Excerpt, tt.test, tt.excerpt)
+		}
+		if len(f.Locations) == 0 {
+			t.Errorf("failure %d has no locations", i)
+			continue
+		}
+		loc := f.Locations[0]
+		if got := loc.File + ":" + strconv.Itoa(loc.Line); got != tt.location || !strings.Contains(loc.Snippet, tt.snippet) {
+			t.Errorf("failure %d location = %s\n%s\nwant %s containing\n%s", i, got, loc.Snippet, tt.location, tt.snippet)
+		}
+	}
+
+	// Once fixed, the failure is no longer present in the latest run
+	writeModule(t, tempDir, map[string]string{
+		"a/a.go":      "package a\n",
+		"a/a_test.go": "package a\n\nimport \"testing\"\n\nfunc TestEqual(t *testing.T) {}\n",
+		"b/b.go":      "package b\n",
+		"b/b_test.go": "package b\n",
+	})
+	if _, _, err := handler.CIRunTests(ctx, nil, types.CIRunTestsInput{Scope: &scope}); err != nil {
+		t.Fatalf("CIRunTests() unexpected error: %v", err)
+	}
+	_, fixed, err := handler.CILastFailure(ctx, nil, types.CILastFailureInput{Scope: &scope})
+	if err != nil {
+		t.Fatalf("CILastFailure() unexpected error: %v", err)
+	}
+	if fixed.RunID != output.RunID || fixed.LatestStatus != "pass" || fixed.StillFailing || fixed.Failures[0].StillFailing {
+		t.Errorf("CILastFailure() after fix = run %d latest %s still failing %v, want run %d, pass and not still failing", fixed.RunID, fixed.LatestStatus, fixed.StillFailing, output.RunID)
+	}
+}
@@ -0,0 +1,456 @@
+package ci
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"maps"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
+)
+
+var (
+	// profileLine matches a block of a cover profile:
+	// "example.com/m/a/a.go:3.24,5.2 1 1".
+	profileLine = regexp.MustCompile(`^(.+):(\d+)\.(\d+),(\d+)\.(\d+) (\d+) (\d+)$`)
+	// hunkHeader matches the new-file range of a unified diff hunk.
+	hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+)
+
+// profileBlock is a block of statements in a cover profile.
+type profileBlock struct {
+	file                string // import path of the package and file name
+	startLine, startCol int
+	endLine, endCol     int
+	statements, count   int
+}
+
+// parseProfile reads a cover profile. Blocks profiled more than once, as
+// happens when several test binaries cover the same package, are merged.
+func parseProfile(file string) ([]profileBlock, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var blocks []profileBlock
+	index := make(map[profileBlock]int)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m := profileLine.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue // the mode line
+		}
+		n := make([]int, 6)
+		for i := range n {
+			n[i], _ = strconv.Atoi(m[i+2])
+		}
+		b := profileBlock{file: m[1], startLine: n[0], startCol: n[1], endLine: n[2], endCol: n[3], statements: n[4]}
+		if i, ok := index[b]; ok {
+			blocks[i].count += n[5]
+			continue
+		}
+		index[b] = len(blocks)
+		b.count = n[5]
+		blocks = append(blocks, b)
+	}
+	return blocks, scanner.Err()
+}
+
+// sourceFunc is a function declaration and the positions it spans.
+type sourceFunc struct {
+	name       string
+	line       int
+	start, end token.Position
+}
+
+// fileFuncs lists the functions declared in a Go file, named as in stack
+// traces: Func, T.Method or (*T).Method.
+func fileFuncs(file string) []sourceFunc {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var funcs []sourceFunc
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		name := fn.Name.Name
+		if fn.Recv != nil && len(fn.Recv.List) == 1 {
+			name = receiverName(fn.Recv.List[0].Type) + "." + name
+		}
+		start, end := fset.Position(fn.Pos()), fset.Position(fn.End())
+		funcs = append(funcs, sourceFunc{name: name, line: start.Line, start: start, end: end})
+	}
+	return funcs
+}
+
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return "(*" + receiverName(t.X) + ")"
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return "?"
+}
+
+func (b profileBlock) within(fn sourceFunc) bool {
+	startsAfter := b.startLine > fn.start.Line || (b.startLine == fn.start.Line && b.startCol >= fn.start.Column)
+	endsBefore := b.endLine < fn.end.Line || (b.endLine == fn.end.Line && b.endCol <= fn.end.Column)
+	return startsAfter && endsBefore
+}
+
+// storeCoverage parses a run's cover profile into per-package and
+// per-function coverage and its blocks, and returns the total percentage
+// of statements covered.
+func (h *CIHandler) storeCoverage(runID uint, profile string) (float64, error) {
+	blocks, err := parseProfile(profile)
+	if err != nil {
+		return 0, err
+	}
+	if len(blocks) == 0 {
+		return 0, fmt.Errorf("the cover profile is empty")
+	}
+
+	root := h.server.GetRepoRoot()
+	var packages []string
+	for _, b := range blocks {
+		if pkg := path.Dir(b.file); !slices.Contains(packages, pkg) {
+			packages = append(packages, pkg)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	dirs := packageDirs(ctx, root, packages)
+
+	byPackage := make(map[string]*models.CICoverage)
+	byFunc := make(map[[2]string]*models.CICoverage)
+	funcs := make(map[string][]sourceFunc)
+	var rows []*models.CICoverage
+	var stored []models.CICoverageBlock
+	statements, covered := 0, 0
+	for _, b := range blocks {
+		pkg := path.Dir(b.file)
+		file := b.file
+		if dir, ok := dirs[pkg]; ok {
+			abs := filepath.Join(dir, path.Base(b.file))
+			if _, seen := funcs[b.file]; !seen {
+				funcs[b.file] = fileFuncs(abs)
+			}
+			if rel, err := filepath.Rel(root, abs); err == nil {
+				file = filepath.ToSlash(rel)
+			}
+		}
+		stored = append(stored, models.CICoverageBlock{
+			RunID: runID, File: file, StartLine: b.startLine, EndLine: b.endLine, Statements: b.statements, Count: b.count,
+		})
+
+		hit := 0
+		if b.count > 0 {
+			hit = b.statements
+		}
+		statements += b.statements
+		covered += hit
+
+		p := byPackage[pkg]
+		if p == nil {
+			p = &models.CICoverage{RunID: runID, Package: pkg}
+			byPackage[pkg] = p
+			rows = append(rows, p)
+		}
+		p.Statements += b.statements
+		p.Covered += hit
+
+		for _, fn := range funcs[b.file] {
+			if !b.within(fn) {
+				continue
+			}
+			key := [2]string{b.file, fn.name}
+			f := byFunc[key]
+			if f == nil {
+				f = &models.CICoverage{RunID: runID, Package: pkg, Function: fn.name, File: file, Line: fn.line}
+				byFunc[key] = f
+				rows = append(rows, f)
+			}
+			f.Statements += b.statements
+			f.Covered += hit
+			break
+		}
+	}
+
+	db := h.server.GetDB()
+	coverage := make([]models.CICoverage, len(rows))
+	for i, r := range rows {
+		coverage[i] = *r
+	}
+	if err := db.CreateInBatches(coverage, 200).Error; err != nil {
+		return 0, err
+	}
+	if err := db.CreateInBatches(stored, 200).Error; err != nil {
+		return 0, err
+	}
+	return percent(covered, statements), nil
+}
+
+// CICoverage reports the coverage of a run by package, the functions its
+// tests never reach, the change against a baseline run and the coverage of
+// the lines changed in the current git diff.
+func (h *CIHandler) CICoverage(ctx context.Context, req *mcp.CallToolRequest, input types.CICoverageInput) (*mcp.CallToolResult, types.CICoverageOutput, error) {
+	db := h.server.GetDB()
+	var ciRun models.CIRun
+	query := db.Where("coverage IS NOT NULL")
+	if input.RunID != 0 {
+		query = query.Where("id = ?", input.RunID)
+	}
+	result := query.Order("started_at DESC, id DESC").Limit(1).Find(&ciRun)
+	if result.Error != nil {
+		return nil, types.CICoverageOutput{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		if input.RunID != 0 {
+			return nil, types.CICoverageOutput{}, fmt.Errorf("run %d has no coverage", input.RunID)
+		}
+		return nil, types.CICoverageOutput{}, fmt.Errorf("no run with coverage yet (run ci_run_tests with coverage)")
+	}
+
+	var baseline models.CIRun
+	if input.Baseline != 0 {
+		result = db.Where("id = ? AND coverage IS NOT NULL", input.Baseline).Limit(1).Find(&baseline)
+		if result.Error == nil && result.RowsAffected == 0 {
+			return nil, types.CICoverageOutput{}, fmt.Errorf("run %d has no coverage", input.Baseline)
+		}
+	} else {
+		result = db.Where("coverage IS NOT NULL AND scope = ? AND run = '' AND id <> ? AND started_at <= ?", ciRun.Scope, ciRun.ID, ciRun.StartedAt).
+			Order("started_at DESC, id DESC").Limit(1).Find(&baseline)
+	}
+	if result.Error != nil {
+		return nil, types.CICoverageOutput{}, result.Error
+	}
+
+	packages, err := loadCoverage(db.Where("function = ''"), ciRun.ID, input.Package)
+	if err != nil {
+		return nil, types.CICoverageOutput{}, err
+	}
+	output := types.CICoverageOutput{
+		RunID:     ciRun.ID,
+		Scope:     ciRun.Scope,
+		Total:     *ciRun.Coverage,
+		Packages:  []types.CIPackageCoverage{},
+		Uncovered: []types.CIFunctionCoverage{},
+	}
+	var before map[string]models.CICoverage
+	if baseline.ID != 0 {
+		output.BaselineRunID = baseline.ID
+		output.BaselineTotal = baseline.Coverage
+		delta := roundPercent(*ciRun.Coverage - *baseline.Coverage)
+		output.Delta = &delta
+		rows, err := loadCoverage(db.Where("function = ''"), baseline.ID, input.Package)
+		if err != nil {
+			return nil, types.CICoverageOutput{}, err
+		}
+		before = make(map[string]models.CICoverage)
+		for _, r := range rows {
+			before[r.Package] = r
+		}
+	}
+	for _, p := range packages {
+		pc := types.CIPackageCoverage{Package: p.Package, Percent: percent(p.Covered, p.Statements), Statements: p.Statements, Covered: p.Covered}
+		if b, ok := before[p.Package]; ok {
+			delta := roundPercent(pc.Percent - percent(b.Covered, b.Statements))
+			pc.Delta = &delta
+		}
+		output.Statements += p.Statements
+		output.Covered += p.Covered
+		output.Packages = append(output.Packages, pc)
+	}
+	if input.Package != "" {
+		output.Total = percent(output.Covered, output.Statements)
+	}
+
+	uncovered, err := loadCoverage(db.Where("function <> '' AND covered = 0 AND statements > 0"), ciRun.ID, input.Package)
+	if err != nil {
+		return nil, types.CICoverageOutput{}, err
+	}
+	slices.SortFunc(uncovered, func(a, b models.CICoverage) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line))
+	})
+	for _, f := range uncovered {
+		output.Uncovered = append(output.Uncovered, types.CIFunctionCoverage{
+			Package: f.Package, Function: f.Function, File: f.File, Line: f.Line, Statements: f.Statements,
+		})
+	}
+
+	changed, err := h.changedCoverage(ctx, ciRun, input.Base)
+	if err != nil {
+		return nil, types.CICoverageOutput{}, err
+	}
+	output.Changed = changed
+	return nil, output, nil
+}
+
+// loadCoverage returns the coverage rows of a run matching query, by
+// package.
+func loadCoverage(query *gorm.DB, runID uint, pkg string) ([]models.CICoverage, error) {
+	query = query.Where("run_id = ?", runID)
+	if pkg != "" {
+		query = query.Where("package = ?", pkg)
+	}
+	var rows []models.CICoverage
+	err := query.Order("package ASC, id ASC").Find(&rows).Error
+	return rows, err
+}
+
+// changedCoverage checks the lines added or changed since base, HEAD by
+// default, against the blocks of a run; every line of an untracked file is
+// changed. Lines outside any block are not statements and are left out. It
+// returns nil outside a git repository.
+func (h *CIHandler) changedCoverage(ctx context.Context, ciRun models.CIRun, base string) (*types.CIChangedCoverage, error) {
+	if base == "" {
+		base = "HEAD"
+	}
+	if strings.HasPrefix(base, "-") {
+		return nil, fmt.Errorf("invalid base %q", base)
+	}
+	root := h.server.GetRepoRoot()
+	diffCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	diff, err := git(diffCtx, root, nil, "diff", "-U0", "--no-color", "--no-ext-diff", "--relative", base, "--", "*.go")
+	if err != nil {
+		if _, err := git(diffCtx, root, nil, "rev-parse", "--git-dir"); err != nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("git diff %s failed: %v", base, err)
+	}
+	untracked, err := git(diffCtx, root, nil, "ls-files", "-z", "--others", "--exclude-standard", "--", "*.go")
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed: %v", err)
+	}
+
+	_, tree := treeState(ctx, root)
+	changed := &types.CIChangedCoverage{
+		Base:      base,
+		Stale:     tree == "" || tree != ciRun.Tree,
+		Uncovered: []types.CIUncoveredLines{},
+	}
+	lines := changedLines(diff)
+	// Every line of a new file that git does not track yet is changed
+	for _, file := range strings.Split(untracked, "\x00") {
+		if file == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(file)))
+		if err != nil {
+			continue
+		}
+		for i := range strings.Count(string(data), "\n") + 1 {
+			lines[file] = append(lines[file], i+1)
+		}
+	}
+	db := h.server.GetDB()
+	for _, file := range slices.Sorted(maps.Keys(lines)) {
+		var blocks []models.CICoverageBlock
+		if err := db.Where("run_id = ? AND file = ?", ciRun.ID, file).Find(&blocks).Error; err != nil {
+			return nil, err
+		}
+		var missed []int
+		for _, line := range lines[file] {
+			statement, hit := false, false
+			for _, b := range blocks {
+				if line >= b.StartLine && line <= b.EndLine {
+					statement = true
+					hit = hit || b.Count > 0
+				}
+			}
+			if !statement {
+				continue
+			}
+			changed.Lines++
+			if hit {
+				changed.Covered++
+			} else {
+				missed = append(missed, line)
+			}
+		}
+		if len(missed) > 0 {
+			changed.Uncovered = append(changed.Uncovered, types.CIUncoveredLines{File: file, Lines: lineRanges(missed)})
+		}
+	}
+	changed.Percent = percent(changed.Covered, changed.Lines)
+	return changed, nil
+}
+
+// changedLines reads the added and changed lines of each file from a diff
+// made with -U0.
+func changedLines(diff string) map[string][]int {
+	lines := make(map[string][]int)
+	var file string
+	for _, line := range strings.Split(diff, "\n") {
+		if name, ok := strings.CutPrefix(line, "+++ "); ok {
+			file = ""
+			if name != "/dev/null" {
+				file = strings.TrimPrefix(name, "b/")
+			}
+			continue
+		}
+		m := hunkHeader.FindStringSubmatch(line)
+		if m == nil || file == "" {
+			continue
+		}
+		start, _ := strconv.Atoi(m[1])
+		count := 1
+		if m[2] != "" {
+			count, _ = strconv.Atoi(m[2])
+		}
+		for i := range count {
+			lines[file] = append(lines[file], start+i)
+		}
+	}
+	return lines
+}
+
+// lineRanges writes sorted line numbers as ranges: "3-5, 9".
+func lineRanges(lines []int) string {
+	var ranges []string
+	for i := 0; i < len(lines); {
+		j := i
+		for j+1 < len(lines) && lines[j+1] == lines[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(lines[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", lines[i], lines[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ", ")
+}
+
+func roundPercent(p float64) float64 {
+	return math.Round(p*10) / 10
+}
@@ -0,0 +1,158 @@
+package ci
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestCIHandler_Coverage(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewCIHandler(srv)
+	ctx := context.Background()
+
+	writeModule(t, tempDir, map[string]string{
+		"a/a.go": "package a\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n\n" +
+			"func Unused() int {\n\treturn 0\n}\n",
+		"a/a_test.go": "package a\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Fatal(\"sum\")\n\t}\n}\n",
+	})
+	for _, args := range [][]string{{"init", "-q"}, {"add", "a", "go.mod"}, {"commit", "-q", "-m", "a"}} {
+		if _, err := git(ctx, tempDir, nil, append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	if _, _, err := handler.CICoverage(ctx, nil, types.CICoverageInput{}); err == nil {
+		t.Errorf("CICoverage() without a coverage run succeeded, want an error")
+	}
+
+	// Uncommitted: a covered method and an uncovered function
+	writeModule(t, tempDir, map[string]string{
+		"a/a.go": "package a\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n\n" +
+			"func Unused() int {\n\treturn 0\n}\n\ntype T struct{}\n\n" +
+			"func (t *T) M() int {\n\treturn 1\n}\n\nfunc Extra() int {\n\treturn 2\n}\n",
+		"a/m_test.go": "package a\n\nimport \"testing\"\n\nfunc TestM(t *testing.T) {\n\tvar v T\n\tv.M()\n}\n",
+	})
+
+	scope := "./..."
+	_, run, err := handler.CIRunTests(ctx, nil, types.CIRunTestsInput{Scope: &scope, Coverage: true})
+	if err != nil {
+		t.Fatalf("CIRunTests() unexpected error: %v", err)
+	}
+	if run.Status != "pass" || run.Coverage == nil || *run.Coverage != 50 {
+		t.Fatalf("CIRunTests() = %s with coverage %v, want pass with 50%%", run.Status, run.Coverage)
+	}
+
+	_, output, err := handler.CICoverage(ctx, nil, types.CICoverageInput{})
+	if err != nil {
+		t.Fatalf("CICoverage() unexpected error: %v", err)
+	}
+	if output.RunID != run.RunID || output.Total != 50 || output.Statements != 4 || output.Covered != 2 || output.Delta != nil {
+		t.Errorf("CICoverage() = %+v, want 2 of 4 statements without a baseline", output)
+	}
+	if len(output.Packages) != 1 || output.Packages[0].Package != "example.com/m/a" || output.Packages[0].Percent != 50 {
+		t.Errorf("CICoverage() packages = %+v, want a at 50%%", output.Packages)
+	}
+	wantUncovered := []types.CIFunctionCoverage{
+		{Package: "example.com/m/a", Function: "Unused", File: "a/a.go", Line: 7, Statements: 1},
+		{Package: "example.com/m/a", Function: "Extra", File: "a/a.go", Line: 17, Statements: 1},
+	}
+	if !reflect.DeepEqual(output.Uncovered, wantUncovered) {
+		t.Errorf("CICoverage() uncovered = %+v, want %+v", output.Uncovered, wantUncovered)
+	}
+	wantChanged := &types.CIChangedCoverage{
+		Base: "HEAD", Lines: 4, Covered: 2, Percent: 50,
+		Uncovered: []types.CIUncoveredLines{{File: "a/a.go", Lines: "18-19"}},
+	}
+	if !reflect.DeepEqual(output.Changed, wantChanged) {
+		t.Errorf("CICoverage() changed = %+v, want %+v", output.Changed, wantChanged)
+	}
+
+	// Covering Extra raises coverage against the first run
+	os.WriteFile(filepath.Join(tempDir, "a", "extra_test.go"), []byte("package a\n\nimport \"testing\"\n\nfunc TestExtra(t *testing.T) { Extra() }\n"), 0644)
+	_, second, err := handler.CIRunTests(ctx, nil, types.CIRunTestsInput{Scope: &scope, Coverage: true})
+	if err != nil {
+		t.Fatalf("CIRunTests() unexpected error: %v", err)
+	}
+	_, output, err = handler.CICoverage(ctx, nil, types.CICoverageInput{})
+	if err != nil {
+		t.Fatalf("CICoverage() unexpected error: %v", err)
+	}
+	if output.RunID != second.RunID || output.BaselineRunID != run.RunID || output.Delta == nil || *output.Delta != 25 {
+		t.Errorf("CICoverage() = run %d against %d with delta %v, want run %d against %d up 25 points", output.RunID, output.BaselineRunID, output.Delta, second.RunID, run.RunID)
+	}
+	if d := output.Packages[0].Delta; d == nil || *d != 25 {
+		t.Errorf("CICoverage() package delta = %v, want 25", d)
+	}
+	if output.Changed == nil || output.Changed.Percent != 100 || output.Changed.Stale {
+		t.Errorf("CICoverage() changed = %+v, want every changed line covered", output.Changed)
+	}
+
+	// The first run against the second as a baseline
+	_, output, err = handler.CICoverage(ctx, nil, types.CICoverageInput{RunID: run.RunID, Baseline: second.RunID})
+	if err != nil {
+		t.Fatalf("CICoverage() unexpected error: %v", err)
+	}
+	if output.Delta == nil || *output.Delta != -25 || output.Changed == nil || !output.Changed.Stale {
+		t.Errorf("CICoverage() = delta %v, changed %+v, want down 25 points on a stale tree", output.Delta, output.Changed)
+	}
+
+	// A new file git does not track yet is changed throughout
+	os.WriteFile(filepath.Join(tempDir, "a", "new.go"), []byte("package a\n\nfunc New() int {\n\treturn 5\n}\n"), 0644)
+	if _, _, err := handler.CIRunTests(ctx, nil, types.CIRunTestsInput{Scope: &scope, Coverage: true}); err != nil {
+		t.Fatalf("CIRunTests() unexpected error: %v", err)
+	}
+	_, output, err = handler.CICoverage(ctx, nil, types.CICoverageInput{})
+	if err != nil {
+		t.Fatalf("CICoverage() unexpected error: %v", err)
+	}
+	wantUncoveredLines := []types.CIUncoveredLines{{File: "a/new.go", Lines: "4-5"}}
+	if output.Changed == nil || !reflect.DeepEqual(output.Changed.Uncovered, wantUncoveredLines) {
+		t.Errorf("CICoverage() changed = %+v, want the untracked file uncovered", output.Changed)
+	}
+}
+
+func TestChangedLines(t *testing.T) {
+	diff := "diff --git a/a/a.go b/a/a.go\n--- a/a/a.go\n+++ b/a/a.go\n" +
+		"@@ -3 +3 @@ func Add(a, b int) int {\n-\treturn a+b\n+\treturn a + b\n" +
+		"@@ -9,0 +10,3 @@ func Unused() int {\n+\n+func Extra() int {\n+}\n" +
+		"@@ -20,2 +22,0 @@\n-gone\n-gone\n" +
+		"diff --git a/b.go b/b.go\ndeleted file mode 100644\n--- a/b.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-package b\n"
+	want := map[string][]int{"a/a.go": {3, 10, 11, 12}}
+	if got := changedLines(diff); !reflect.DeepEqual(got, want) {
+		t.Errorf("changedLines() = %v, want %v", got, want)
+	}
+}
+
+func TestLineRanges(t *testing.T) {
+	tests := []struct {
+		lines []int
+		want  string
+	}{
+		{[]int{7}, "7"},
+		{[]int{3, 4, 5, 9}, "3-5, 9"},
+		{[]int{1, 3, 4}, "1, 3-4"},
+	}
+	for _, tt := range tests {
+		if got := lineRanges(tt.lines); got != tt.want {
+			t.Errorf("lineRanges(%v) = %q, want %q", tt.lines, got, tt.want)
+		}
+	}
+}
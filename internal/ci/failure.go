@@ -0,0 +1,211 @@
+package ci
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+var (
+	// testLocation matches t.Error and t.Fatal lines such as
+	// "    a_test.go:12: boom", which name the file relative to the package.
+	testLocation = regexp.MustCompile(`^\s+([\w.-]+\.go):(\d+):`)
+	// sourceLocation matches compiler errors ("b/b.go:2:23: ...") and panic
+	// stack frames ("\t/repo/a/a.go:12 +0x1d").
+	sourceLocation = regexp.MustCompile(`^\s*((?:[A-Za-z]:)?[^\s:]+\.go):(\d+)(?::\d+)?(?::| \+0x|$)`)
+	// frameLine matches the lines go test frames each test with.
+	frameLine = regexp.MustCompile(`^(\s*=== (RUN|PAUSE|CONT|NAME)\s|\s*--- (PASS|FAIL|SKIP): |FAIL$|FAIL\t|ok\s)`)
+)
+
+const (
+	maxExcerptLines = 40
+	maxLocations    = 10
+)
+
+// CILastFailure returns the latest failed run with its failing tests, an
+// excerpt of their output, the source locations the output points at and
+// whether each failure is still present in the latest run of the scope.
+func (h *CIHandler) CILastFailure(ctx context.Context, req *mcp.CallToolRequest, input types.CILastFailureInput) (*mcp.CallToolResult, types.CILastFailureOutput, error) {
+	db := h.server.GetDB()
+	query := db.Where("status IN ?", []string{"fail", "error", "timeout"})
+	if input.Scope != nil && *input.Scope != "" {
+		query = query.Where("scope = ?", *input.Scope)
+	}
+	var ciRun models.CIRun
+	result := query.Order("started_at DESC, id DESC").Limit(1).Find(&ciRun)
+	if result.Error != nil {
+		return nil, types.CILastFailureOutput{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, types.CILastFailureOutput{Status: "none", Failures: []types.CIFailureDetail{}}, nil
+	}
+
+	var results []models.CITestResult
+	if err := db.Where("run_id = ?", ciRun.ID).Order("id ASC").Find(&results).Error; err != nil {
+		return nil, types.CILastFailureOutput{}, err
+	}
+	var latest models.CIRun
+	if err := db.Where("scope = ?", ciRun.Scope).Order("started_at DESC, id DESC").Limit(1).Find(&latest).Error; err != nil {
+		return nil, types.CILastFailureOutput{}, err
+	}
+	stillFailing := make(map[[2]string]bool)
+	if latest.ID != ciRun.ID {
+		var latestResults []models.CITestResult
+		if err := db.Where("run_id = ? AND status = ?", latest.ID, "fail").Find(&latestResults).Error; err != nil {
+			return nil, types.CILastFailureOutput{}, err
+		}
+		for _, r := range latestResults {
+			stillFailing[[2]string{r.Package, r.Test}] = true
+		}
+	}
+
+	around := 3
+	if input.Context != nil && *input.Context >= 0 {
+		around = min(*input.Context, 20)
+	}
+
+	failed := failedResults(results)
+	var packages []string
+	for _, r := range failed {
+		packages = append(packages, r.Package)
+	}
+	root := h.server.GetRepoRoot()
+	dirs := packageDirs(ctx, root, packages)
+
+	startedAt := ciRun.StartedAt.Format("2006-01-02 15:04:05")
+	output := types.CILastFailureOutput{
+		Status:       ciRun.Status,
+		RunID:        ciRun.ID,
+		Scope:        &ciRun.Scope,
+		StartedAt:    &startedAt,
+		Failures:     []types.CIFailureDetail{},
+		Output:       ciRun.Output,
+		LatestRunID:  latest.ID,
+		LatestStatus: latest.Status,
+	}
+	for _, r := range failed {
+		detail := types.CIFailureDetail{
+			Package:      r.Package,
+			Test:         r.Test,
+			Excerpt:      excerpt(r.Output),
+			Locations:    locations(root, dirs[r.Package], r.Output, around),
+			StillFailing: latest.ID == ciRun.ID || stillFailing[[2]string{r.Package, r.Test}],
+		}
+		output.StillFailing = output.StillFailing || detail.StillFailing
+		output.Failures = append(output.Failures, detail)
+	}
+	return nil, output, nil
+}
+
+// packageDirs asks go list for the directories of the given packages.
+func packageDirs(ctx context.Context, root string, packages []string) map[string]string {
+	dirs := make(map[string]string)
+	if len(packages) == 0 {
+		return dirs
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	args := append([]string{"list", "-e", "-f", "{{.ImportPath}}\t{{.Dir}}"}, packages...)
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return dirs
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if pkg, dir, ok := strings.Cut(line, "\t"); ok && dir != "" {
+			dirs[pkg] = dir
+		}
+	}
+	return dirs
+}
+
+// excerpt drops go test's framing from a test's output and keeps the first
+// maxExcerptLines lines.
+func excerpt(output string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if !frameLine.MatchString(line) {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > maxExcerptLines {
+		more := len(lines) - maxExcerptLines
+		lines = append(lines[:maxExcerptLines], fmt.Sprintf("... %d more lines", more))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// locations finds the file:line locations in a test's output that lie in
+// the repository, with the source around each. Test log lines name files
+// relative to the package directory, compiler errors relative to the
+// repository root and panics absolutely.
+func locations(root, dir, output string, around int) []types.CISourceLocation {
+	found := []types.CISourceLocation{}
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		var file string
+		var number int
+		if m := testLocation.FindStringSubmatch(line); m != nil && dir != "" {
+			file = filepath.Join(dir, m[1])
+			number, _ = strconv.Atoi(m[2])
+		} else if m := sourceLocation.FindStringSubmatch(line); m != nil {
+			file = filepath.FromSlash(m[1])
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(root, file)
+			}
+			number, _ = strconv.Atoi(m[2])
+		} else {
+			continue
+		}
+
+		rel, err := filepath.Rel(root, file)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue // the standard library or the module cache
+		}
+		rel = filepath.ToSlash(rel)
+		key := fmt.Sprintf("%s:%d", rel, number)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		found = append(found, types.CISourceLocation{File: rel, Line: number, Snippet: snippet(file, number, around)})
+		if len(found) == maxLocations {
+			break
+		}
+	}
+	return found
+}
+
+// snippet returns the lines around line in file, numbered, with the line
+// itself marked by ">".
+func snippet(file string, line, around int) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(string(data), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	var b strings.Builder
+	for i := max(line-around, 1); i <= min(line+around, len(lines)); i++ {
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%s %4d | %s\n", marker, i, lines[i-1])
+	}
+	return b.String()
+}
@@ -0,0 +1,221 @@
+package ci
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+// maxReruns caps how often ci_flaky retries each failing test.
+const maxReruns = 10
+
+// CIFlaky finds tests that both passed and failed on the same working
+// tree. A test's score is the share of trees it ran on more than once
+// where it did both. With rerun set, the failing tests of a run are first
+// retried one by one to tell flaky failures from deterministic ones; the
+// reruns are recorded and count towards the scores, unless the working
+// tree changed since the run.
+func (h *CIHandler) CIFlaky(ctx context.Context, req *mcp.CallToolRequest, input types.CIFlakyInput) (*mcp.CallToolResult, types.CIFlakyOutput, error) {
+	output := types.CIFlakyOutput{Tests: []types.CIFlakyTest{}, Reruns: []types.CIRerunResult{}}
+	if input.Rerun > 0 {
+		reruns, err := h.rerunFailures(ctx, input)
+		if err != nil {
+			return nil, types.CIFlakyOutput{}, err
+		}
+		output.Reruns = reruns
+	}
+
+	runs := h.server.GetDB().Model(&models.CIRun{}).Select("id").
+		Where("tree <> '' AND status NOT IN ?", []string{"running", "cancelled"}).
+		Where("rerun_of = 0 OR tree = (SELECT failed.tree FROM ci_runs AS failed WHERE failed.id = ci_runs.rerun_of)")
+	if input.Since != "" {
+		since, err := parseDay(input.Since)
+		if err != nil {
+			return nil, types.CIFlakyOutput{}, err
+		}
+		runs = runs.Where("started_at >= ?", since)
+	}
+	var rows []struct {
+		Package   string
+		Test      string
+		Status    string
+		Tree      string
+		StartedAt time.Time
+	}
+	query := h.server.GetDB().Table("ci_test_results").
+		Select("ci_test_results.package, ci_test_results.test, ci_test_results.status, ci_runs.tree, ci_runs.started_at").
+		Joins("JOIN ci_runs ON ci_runs.id = ci_test_results.run_id").
+		Where("ci_test_results.test <> '' AND ci_test_results.status IN ? AND ci_test_results.run_id IN (?)", []string{"pass", "fail"}, runs)
+	if input.Package != "" {
+		query = query.Where("ci_test_results.package = ?", input.Package)
+	}
+	if err := query.Order("ci_runs.started_at ASC, ci_test_results.id ASC").Scan(&rows).Error; err != nil {
+		return nil, types.CIFlakyOutput{}, err
+	}
+
+	type outcomes struct {
+		passed, failed int
+		last           time.Time
+	}
+	trees := make(map[[2]string]map[string]*outcomes)
+	for _, r := range rows {
+		key := [2]string{r.Package, r.Test}
+		if trees[key] == nil {
+			trees[key] = make(map[string]*outcomes)
+		}
+		o := trees[key][r.Tree]
+		if o == nil {
+			o = &outcomes{}
+			trees[key][r.Tree] = o
+		}
+		if r.Status == "pass" {
+			o.passed++
+		} else {
+			o.failed++
+		}
+		o.last = r.StartedAt
+	}
+
+	for key, byTree := range trees {
+		test := types.CIFlakyTest{Package: key[0], Test: key[1], Trees: len(byTree)}
+		repeated := 0
+		var lastFlaked time.Time
+		for _, o := range byTree {
+			test.Passed += o.passed
+			test.Failed += o.failed
+			if o.passed+o.failed > 1 {
+				repeated++
+			}
+			if o.passed > 0 && o.failed > 0 {
+				test.MixedTrees++
+				if o.last.After(lastFlaked) {
+					lastFlaked = o.last
+				}
+			}
+		}
+		if test.MixedTrees == 0 {
+			continue
+		}
+		test.Runs = test.Passed + test.Failed
+		test.Score = math.Round(float64(test.MixedTrees)/float64(repeated)*1000) / 1000
+		test.LastFlaked = lastFlaked.Format("2006-01-02 15:04:05")
+		output.Tests = append(output.Tests, test)
+	}
+	slices.SortFunc(output.Tests, func(a, b types.CIFlakyTest) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(b.MixedTrees, a.MixedTrees),
+			cmp.Compare(a.Package, b.Package),
+			cmp.Compare(a.Test, b.Test),
+		)
+	})
+	if input.Limit > 0 && len(output.Tests) > input.Limit {
+		output.Tests = output.Tests[:input.Limit]
+	}
+	return nil, output, nil
+}
+
+// rerunFailures runs each failing test of a run again, alone, up to
+// input.Rerun times. A test that passes at least once is flaky; one that
+// fails every time is deterministic. Reruns use the working tree as it is:
+// if it is not the tree of the run, as changedCoverage checks, a test
+// that passes may have been fixed instead, so it is only reported stale.
+func (h *CIHandler) rerunFailures(ctx context.Context, input types.CIFlakyInput) ([]types.CIRerunResult, error) {
+	attempts := min(input.Rerun, maxReruns)
+	timeout, err := h.timeout(input.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	db := h.server.GetDB()
+	var ciRun models.CIRun
+	query := db.Where("status IN ?", []string{"fail", "timeout"})
+	if input.RunID != 0 {
+		query = db.Where("id = ?", input.RunID)
+	}
+	result := query.Order("started_at DESC, id DESC").Limit(1).Find(&ciRun)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		if input.RunID != 0 {
+			return nil, fmt.Errorf("run %d not found", input.RunID)
+		}
+		return nil, fmt.Errorf("no failed run to rerun")
+	}
+	if ciRun.Runner != "go" {
+		return nil, fmt.Errorf("run %d used %s; only go test runs can be rerun", ciRun.ID, ciRun.Runner)
+	}
+
+	var failed []models.CITestResult
+	err = db.Where("run_id = ? AND test <> '' AND status = ?", ciRun.ID, "fail").Order("id ASC").Find(&failed).Error
+	if err != nil {
+		return nil, err
+	}
+	if input.Package != "" {
+		failed = slices.DeleteFunc(failed, func(r models.CITestResult) bool { return r.Package != input.Package })
+	}
+
+	_, tree := treeState(ctx, h.server.GetRepoRoot())
+	stale := tree == "" || tree != ciRun.Tree
+
+	reruns := []types.CIRerunResult{}
+	for _, f := range failed {
+		// A test fails with its failing subtests; rerunning those is enough
+		if slices.ContainsFunc(failed, func(r models.CITestResult) bool {
+			return r.Package == f.Package && strings.HasPrefix(r.Test, f.Test+"/")
+		}) {
+			continue
+		}
+
+		rerun := types.CIRerunResult{Package: f.Package, Test: f.Test, RunID: ciRun.ID, Stale: stale, RerunIDs: []uint{}}
+		for range attempts {
+			j, err := h.startJob(jobSpec{scope: f.Package, run: runPattern(f.Test), rerunOf: ciRun.ID, timeout: timeout})
+			if err != nil {
+				return nil, err
+			}
+			j.wait(ctx)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			var r models.CITestResult
+			db.Where("run_id = ? AND package = ? AND test = ?", j.runID, f.Package, f.Test).Limit(1).Find(&r)
+			rerun.RerunIDs = append(rerun.RerunIDs, j.runID)
+			rerun.Attempts++
+			if r.Status == "pass" {
+				rerun.Passed++
+			} else {
+				rerun.Failed++
+			}
+		}
+		switch {
+		case rerun.Passed == 0:
+			rerun.Verdict = "deterministic"
+		case stale:
+			rerun.Verdict = "stale"
+		default:
+			rerun.Verdict = "flaky"
+		}
+		reruns = append(reruns, rerun)
+	}
+	return reruns, nil
+}
+
+// runPattern returns the -run pattern matching exactly one test or
+// subtest, such as ^TestA$/^case_1$.
+func runPattern(test string) string {
+	parts := strings.Split(test, "/")
+	for i, part := range parts {
+		parts[i] = "^" + regexp.QuoteMeta(part) + "$"
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,218 @@
+package ci
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestCIHandler_Flaky(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewCIHandler(srv)
+	ctx := context.Background()
+
+	// TestFlaky flips on tree t1 and passes twice on t2; TestBroken fails
+	// on every tree it runs on; TestOnce changes result only across trees
+	start := time.Now().Add(-time.Hour)
+	runs := []struct {
+		tree    string
+		results map[string]string
+	}{
+		{"t1", map[string]string{"TestFlaky": "pass", "TestBroken": "fail", "TestOnce": "pass"}},
+		{"t1", map[string]string{"TestFlaky": "fail", "TestBroken": "fail"}},
+		{"t2", map[string]string{"TestFlaky": "pass", "TestBroken": "fail", "TestOnce": "fail"}},
+		{"t2", map[string]string{"TestFlaky": "pass"}},
+		{"", map[string]string{"TestBroken": "pass"}},
+	}
+	for i, r := range runs {
+		run := models.CIRun{Scope: "./...", Tree: r.tree, Status: "fail", StartedAt: start.Add(time.Duration(i) * time.Minute)}
+		for test, status := range r.results {
+			run.Results = append(run.Results, models.CITestResult{Package: "example.com/m/a", Test: test, Status: status})
+		}
+		if err := srv.GetDB().Create(&run).Error; err != nil {
+			t.Fatalf("Failed to create run: %v", err)
+		}
+	}
+
+	_, output, err := handler.CIFlaky(ctx, nil, types.CIFlakyInput{})
+	if err != nil {
+		t.Fatalf("CIFlaky() unexpected error: %v", err)
+	}
+	if len(output.Tests) != 1 {
+		t.Fatalf("CIFlaky() = %+v, want only TestFlaky", output.Tests)
+	}
+	flaky := output.Tests[0]
+	if flaky.Test != "TestFlaky" || flaky.Score != 0.5 || flaky.Runs != 4 || flaky.Failed != 1 || flaky.Trees != 2 || flaky.MixedTrees != 1 {
+		t.Errorf("CIFlaky() = %+v, want TestFlaky mixed on one of two repeated trees", flaky)
+	}
+	if want := start.Add(time.Minute).Format("2006-01-02 15:04:05"); flaky.LastFlaked != want {
+		t.Errorf("CIFlaky() last flaked = %s, want %s", flaky.LastFlaked, want)
+	}
+
+	_, output, err = handler.CIFlaky(ctx, nil, types.CIFlakyInput{Package: "example.com/m/b"})
+	if err != nil || len(output.Tests) != 0 {
+		t.Errorf("CIFlaky(b) = %+v, %v, want no tests", output.Tests, err)
+	}
+}
+
+func TestCIHandler_FlakyRerun(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewCIHandler(srv)
+	ctx := context.Background()
+
+	// TestFlaky fails until it has left a marker behind, outside the tree
+	marker := filepath.Join(t.TempDir(), "marker")
+	writeModule(t, tempDir, map[string]string{
+		"a/a_test.go": "package a\n\nimport (\n\t\"os\"\n\t\"testing\"\n)\n\n" +
+			"func TestFlaky(t *testing.T) {\n\tif _, err := os.Stat(" + strconv.Quote(marker) + "); err != nil {\n" +
+			"\t\tos.WriteFile(" + strconv.Quote(marker) + ", nil, 0644)\n\t\tt.Fatal(\"first run\")\n\t}\n}\n\n" +
+			"func TestBroken(t *testing.T) {\n\tt.Run(\"case 1\", func(t *testing.T) { t.Fatal(\"always\") })\n}\n",
+	})
+	for _, args := range [][]string{{"init", "-q"}, {"add", "-A", "--", ".", stateExclude}, {"commit", "-q", "-m", "init"}} {
+		if _, err := git(ctx, tempDir, nil, append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	if _, _, err := handler.CIFlaky(ctx, nil, types.CIFlakyInput{Rerun: 2}); err == nil {
+		t.Errorf("CIFlaky() rerun without a failed run succeeded, want an error")
+	}
+
+	scope := "./..."
+	_, run, err := handler.CIRunTests(ctx, nil, types.CIRunTestsInput{Scope: &scope})
+	if err != nil || run.Failed != 3 {
+		t.Fatalf("CIRunTests() = %+v, %v, want TestFlaky, TestBroken and its subtest failing", run, err)
+	}
+
+	_, output, err := handler.CIFlaky(ctx, nil, types.CIFlakyInput{Rerun: 2})
+	if err != nil {
+		t.Fatalf("CIFlaky() unexpected error: %v", err)
+	}
+	if len(output.Reruns) != 2 {
+		t.Fatalf("CIFlaky() reruns = %+v, want TestFlaky and TestBroken/case_1", output.Reruns)
+	}
+	for _, r := range output.Reruns {
+		want := map[string]string{"TestFlaky": "flaky", "TestBroken/case_1": "deterministic"}[r.Test]
+		if r.Verdict != want || r.Stale || r.Attempts != 2 || len(r.RerunIDs) != 2 || r.RunID != run.RunID {
+			t.Errorf("CIFlaky() rerun = %+v, want %s after 2 attempts", r, want)
+		}
+	}
+	if len(output.Tests) != 1 || output.Tests[0].Test != "TestFlaky" || output.Tests[0].MixedTrees != 1 {
+		t.Errorf("CIFlaky() tests = %+v, want TestFlaky mixed on one tree", output.Tests)
+	}
+
+	var rerun models.CIRun
+	srv.GetDB().First(&rerun, output.Reruns[1].RerunIDs[0])
+	if rerun.Scope != "example.com/m/a" || rerun.Run != "^TestBroken$/^case_1$" || rerun.RerunOf != run.RunID {
+		t.Errorf("rerun recorded as %q -run %q of run %d, want the package and the test of run %d", rerun.Scope, rerun.Run, rerun.RerunOf, run.RunID)
+	}
+
+	// Once the tree has changed, a test that fails there and passes on a
+	// retry of the old run may have been fixed: neither counts
+	os.WriteFile(filepath.Join(tempDir, "a", "a.go"), []byte("package a\n"), 0644)
+	os.Remove(marker)
+	if _, _, err := handler.CIRunTests(ctx, nil, types.CIRunTestsInput{Scope: &scope}); err != nil {
+		t.Fatalf("CIRunTests() unexpected error: %v", err)
+	}
+	_, output, err = handler.CIFlaky(ctx, nil, types.CIFlakyInput{Rerun: 1, RunID: run.RunID, Package: "example.com/m/a"})
+	if err != nil {
+		t.Fatalf("CIFlaky() unexpected error: %v", err)
+	}
+	for _, r := range output.Reruns {
+		want := map[string]string{"TestFlaky": "stale", "TestBroken/case_1": "deterministic"}[r.Test]
+		if r.Verdict != want || !r.Stale {
+			t.Errorf("CIFlaky() rerun on a changed tree = %+v, want stale and %s", r, want)
+		}
+	}
+	if len(output.Tests) != 1 || output.Tests[0].MixedTrees != 1 || output.Tests[0].Trees != 2 {
+		t.Errorf("CIFlaky() tests = %+v, want TestFlaky mixed on the first tree only", output.Tests)
+	}
+}
+
+func TestTreeState(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	if commit, tree := treeState(context.Background(), dir); commit != "" || tree != "" {
+		t.Errorf("treeState() outside a repository = %q, %q, want empty", commit, tree)
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := git(context.Background(), dir, nil, append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return out
+	}
+	run("init", "-q")
+	os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0644)
+	run("add", "a.go")
+	run("commit", "-q", "-m", "a")
+
+	commit, clean := treeState(context.Background(), dir)
+	if commit != run("rev-parse", "HEAD") || clean != run("rev-parse", "HEAD^{tree}") {
+		t.Errorf("treeState() = %q, %q, want HEAD and its tree", commit, clean)
+	}
+
+	// The server's state does not change the tree; an untracked file does
+	os.MkdirAll(filepath.Join(dir, ".agent"), 0755)
+	os.WriteFile(filepath.Join(dir, ".agent", "state.db"), []byte("x"), 0644)
+	if _, tree := treeState(context.Background(), dir); tree != clean {
+		t.Errorf("treeState() with .agent = %q, want %q", tree, clean)
+	}
+	os.WriteFile(filepath.Join(dir, "b.go"), []byte("package a\n"), 0644)
+	_, dirty := treeState(context.Background(), dir)
+	if dirty == "" || dirty == clean {
+		t.Errorf("treeState() with an untracked file = %q, want a tree other than %q", dirty, clean)
+	}
+	if _, again := treeState(context.Background(), dir); again != dirty {
+		t.Errorf("treeState() = %q then %q, want the same tree", dirty, again)
+	}
+	if status := run("status", "--porcelain"); status != "?? .agent/\n?? b.go" {
+		t.Errorf("git status = %q, want the index untouched", status)
+	}
+}
+
+func TestRunPattern(t *testing.T) {
+	tests := []struct {
+		test string
+		want string
+	}{
+		{"TestA", "^TestA$"},
+		{"TestA/case_1", "^TestA$/^case_1$"},
+		{"TestA/a.b(c)", `^TestA$/^a\.b\(c\)$`},
+	}
+	for _, tt := range tests {
+		if got := runPattern(tt.test); got != tt.want {
+			t.Errorf("runPattern(%q) = %q, want %q", tt.test, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package ci
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// stateExclude keeps the server's own state out of tree hashes; the
+// database changes with every run.
+const stateExclude = ":(exclude).agent"
+
+// treeState returns the commit checked out in root and the hash of the
+// working tree including uncommitted and untracked changes, so that runs
+// of the same code can be compared. Both are empty outside a git
+// repository and before the first commit.
+func treeState(ctx context.Context, root string) (commit, tree string) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	commit, err := git(ctx, root, nil, "rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		return "", ""
+	}
+	status, err := git(ctx, root, nil, "status", "--porcelain", "--untracked-files=all", "--", ".", stateExclude)
+	if err != nil {
+		return commit, ""
+	}
+	if status == "" {
+		tree, _ = git(ctx, root, nil, "rev-parse", "HEAD^{tree}")
+		return commit, tree
+	}
+
+	// Stage everything into a scratch index; the real one is left alone
+	dir, err := os.MkdirTemp("", "project-manager-index-")
+	if err != nil {
+		return commit, ""
+	}
+	defer os.RemoveAll(dir)
+	env := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(dir, "index"))
+	if _, err := git(ctx, root, env, "read-tree", "HEAD"); err != nil {
+		return commit, ""
+	}
+	if _, err := git(ctx, root, env, "add", "--all", "--", ".", stateExclude); err != nil {
+		return commit, ""
+	}
+	tree, _ = git(ctx, root, env, "write-tree")
+	return commit, tree
+}
+
+func git(ctx context.Context, root string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", root}, args...)...)
+	cmd.Env = env
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
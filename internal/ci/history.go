@@ -0,0 +1,279 @@
+package ci
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
+)
+
+// slowdownThreshold is how much a package's median duration must grow from
+// its first period to its latest to be reported as getting slower.
+const slowdownThreshold = 0.2
+
+// CIHistory lists CI runs, newest first, with their duration and test
+// counts.
+func (h *CIHandler) CIHistory(ctx context.Context, req *mcp.CallToolRequest, input types.CIHistoryInput) (*mcp.CallToolResult, types.CIHistoryOutput, error) {
+	db := h.server.GetDB()
+	query, err := runFilter(db.Model(&models.CIRun{}), input.Scope, input.Since, input.Until)
+	if err != nil {
+		return nil, types.CIHistoryOutput{}, err
+	}
+	if input.Status != "" {
+		query = query.Where("status = ?", input.Status)
+	}
+	var output types.CIHistoryOutput
+	if err := query.Count(&output.Total).Error; err != nil {
+		return nil, types.CIHistoryOutput{}, err
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	var runs []models.CIRun
+	if err := query.Order("started_at DESC, id DESC").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, types.CIHistoryOutput{}, err
+	}
+
+	ids := make([]uint, len(runs))
+	for i, r := range runs {
+		ids[i] = r.ID
+	}
+	var counts []struct {
+		RunID  uint
+		Status string
+		Count  int
+	}
+	err = db.Model(&models.CITestResult{}).Select("run_id, status, COUNT(*) AS count").
+		Where("run_id IN ? AND test <> ''", ids).Group("run_id, status").Scan(&counts).Error
+	if err != nil {
+		return nil, types.CIHistoryOutput{}, err
+	}
+	byRun := make(map[uint]map[string]int)
+	for _, c := range counts {
+		if byRun[c.RunID] == nil {
+			byRun[c.RunID] = make(map[string]int)
+		}
+		byRun[c.RunID][c.Status] = c.Count
+	}
+
+	output.Runs = []types.CIRunSummary{}
+	for _, r := range runs {
+		summary := types.CIRunSummary{
+			RunID:     r.ID,
+			Scope:     r.Scope,
+			Status:    r.Status,
+			StartedAt: r.StartedAt.Format("2006-01-02 15:04:05"),
+			Passed:    byRun[r.ID]["pass"],
+			Failed:    byRun[r.ID]["fail"],
+			Skipped:   byRun[r.ID]["skip"],
+			Coverage:  r.Coverage,
+		}
+		if r.FinishedAt != nil {
+			finishedAt := r.FinishedAt.Format("2006-01-02 15:04:05")
+			summary.FinishedAt = &finishedAt
+			summary.Duration = roundSeconds(r.FinishedAt.Sub(r.StartedAt).Seconds())
+		}
+		output.Runs = append(output.Runs, summary)
+	}
+	return nil, output, nil
+}
+
+// CITrends reports, per package, the pass rate and duration percentiles of
+// its results in each day or ISO week, so that suites getting slower or
+// flakier stand out. Running and cancelled runs are left out.
+func (h *CIHandler) CITrends(ctx context.Context, req *mcp.CallToolRequest, input types.CITrendsInput) (*mcp.CallToolResult, types.CITrendsOutput, error) {
+	period := input.Period
+	if period == "" {
+		period = "week"
+	}
+	if period != "day" && period != "week" {
+		return nil, types.CITrendsOutput{}, fmt.Errorf("invalid period %q (allowed: day, week)", period)
+	}
+	since := input.Since
+	if since == "" {
+		// The last 12 periods, including the current one
+		start := dayStart(time.Now()).AddDate(0, 0, -11)
+		if period == "week" {
+			start = weekStart(time.Now()).AddDate(0, 0, -7*11)
+		}
+		since = start.Format("2006-01-02")
+	}
+
+	db := h.server.GetDB()
+	runs, err := runFilter(db.Model(&models.CIRun{}).Select("id"), input.Scope, since, input.Until)
+	if err != nil {
+		return nil, types.CITrendsOutput{}, err
+	}
+	// Reruns of single tests and benchmark runs would skew package times
+	runs = runs.Where("status NOT IN ? AND run = ''", []string{"running", "cancelled"})
+
+	var rows []struct {
+		Package   string
+		Status    string
+		Elapsed   float64
+		StartedAt time.Time
+	}
+	query := db.Table("ci_test_results").
+		Select("ci_test_results.package, ci_test_results.status, ci_test_results.elapsed, ci_runs.started_at").
+		Joins("JOIN ci_runs ON ci_runs.id = ci_test_results.run_id").
+		Where("ci_test_results.test = '' AND ci_test_results.status <> 'skip' AND ci_test_results.run_id IN (?)", runs)
+	if input.Package != "" {
+		query = query.Where("ci_test_results.package = ?", input.Package)
+	}
+	if err := query.Order("ci_runs.started_at ASC, ci_test_results.id ASC").Scan(&rows).Error; err != nil {
+		return nil, types.CITrendsOutput{}, err
+	}
+
+	type sample struct {
+		period  string
+		passed  bool
+		elapsed float64
+	}
+	samples := make(map[string][]sample)
+	for _, r := range rows {
+		samples[r.Package] = append(samples[r.Package], sample{
+			period:  periodLabel(r.StartedAt, period),
+			passed:  r.Status == "pass",
+			elapsed: r.Elapsed,
+		})
+	}
+
+	output := types.CITrendsOutput{Period: period, Since: since, Packages: []types.CIPackageTrend{}, Slowing: []string{}}
+	for _, pkg := range slices.Sorted(maps.Keys(samples)) {
+		trend := types.CIPackageTrend{Package: pkg, Points: []types.CITrendPoint{}}
+		var all []float64
+		passed := 0
+		var points []sample // samples of the current period
+		flush := func() {
+			if len(points) == 0 {
+				return
+			}
+			var elapsed []float64
+			ok := 0
+			for _, s := range points {
+				elapsed = append(elapsed, s.elapsed)
+				if s.passed {
+					ok++
+				}
+			}
+			trend.Points = append(trend.Points, types.CITrendPoint{
+				Period:   points[0].period,
+				Runs:     len(points),
+				PassRate: percent(ok, len(points)),
+				P50:      percentile(elapsed, 50),
+				P90:      percentile(elapsed, 90),
+			})
+			points = nil
+		}
+		for _, s := range samples[pkg] {
+			if len(points) > 0 && points[0].period != s.period {
+				flush()
+			}
+			points = append(points, s)
+			all = append(all, s.elapsed)
+			if s.passed {
+				passed++
+			}
+		}
+		flush()
+
+		trend.Runs = len(all)
+		trend.PassRate = percent(passed, len(all))
+		trend.P50 = percentile(all, 50)
+		trend.P90 = percentile(all, 90)
+		trend.P99 = percentile(all, 99)
+		if n := len(trend.Points); n > 1 && trend.Points[0].P50 > 0 {
+			first, last := trend.Points[0].P50, trend.Points[n-1].P50
+			trend.Change = math.Round((last-first)/first*1000) / 10
+			if last > first*(1+slowdownThreshold) {
+				output.Slowing = append(output.Slowing, pkg)
+			}
+		}
+		output.Packages = append(output.Packages, trend)
+	}
+	return nil, output, nil
+}
+
+// runFilter narrows a query on ci_runs to a scope and to runs started on
+// or after since and before until (YYYY-MM-DD).
+func runFilter(query *gorm.DB, scope *string, since, until string) (*gorm.DB, error) {
+	if scope != nil && *scope != "" {
+		query = query.Where("scope = ?", *scope)
+	}
+	if since != "" {
+		date, err := parseDay(since)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("started_at >= ?", date)
+	}
+	if until != "" {
+		date, err := parseDay(until)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("started_at < ?", date)
+	}
+	return query, nil
+}
+
+// parseDay reads a date in local time, which run timestamps are stored in.
+func parseDay(value string) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", value)
+	}
+	return date, nil
+}
+
+// periodLabel names the day (2025-07-01) or ISO week (2025-W27) t falls in.
+func periodLabel(t time.Time, period string) string {
+	t = t.Local()
+	if period == "day" {
+		return t.Format("2006-01-02")
+	}
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, week)
+}
+
+func dayStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// weekStart returns the Monday starting t's ISO week.
+func weekStart(t time.Time) time.Time {
+	return dayStart(t).AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+}
+
+// percentile returns the p-th percentile of values by the nearest-rank
+// method, in seconds rounded to milliseconds.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Sorted(slices.Values(values))
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return roundSeconds(sorted[max(rank, 1)-1])
+}
+
+// percent returns n as a percentage of total, to one decimal.
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(n)/float64(total)*1000) / 10
+}
+
+func roundSeconds(s float64) float64 {
+	return math.Round(s*1000) / 1000
+}
@@ -0,0 +1,139 @@
+package ci
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestCIHandler_HistoryAndTrends(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewCIHandler(srv)
+	ctx := context.Background()
+
+	// Two runs a week for three weeks; package a gets slower, b fails once
+	monday := weekStart(time.Now()).AddDate(0, 0, -14)
+	for week := range 3 {
+		for day := range 2 {
+			start := monday.AddDate(0, 0, 7*week+day).Add(9 * time.Hour)
+			finish := start.Add(time.Duration(10+week) * time.Second)
+			status, bStatus := "pass", "pass"
+			if week == 1 && day == 0 {
+				status, bStatus = "fail", "fail"
+			}
+			run := models.CIRun{Scope: "./...", Status: status, StartedAt: start, FinishedAt: &finish, Results: []models.CITestResult{
+				{Package: "example.com/m/a", Status: "pass", Elapsed: float64(1 + week)},
+				{Package: "example.com/m/a", Test: "TestA", Status: "pass"},
+				{Package: "example.com/m/b", Status: bStatus, Elapsed: 2},
+				{Package: "example.com/m/b", Test: "TestB", Status: bStatus},
+				{Package: "example.com/m/b", Test: "TestLater", Status: "skip"},
+				{Package: "example.com/m/c", Status: "skip"},
+			}}
+			if err := srv.GetDB().Create(&run).Error; err != nil {
+				t.Fatalf("Failed to create run: %v", err)
+			}
+		}
+	}
+	other := models.CIRun{Scope: "./a", Status: "cancelled", StartedAt: monday.AddDate(0, 0, 15).Add(10 * time.Hour)}
+	srv.GetDB().Create(&other)
+
+	t.Run("History", func(t *testing.T) {
+		_, output, err := handler.CIHistory(ctx, nil, types.CIHistoryInput{})
+		if err != nil {
+			t.Fatalf("CIHistory() unexpected error: %v", err)
+		}
+		if output.Total != 7 || len(output.Runs) != 7 || output.Runs[0].RunID != other.ID {
+			t.Fatalf("CIHistory() = %d runs of %d, want 7 newest first", len(output.Runs), output.Total)
+		}
+		oldest := output.Runs[6]
+		if oldest.Duration != 10 || oldest.Passed != 2 || oldest.Skipped != 1 || oldest.FinishedAt == nil {
+			t.Errorf("CIHistory() oldest run = %+v, want 10s with 2 passed and 1 skipped", oldest)
+		}
+
+		scope := "./..."
+		_, output, err = handler.CIHistory(ctx, nil, types.CIHistoryInput{Scope: &scope, Status: "fail"})
+		if err != nil {
+			t.Fatalf("CIHistory() unexpected error: %v", err)
+		}
+		if output.Total != 1 || output.Runs[0].Failed != 1 || output.Runs[0].Duration != 11 {
+			t.Errorf("CIHistory(fail) = %+v, want the failed run", output.Runs)
+		}
+
+		since := monday.AddDate(0, 0, 7).Format("2006-01-02")
+		until := monday.AddDate(0, 0, 14).Format("2006-01-02")
+		_, output, err = handler.CIHistory(ctx, nil, types.CIHistoryInput{Since: since, Until: until, Limit: 1})
+		if err != nil {
+			t.Fatalf("CIHistory() unexpected error: %v", err)
+		}
+		if output.Total != 2 || len(output.Runs) != 1 {
+			t.Errorf("CIHistory(%s..%s, limit 1) = %d runs of %d, want 1 of 2", since, until, len(output.Runs), output.Total)
+		}
+
+		if _, _, err := handler.CIHistory(ctx, nil, types.CIHistoryInput{Since: "last week"}); err == nil {
+			t.Errorf("CIHistory() with an invalid date succeeded, want an error")
+		}
+	})
+
+	t.Run("Trends", func(t *testing.T) {
+		_, output, err := handler.CITrends(ctx, nil, types.CITrendsInput{})
+		if err != nil {
+			t.Fatalf("CITrends() unexpected error: %v", err)
+		}
+		if len(output.Packages) != 2 {
+			t.Fatalf("CITrends() packages = %+v, want a and b without the skipped c", output.Packages)
+		}
+		a, b := output.Packages[0], output.Packages[1]
+		if a.Runs != 6 || a.PassRate != 100 || a.P50 != 2 || a.P99 != 3 || len(a.Points) != 3 || a.Change != 200 {
+			t.Errorf("CITrends() a = %+v, want 6 runs, median 2s and 200%% slower", a)
+		}
+		if b.PassRate != 83.3 || b.Points[1].PassRate != 50 || b.Change != 0 {
+			t.Errorf("CITrends() b = %+v, want one failure in the second week", b)
+		}
+		if !slices.Equal(output.Slowing, []string{"example.com/m/a"}) {
+			t.Errorf("CITrends() slowing = %v, want a", output.Slowing)
+		}
+
+		_, output, err = handler.CITrends(ctx, nil, types.CITrendsInput{Package: "example.com/m/a", Period: "day", Since: monday.Format("2006-01-02")})
+		if err != nil {
+			t.Fatalf("CITrends() unexpected error: %v", err)
+		}
+		if len(output.Packages) != 1 || len(output.Packages[0].Points) != 6 || output.Packages[0].Points[0].Period != monday.Format("2006-01-02") {
+			t.Errorf("CITrends(day) = %+v, want a point per day for a", output.Packages)
+		}
+
+		if _, _, err := handler.CITrends(ctx, nil, types.CITrendsInput{Period: "month"}); err == nil {
+			t.Errorf("CITrends() with period month succeeded, want an error")
+		}
+	})
+}
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{nil, 50, 0},
+		{[]float64{3}, 99, 3},
+		{[]float64{4, 1, 3, 2}, 50, 2},
+		{[]float64{4, 1, 3, 2}, 90, 4},
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 90, 9},
+	}
+	for _, tt := range tests {
+		if got := percentile(tt.values, tt.p); got != tt.want {
+			t.Errorf("percentile(%v, %v) = %v, want %v", tt.values, tt.p, got, tt.want)
+		}
+	}
+}
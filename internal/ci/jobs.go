@@ -0,0 +1,542 @@
+package ci
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
+)
+
+const (
+	// timeoutGrace is how long a run may overrun its timeout, for building
+	// and for go test to report the tests that hung, before it is killed.
+	timeoutGrace = 30 * time.Second
+	// maxOutputChunk caps the output ci_output returns per call.
+	maxOutputChunk = 64 * 1024
+	// keepFinishedJobs is how many finished jobs stay in memory for
+	// ci_output; older runs are read back from the database.
+	keepFinishedJobs = 16
+)
+
+// job is a test run in the background. Its run is stored with status
+// running and updated when the run ends.
+type job struct {
+	runID   uint
+	scope   string
+	timeout time.Duration
+	started time.Time
+	cancel  context.CancelFunc
+	done    chan struct{}
+	// runner reads the results of runs other than go test's, which have no
+	// parser; report is the file it has the tool write them to
+	runner *testRunner
+	report string
+
+	mu        sync.Mutex
+	parser    *testParser
+	partial   []byte          // stdout after the last newline
+	text      strings.Builder // output as go test prints it without -json
+	stderr    strings.Builder
+	cancelled bool
+	// finishing is set once the job's outcome is decided, so that it can
+	// no longer be cancelled while its run is stored
+	finishing bool
+	// coverProfile is where go test writes coverage, if it was asked to
+	coverProfile string
+	status       string
+	finished     time.Time
+}
+
+// stdoutWriter feeds go test's -json output to the job's parser line by
+// line. Other runners' output is kept as it is.
+type stdoutWriter struct{ j *job }
+
+func (w stdoutWriter) Write(p []byte) (int, error) {
+	w.j.mu.Lock()
+	defer w.j.mu.Unlock()
+	if w.j.parser == nil {
+		w.j.text.Write(p)
+		return len(p), nil
+	}
+	data := append(w.j.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		w.j.text.WriteString(w.j.parser.line(data[:i]))
+		data = data[i+1:]
+	}
+	w.j.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+type stderrWriter struct{ j *job }
+
+func (w stderrWriter) Write(p []byte) (int, error) {
+	w.j.mu.Lock()
+	defer w.j.mu.Unlock()
+	w.j.text.Write(p)
+	w.j.stderr.Write(p)
+	return len(p), nil
+}
+
+// jobSpec describes a test run.
+type jobSpec struct {
+	scope    string
+	runner   string // one of runnerNames; go test when empty
+	run      string // -run pattern, for reruns of single tests
+	rerunOf  uint   // run whose failed test a rerun retries
+	timeout  time.Duration
+	coverage bool     // write a cover profile and store it with the run
+	args     []string // further go test flags, such as -bench
+	packages []string // packages to test in place of scope, for the affected scope
+}
+
+// startJob records a running CI run and starts go test, or the runner
+// asked for, for it in the background.
+func (h *CIHandler) startJob(spec jobSpec) (*job, error) {
+	root := h.server.GetRepoRoot()
+	runner := cmp.Or(spec.runner, "go")
+	ciRun := models.CIRun{Scope: spec.scope, Runner: runner, Run: spec.run, RerunOf: spec.rerunOf, Status: "running", StartedAt: time.Now()}
+	ciRun.Commit, ciRun.Tree = treeState(context.Background(), root)
+	if err := h.server.GetDB().Create(&ciRun).Error; err != nil {
+		return nil, err
+	}
+
+	// The job outlives the request that started it
+	ctx, cancel := context.WithTimeout(context.Background(), spec.timeout+timeoutGrace)
+	j := &job{
+		runID:   ciRun.ID,
+		scope:   spec.scope,
+		timeout: spec.timeout,
+		started: ciRun.StartedAt,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		parser:  newTestParser(),
+		status:  "running",
+	}
+	if runner != "go" {
+		return h.startRunner(ctx, j, testRunners[runner], spec)
+	}
+
+	args := []string{"test", "-json", "-count=1", "-timeout=" + spec.timeout.String()}
+	if spec.run != "" {
+		args = append(args, "-run", spec.run)
+	}
+	args = append(args, spec.args...)
+	if spec.coverage {
+		profile, err := os.CreateTemp("", "project-manager-cover-*.out")
+		if err != nil {
+			cancel()
+			return nil, h.failStart(ciRun.ID, err)
+		}
+		profile.Close()
+		j.coverProfile = profile.Name()
+		args = append(args, "-coverprofile="+j.coverProfile)
+	}
+	if len(spec.packages) > 0 {
+		args = append(args, spec.packages...)
+	} else {
+		args = append(args, spec.scope)
+	}
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = root
+	cmd.Stdout = stdoutWriter{j}
+	cmd.Stderr = stderrWriter{j}
+	// Test binaries left behind by a killed go test may hold the pipes open
+	cmd.WaitDelay = 5 * time.Second
+	if err := cmd.Start(); err != nil {
+		cancel()
+		if j.coverProfile != "" {
+			os.Remove(j.coverProfile)
+		}
+		return nil, h.failStart(ciRun.ID, err)
+	}
+
+	h.track(ctx, j, cmd)
+	return j, nil
+}
+
+// startRunner starts a job for a runner other than go test.
+func (h *CIHandler) startRunner(ctx context.Context, j *job, runner testRunner, spec jobSpec) (*job, error) {
+	j.runner, j.parser = &runner, nil
+	report, err := os.CreateTemp("", "project-manager-report-*.xml")
+	if err != nil {
+		j.cancel()
+		return nil, h.failStart(j.runID, err)
+	}
+	report.Close()
+	j.report = report.Name()
+
+	args := runner.command(spec.scope, spec.run, j.report)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = h.server.GetRepoRoot()
+	if runner.env != nil {
+		cmd.Env = append(os.Environ(), runner.env(j.report)...)
+	}
+	// One writer shares a pipe, so that lines do not interleave
+	cmd.Stdout = stdoutWriter{j}
+	cmd.Stderr = cmd.Stdout
+	cmd.WaitDelay = 5 * time.Second
+	if err := cmd.Start(); err != nil {
+		j.cancel()
+		os.Remove(j.report)
+		return nil, h.failStart(j.runID, err)
+	}
+
+	h.track(ctx, j, cmd)
+	return j, nil
+}
+
+// track keeps a started job for ci_status and ci_output and finishes it
+// when its command exits.
+func (h *CIHandler) track(ctx context.Context, j *job, cmd *exec.Cmd) {
+	h.mu.Lock()
+	h.pruneJobs()
+	h.jobs[j.runID] = j
+	h.mu.Unlock()
+
+	go func() {
+		defer j.cancel()
+		h.finishJob(ctx, j, cmd.Wait())
+	}()
+}
+
+// failStart records a run that could not be started as an error.
+func (h *CIHandler) failStart(runID uint, err error) error {
+	h.server.GetDB().Model(&models.CIRun{ID: runID}).Updates(map[string]interface{}{
+		"status": "error", "finished_at": time.Now(), "output": err.Error(),
+	})
+	return fmt.Errorf("failed to start tests: %v", err)
+}
+
+// finishJob stores the outcome of a job once its command has exited. A
+// run that hit its timeout, either go test's own or the deadline of the
+// job, is a timeout rather than a failure.
+func (h *CIHandler) finishJob(ctx context.Context, j *job, err error) {
+	j.mu.Lock()
+	var results []models.CITestResult
+	var other, notRead string
+	if j.runner != nil {
+		if j.runner.results != nil {
+			var readErr error
+			if results, readErr = j.runner.results(j.report, j.text.String()); readErr != nil {
+				notRead = fmt.Sprintf("test results not read: %v\n", readErr)
+			}
+		}
+		os.Remove(j.report)
+	} else {
+		if len(j.partial) > 0 {
+			j.text.WriteString(j.parser.line(j.partial))
+			j.partial = nil
+		}
+		results, other = j.parser.finish()
+		// Storing the results sets their IDs while ci_status reads the parser's
+		results = slices.Clone(results)
+	}
+
+	var status string
+	var exitErr *exec.ExitError
+	switch {
+	case j.cancelled:
+		status = "cancelled"
+	case errors.Is(ctx.Err(), context.DeadlineExceeded) || strings.Contains(j.text.String(), "panic: test timed out after"):
+		status = "timeout"
+		if ctx.Err() != nil {
+			fmt.Fprintf(&j.text, "run stopped after %s\n", j.timeout+timeoutGrace)
+		}
+	case errors.As(err, &exitErr):
+		status = "fail"
+	case err != nil:
+		status = "error"
+		fmt.Fprintln(&j.stderr, err)
+		fmt.Fprintln(&j.text, err)
+	default:
+		status = "pass"
+	}
+	finished, output := time.Now(), other+j.stderr.String()
+	if j.runner != nil && len(results) == 0 && (status == "pass" || status == "fail") {
+		// Without results, the whole run stands for one package
+		results = []models.CITestResult{{Package: j.scope, Status: status, Output: j.text.String()}}
+		if j.scope == "" {
+			results[0].Package = "."
+		}
+		output = j.text.String()
+	}
+	output += notRead
+	j.finishing = true
+	j.mu.Unlock()
+
+	for i := range results {
+		results[i].RunID = j.runID
+	}
+	db := h.server.GetDB()
+	var storeErr error
+	if len(results) > 0 {
+		if err := db.CreateInBatches(results, 100).Error; err != nil {
+			storeErr = fmt.Errorf("failed to store test results: %v", err)
+		}
+	}
+	updates := map[string]interface{}{"finished_at": finished}
+	if j.coverProfile != "" {
+		if total, err := h.storeCoverage(j.runID, j.coverProfile); err == nil {
+			updates["coverage"] = total
+		} else {
+			output += fmt.Sprintf("coverage not recorded: %v\n", err)
+		}
+		os.Remove(j.coverProfile)
+	}
+	if storeErr != nil {
+		status, output = "error", output+storeErr.Error()+"\n"
+	}
+	updates["status"], updates["output"] = status, output
+	if err := db.Model(&models.CIRun{ID: j.runID}).Updates(updates).Error; err != nil {
+		// At least do not leave the run running
+		status, storeErr = "error", errors.Join(storeErr, fmt.Errorf("failed to store run: %v", err))
+		db.Model(&models.CIRun{ID: j.runID}).Updates(map[string]interface{}{"status": status, "finished_at": finished})
+	}
+
+	// The job is done once its run is stored
+	j.mu.Lock()
+	if storeErr != nil {
+		fmt.Fprintln(&j.stderr, storeErr)
+		fmt.Fprintln(&j.text, storeErr)
+	}
+	j.status, j.finished = status, finished
+	j.mu.Unlock()
+	close(j.done)
+}
+
+// wait waits for a job to finish, cancelling it if ctx is done first.
+func (j *job) wait(ctx context.Context) {
+	select {
+	case <-j.done:
+	case <-ctx.Done():
+		j.stop()
+		<-j.done
+	}
+}
+
+// pruneJobs drops all but the latest finished jobs. h.mu must be held.
+func (h *CIHandler) pruneJobs() {
+	var finished []uint
+	for id, j := range h.jobs {
+		select {
+		case <-j.done:
+			finished = append(finished, id)
+		default:
+		}
+	}
+	if len(finished) < keepFinishedJobs {
+		return
+	}
+	// IDs grow with time, so the oldest runs have the lowest IDs
+	slices.Sort(finished)
+	for _, id := range finished[:len(finished)-keepFinishedJobs+1] {
+		delete(h.jobs, id)
+	}
+}
+
+// stop cancels the job unless it has already finished, in which case it
+// returns the status it finished with, or is being stored.
+func (j *job) stop() (string, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status != "running" {
+		return j.status, false
+	}
+	if j.finishing {
+		return "finishing", false
+	}
+	j.cancelled = true
+	j.cancel()
+	return "", true
+}
+
+func (h *CIHandler) job(id uint) *job {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.jobs[id]
+}
+
+// timeout returns the run timeout asked for, or the configured default.
+func (h *CIHandler) timeout(value *string) (time.Duration, error) {
+	if value == nil || *value == "" {
+		return h.server.GetCITimeout(), nil
+	}
+	timeout, err := time.ParseDuration(*value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q (expected a duration such as 90s or 15m)", *value)
+	}
+	return timeout, nil
+}
+
+// CIStart starts the tests in the background and returns the job ID to
+// poll with ci_status and ci_output.
+func (h *CIHandler) CIStart(ctx context.Context, req *mcp.CallToolRequest, input types.CIStartInput) (*mcp.CallToolResult, types.CIStartOutput, error) {
+	spec, affected, err := h.testSpec(ctx, input.Runner, input.Scope, input.Base, input.Timeout, input.Coverage)
+	if err != nil {
+		return nil, types.CIStartOutput{}, err
+	}
+	output := types.CIStartOutput{Status: "running", Runner: spec.runner, Scope: spec.scope, Timeout: spec.timeout.String(), Affected: affected}
+	if affected != nil && len(affected.Packages) == 0 {
+		ciRun, err := h.recordPassedRun(spec)
+		if err != nil {
+			return nil, types.CIStartOutput{}, err
+		}
+		output.JobID, output.Status = ciRun.ID, ciRun.Status
+		return nil, output, nil
+	}
+
+	j, err := h.startJob(spec)
+	if err != nil {
+		return nil, types.CIStartOutput{}, err
+	}
+	output.JobID = j.runID
+	return nil, output, nil
+}
+
+// recordPassedRun records a run that had nothing to test as passed, so the
+// job ID ci_start returns for it can be polled like any other.
+func (h *CIHandler) recordPassedRun(spec jobSpec) (models.CIRun, error) {
+	now := time.Now()
+	ciRun := models.CIRun{Scope: spec.scope, Runner: cmp.Or(spec.runner, "go"), Status: "pass", StartedAt: now, FinishedAt: &now}
+	ciRun.Commit, ciRun.Tree = treeState(context.Background(), h.server.GetRepoRoot())
+	return ciRun, h.server.GetDB().Create(&ciRun).Error
+}
+
+// CIStatus reports where a job is: its status, how long it has run and the
+// tests that have passed, failed and been skipped so far.
+func (h *CIHandler) CIStatus(ctx context.Context, req *mcp.CallToolRequest, input types.CIStatusInput) (*mcp.CallToolResult, types.CIStatusOutput, error) {
+	if j := h.job(input.JobID); j != nil {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		output := types.CIStatusOutput{
+			JobID:     j.runID,
+			Scope:     j.scope,
+			Status:    j.status,
+			Done:      j.status != "running",
+			StartedAt: j.started.Format("2006-01-02 15:04:05"),
+			Timeout:   j.timeout.String(),
+		}
+		end := time.Now()
+		if output.Done {
+			end = j.finished
+			finishedAt := j.finished.Format("2006-01-02 15:04:05")
+			output.FinishedAt = &finishedAt
+		}
+		output.Elapsed = end.Sub(j.started).Seconds()
+		if j.parser != nil {
+			output.Passed, output.Failed, output.Skipped = testSummary(j.parser.results)
+		}
+		return nil, output, nil
+	}
+
+	ciRun, err := h.findRun(input.JobID)
+	if err != nil {
+		return nil, types.CIStatusOutput{}, err
+	}
+	output := types.CIStatusOutput{
+		JobID:     ciRun.ID,
+		Scope:     ciRun.Scope,
+		Status:    ciRun.Status,
+		Done:      ciRun.Status != "running",
+		StartedAt: ciRun.StartedAt.Format("2006-01-02 15:04:05"),
+	}
+	if ciRun.FinishedAt != nil {
+		finishedAt := ciRun.FinishedAt.Format("2006-01-02 15:04:05")
+		output.FinishedAt = &finishedAt
+		output.Elapsed = ciRun.FinishedAt.Sub(ciRun.StartedAt).Seconds()
+	}
+	output.Passed, output.Failed, output.Skipped = testSummary(ciRun.Results)
+	return nil, output, nil
+}
+
+// CIOutput returns a job's output from an offset, so that repeated calls
+// with the returned offset follow the run as it goes.
+func (h *CIHandler) CIOutput(ctx context.Context, req *mcp.CallToolRequest, input types.CIOutputInput) (*mcp.CallToolResult, types.CIOutputOutput, error) {
+	var text, status string
+	if j := h.job(input.JobID); j != nil {
+		j.mu.Lock()
+		text, status = j.text.String(), j.status
+		j.mu.Unlock()
+	} else {
+		ciRun, err := h.findRun(input.JobID)
+		if err != nil {
+			return nil, types.CIOutputOutput{}, err
+		}
+		// Only the stored output of each test is left of runs started
+		// before the server was
+		var b strings.Builder
+		for _, r := range ciRun.Results {
+			b.WriteString(r.Output)
+		}
+		b.WriteString(ciRun.Output)
+		text, status = b.String(), ciRun.Status
+	}
+
+	offset := 0
+	if input.Offset != nil {
+		offset = *input.Offset
+	}
+	if offset < 0 || offset > len(text) {
+		return nil, types.CIOutputOutput{}, fmt.Errorf("offset %d is outside the output (0-%d)", offset, len(text))
+	}
+	end := min(offset+maxOutputChunk, len(text))
+	return nil, types.CIOutputOutput{
+		JobID:  input.JobID,
+		Status: status,
+		Done:   status != "running" && end == len(text),
+		Output: text[offset:end],
+		Offset: end,
+	}, nil
+}
+
+// CICancel stops a running job; its run is recorded as cancelled.
+func (h *CIHandler) CICancel(ctx context.Context, req *mcp.CallToolRequest, input types.CICancelInput) (*mcp.CallToolResult, types.CICancelOutput, error) {
+	j := h.job(input.JobID)
+	if j == nil {
+		ciRun, err := h.findRun(input.JobID)
+		if err != nil {
+			return nil, types.CICancelOutput{}, err
+		}
+		return nil, types.CICancelOutput{}, fmt.Errorf("job %d is not running (%s)", ciRun.ID, ciRun.Status)
+	}
+
+	if status, ok := j.stop(); !ok {
+		return nil, types.CICancelOutput{}, fmt.Errorf("job %d is not running (%s)", j.runID, status)
+	}
+	select {
+	case <-j.done:
+	case <-ctx.Done():
+		return nil, types.CICancelOutput{}, ctx.Err()
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return nil, types.CICancelOutput{JobID: j.runID, Status: j.status}, nil
+}
+
+func (h *CIHandler) findRun(id uint) (models.CIRun, error) {
+	var ciRun models.CIRun
+	result := h.server.GetDB().Preload("Results", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).Where("id = ?", id).Limit(1).Find(&ciRun)
+	if result.Error != nil {
+		return models.CIRun{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.CIRun{}, fmt.Errorf("job %d not found", id)
+	}
+	return ciRun, nil
+}
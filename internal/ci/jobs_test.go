@@ -0,0 +1,203 @@
+package ci
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+const slowTest = "package slow\n\nimport (\n\t\"fmt\"\n\t\"testing\"\n\t\"time\"\n)\n\n" +
+	"func TestSlow(t *testing.T) {\n\tfmt.Println(\"started\")\n\ttime.Sleep(20 * time.Second)\n}\n"
+
+func TestCIHandler_Jobs(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewCIHandler(srv)
+	ctx := context.Background()
+
+	writeModule(t, tempDir, map[string]string{"slow/slow_test.go": slowTest})
+
+	scope := "./slow"
+	_, started, err := handler.CIStart(ctx, nil, types.CIStartInput{Scope: &scope})
+	if err != nil {
+		t.Fatalf("CIStart() unexpected error: %v", err)
+	}
+	if started.Status != "running" || started.Timeout != "10m0s" {
+		t.Errorf("CIStart() = %+v, want running with the default timeout", started)
+	}
+
+	// Follow the output until the test has started
+	var output strings.Builder
+	offset := 0
+	deadline := time.Now().Add(time.Minute)
+	for !strings.Contains(output.String(), "started") {
+		if time.Now().After(deadline) {
+			t.Fatalf("CIOutput() = %q after a minute, want the test's output", output.String())
+		}
+		_, chunk, err := handler.CIOutput(ctx, nil, types.CIOutputInput{JobID: started.JobID, Offset: &offset})
+		if err != nil {
+			t.Fatalf("CIOutput() unexpected error: %v", err)
+		}
+		if chunk.Done {
+			t.Fatalf("CIOutput() done with status %s before the test ran", chunk.Status)
+		}
+		output.WriteString(chunk.Output)
+		offset = chunk.Offset
+		time.Sleep(50 * time.Millisecond)
+	}
+	if !strings.Contains(output.String(), "=== RUN   TestSlow") {
+		t.Errorf("CIOutput() = %q, want go test's plain output", output.String())
+	}
+
+	_, status, err := handler.CIStatus(ctx, nil, types.CIStatusInput{JobID: started.JobID})
+	if err != nil {
+		t.Fatalf("CIStatus() unexpected error: %v", err)
+	}
+	if status.Status != "running" || status.Done || status.FinishedAt != nil {
+		t.Errorf("CIStatus() = %+v, want running", status)
+	}
+
+	_, cancelled, err := handler.CICancel(ctx, nil, types.CICancelInput{JobID: started.JobID})
+	if err != nil {
+		t.Fatalf("CICancel() unexpected error: %v", err)
+	}
+	if cancelled.Status != "cancelled" {
+		t.Errorf("CICancel() status = %s, want cancelled", cancelled.Status)
+	}
+	if _, _, err := handler.CICancel(ctx, nil, types.CICancelInput{JobID: started.JobID}); err == nil {
+		t.Errorf("CICancel() of a finished job succeeded, want an error")
+	}
+
+	_, status, err = handler.CIStatus(ctx, nil, types.CIStatusInput{JobID: started.JobID})
+	if err != nil {
+		t.Fatalf("CIStatus() unexpected error: %v", err)
+	}
+	if status.Status != "cancelled" || !status.Done || status.FinishedAt == nil {
+		t.Errorf("CIStatus() = %+v, want cancelled and done", status)
+	}
+	var ciRun models.CIRun
+	srv.GetDB().First(&ciRun, started.JobID)
+	if ciRun.Status != "cancelled" || ciRun.FinishedAt == nil {
+		t.Errorf("stored run = %s finished %v, want cancelled", ciRun.Status, ciRun.FinishedAt)
+	}
+
+	// A new handler finds the run in the database
+	handler = NewCIHandler(srv)
+	_, status, err = handler.CIStatus(ctx, nil, types.CIStatusInput{JobID: started.JobID})
+	if err != nil || status.Status != "cancelled" {
+		t.Errorf("CIStatus() from the database = %+v, %v, want cancelled", status, err)
+	}
+	_, rest, err := handler.CIOutput(ctx, nil, types.CIOutputInput{JobID: started.JobID})
+	if err != nil || !rest.Done || !strings.Contains(rest.Output, "started") {
+		t.Errorf("CIOutput() from the database = %+v, %v, want the stored output", rest, err)
+	}
+	if _, _, err := handler.CIStatus(ctx, nil, types.CIStatusInput{JobID: 999}); err == nil {
+		t.Errorf("CIStatus() of an unknown job succeeded, want an error")
+	}
+}
+
+func TestCIHandler_Timeout(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewCIHandler(srv)
+	ctx := context.Background()
+
+	writeModule(t, tempDir, map[string]string{"slow/slow_test.go": slowTest})
+
+	scope := "./slow"
+	invalid := "soon"
+	if _, _, err := handler.CIRunTests(ctx, nil, types.CIRunTestsInput{Scope: &scope, Timeout: &invalid}); err == nil {
+		t.Errorf("CIRunTests() with timeout %q succeeded, want an error", invalid)
+	}
+
+	timeout := "1s"
+	_, output, err := handler.CIRunTests(ctx, nil, types.CIRunTestsInput{Scope: &scope, Timeout: &timeout})
+	if err != nil {
+		t.Fatalf("CIRunTests() unexpected error: %v", err)
+	}
+	if output.Status != "timeout" {
+		t.Errorf("CIRunTests() status = %s, want timeout", output.Status)
+	}
+	if len(output.Failures) == 0 || output.Failures[0].Test != "TestSlow" {
+		t.Errorf("CIRunTests() failures = %+v, want the test that hung", output.Failures)
+	}
+
+	_, last, err := handler.CILastFailure(ctx, nil, types.CILastFailureInput{})
+	if err != nil {
+		t.Fatalf("CILastFailure() unexpected error: %v", err)
+	}
+	if last.Status != "timeout" || last.RunID != output.RunID {
+		t.Errorf("CILastFailure() = %s run %d, want the timed out run %d", last.Status, last.RunID, output.RunID)
+	}
+}
+
+func TestJobStop(t *testing.T) {
+	cancelled := false
+	j := &job{status: "running", cancel: func() { cancelled = true }}
+	j.finishing = true
+	if status, ok := j.stop(); ok || status != "finishing" || cancelled {
+		t.Errorf("stop() of a finishing job = %s, %v, want it left to finish", status, ok)
+	}
+
+	j.finishing = false
+	if _, ok := j.stop(); !ok || !cancelled || !j.cancelled {
+		t.Errorf("stop() of a running job = %v, want it cancelled", ok)
+	}
+}
+
+func TestCIHandler_FinishJobStoreError(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewCIHandler(srv)
+	ciRun := models.CIRun{Status: "running"}
+	if err := srv.GetDB().Create(&ciRun).Error; err != nil {
+		t.Fatalf("Failed to create run: %v", err)
+	}
+	if err := srv.GetDB().Migrator().DropTable(&models.CITestResult{}); err != nil {
+		t.Fatalf("Failed to drop results: %v", err)
+	}
+
+	j := &job{runID: ciRun.ID, status: "running", done: make(chan struct{}), runner: &testRunner{
+		results: func(report, output string) ([]models.CITestResult, error) {
+			return []models.CITestResult{{Package: "p", Status: "pass"}}, errors.New("truncated report")
+		},
+	}}
+	handler.finishJob(context.Background(), j, nil)
+
+	srv.GetDB().First(&ciRun, ciRun.ID)
+	if j.status != "error" || ciRun.Status != "error" || ciRun.FinishedAt == nil {
+		t.Errorf("finishJob() status = %s, stored %s, want error", j.status, ciRun.Status)
+	}
+	for _, want := range []string{"test results not read: truncated report", "failed to store test results"} {
+		if !strings.Contains(ciRun.Output, want) {
+			t.Errorf("stored output = %q, want %q", ciRun.Output, want)
+		}
+	}
+	if !strings.Contains(j.stderr.String(), "failed to store test results") {
+		t.Errorf("job stderr = %q, want the store error", j.stderr.String())
+	}
+}
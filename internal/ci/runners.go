@@ -0,0 +1,330 @@
+package ci
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/setup"
+)
+
+// testRunner runs the tests of a project that go test does not. Its
+// results are read once the run ends, from a JUnit report where the tool
+// writes one, into the same package and test rows as go test's.
+type testRunner struct {
+	// command returns the command line for a run of scope, limited to the
+	// tests matching run, that writes its report to report
+	command func(scope, run, report string) []string
+	// env is added to the environment of the command
+	env func(report string) []string
+	// results reads the outcome of the run from the report or the output
+	results func(report, output string) ([]models.CITestResult, error)
+}
+
+// runnerNames lists the runners in the order documented.
+var runnerNames = []string{"go", "npm", "vitest", "jest", "pytest", "cargo"}
+
+var testRunners = map[string]testRunner{
+	// npm test runs whatever the package's test script is, so only its
+	// exit status is known
+	"npm": {
+		command: func(scope, run, report string) []string {
+			if scope == "" {
+				return []string{"npm", "test"}
+			}
+			return append([]string{"npm", "test", "--"}, strings.Fields(scope)...)
+		},
+	},
+	"vitest": {
+		command: func(scope, run, report string) []string {
+			args := []string{"npx", "vitest", "run", "--reporter=default", "--reporter=junit", "--outputFile.junit=" + report}
+			if run != "" {
+				args = append(args, "-t", run)
+			}
+			return append(args, strings.Fields(scope)...)
+		},
+		results: junitResults,
+	},
+	// jest writes JUnit through the jest-junit reporter, with the test
+	// file as the class name
+	"jest": {
+		command: func(scope, run, report string) []string {
+			args := []string{"npx", "jest", "--ci", "--reporters=default", "--reporters=jest-junit"}
+			if run != "" {
+				args = append(args, "-t", run)
+			}
+			return append(args, strings.Fields(scope)...)
+		},
+		env: func(report string) []string {
+			return []string{"JEST_JUNIT_OUTPUT_FILE=" + report, "JEST_JUNIT_CLASSNAME={filepath}", "JEST_JUNIT_TITLE={classname} {title}"}
+		},
+		results: junitResults,
+	},
+	"pytest": {
+		command: func(scope, run, report string) []string {
+			args := []string{"python3", "-m", "pytest"}
+			if _, err := exec.LookPath("pytest"); err == nil {
+				args = []string{"pytest"}
+			}
+			args = append(args, "--junitxml="+report)
+			if run != "" {
+				args = append(args, "-k", run)
+			}
+			return append(args, strings.Fields(scope)...)
+		},
+		results: junitResults,
+	},
+	// cargo test has no stable machine-readable output; libtest's lines
+	// are read instead
+	"cargo": {
+		command: func(scope, run, report string) []string {
+			args := []string{"cargo", "test", "--no-fail-fast"}
+			for _, pkg := range strings.Fields(scope) {
+				args = append(args, "--package", pkg)
+			}
+			if run != "" {
+				args = append(args, run)
+			}
+			return args
+		},
+		results: func(report, output string) ([]models.CITestResult, error) {
+			return parseCargoTest(output), nil
+		},
+	},
+}
+
+// testRunner returns the runner for a run: the one asked for, else the
+// configured one, else the one for the type of project in the repository.
+func (h *CIHandler) testRunner(name string) (string, error) {
+	if name == "" {
+		name = h.server.GetCIRunner()
+	}
+	if name == "" {
+		name = detectRunner(h.server.GetRepoRoot())
+	}
+	if !slices.Contains(runnerNames, name) {
+		return "", fmt.Errorf("unknown runner %q (expected one of %s)", name, strings.Join(runnerNames, ", "))
+	}
+	return name, nil
+}
+
+// detectRunner picks the runner for the project in root. Node projects use
+// vitest or jest when they depend on them, jest only with jest-junit, and
+// npm test otherwise.
+func detectRunner(root string) string {
+	switch setup.DetectProjectType(root) {
+	case "nodejs":
+		data, err := os.ReadFile(filepath.Join(root, "package.json"))
+		if err != nil {
+			return "npm"
+		}
+		var pkg struct {
+			Dependencies    map[string]string `json:"dependencies"`
+			DevDependencies map[string]string `json:"devDependencies"`
+		}
+		json.Unmarshal(data, &pkg)
+		has := func(name string) bool {
+			_, dep := pkg.Dependencies[name]
+			_, dev := pkg.DevDependencies[name]
+			return dep || dev
+		}
+		switch {
+		case has("vitest"):
+			return "vitest"
+		case has("jest") && has("jest-junit"):
+			return "jest"
+		}
+		return "npm"
+	case "python":
+		return "pytest"
+	case "rust":
+		return "cargo"
+	}
+	return "go"
+}
+
+// junitSuite is a <testsuites> or <testsuite> element of a JUnit report;
+// suites may nest.
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Error     *junitMessage `xml:"error"`
+	Skipped   *junitMessage `xml:"skipped"`
+	SystemOut string        `xml:"system-out"`
+	SystemErr string        `xml:"system-err"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func junitResults(report, output string) ([]models.CITestResult, error) {
+	f, err := os.Open(report)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseJUnit(f)
+}
+
+// parseJUnit reads a JUnit XML report into one result per test case and
+// one per package, which is the case's class name: the module for
+// pytest, the test file for vitest and jest.
+func parseJUnit(r io.Reader) ([]models.CITestResult, error) {
+	var root junitSuite
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("failed to read JUnit report: %v", err)
+	}
+
+	var tests []models.CITestResult
+	var walk func(s junitSuite)
+	walk = func(s junitSuite) {
+		for _, c := range s.Cases {
+			t := models.CITestResult{Package: cmp.Or(c.Classname, c.File, s.Name), Test: c.Name, Status: "pass"}
+			t.Elapsed, _ = strconv.ParseFloat(strings.ReplaceAll(c.Time, ",", ""), 64)
+			var output strings.Builder
+			for _, m := range []*junitMessage{c.Failure, c.Error, c.Skipped} {
+				if m == nil {
+					continue
+				}
+				if m != c.Skipped {
+					t.Status = "fail"
+				} else if t.Status == "pass" {
+					t.Status = "skip"
+				}
+				for _, text := range []string{m.Message, strings.TrimSpace(m.Text)} {
+					if text != "" && !strings.Contains(output.String(), text) {
+						output.WriteString(text + "\n")
+					}
+				}
+			}
+			for _, text := range []string{c.SystemOut, c.SystemErr} {
+				if text = strings.TrimSpace(text); text != "" {
+					output.WriteString(text + "\n")
+				}
+			}
+			t.Output = output.String()
+			tests = append(tests, t)
+		}
+		for _, child := range s.Suites {
+			walk(child)
+		}
+	}
+	walk(root)
+	return withPackages(tests), nil
+}
+
+// withPackages puts a row for each package before its tests: failed if a
+// test failed, skipped if all were skipped, taking as long as its tests.
+func withPackages(tests []models.CITestResult) []models.CITestResult {
+	var order []string
+	packages := make(map[string]*models.CITestResult)
+	byPackage := make(map[string][]models.CITestResult)
+	for _, t := range tests {
+		p, ok := packages[t.Package]
+		if !ok {
+			p = &models.CITestResult{Package: t.Package, Status: "skip"}
+			packages[t.Package] = p
+			order = append(order, t.Package)
+		}
+		p.Elapsed += t.Elapsed
+		switch {
+		case t.Status == "fail":
+			p.Status = "fail"
+		case t.Status == "pass" && p.Status == "skip":
+			p.Status = "pass"
+		}
+		byPackage[t.Package] = append(byPackage[t.Package], t)
+	}
+	var results []models.CITestResult
+	for _, pkg := range order {
+		results = append(results, *packages[pkg])
+		results = append(results, byPackage[pkg]...)
+	}
+	return results
+}
+
+var (
+	// cargoTarget matches the header of a test binary,
+	// "Running unittests src/lib.rs (target/debug/deps/demo-0123abcd)",
+	// or of a crate's doc tests, "Doc-tests demo".
+	cargoTarget = regexp.MustCompile(`^\s*(?:Running (?:unittests )?(\S+) \((?:.*[/\\])?(.+?)-[0-9a-f]+(?:\.exe)?\)|Doc-tests (\S+))$`)
+	// cargoTest matches a test outcome, "test tests::works ... ok".
+	cargoTest = regexp.MustCompile(`^test (.+) \.\.\. (ok|FAILED|ignored)`)
+	// cargoCaptured starts the captured output of a failed test.
+	cargoCaptured = regexp.MustCompile(`^---- (.+) std(?:out|err) ----$`)
+	// cargoSummary ends the tests of a binary.
+	cargoSummary = regexp.MustCompile(`^test result: .* finished in ([0-9.]+)s`)
+)
+
+// parseCargoTest reads cargo test's output into results. Each test
+// binary is a package named after the binary and its source, or the
+// crate for doc tests.
+func parseCargoTest(output string) []models.CITestResult {
+	var tests []models.CITestResult
+	elapsed := make(map[string]float64)
+	pkg, captured := "", -1
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := cargoTarget.FindStringSubmatch(line); m != nil {
+			pkg, captured = m[2]+" "+m[1], -1
+			if m[3] != "" {
+				pkg = m[3] + " doc-tests"
+			}
+			continue
+		}
+		if m := cargoTest.FindStringSubmatch(line); m != nil {
+			status := map[string]string{"ok": "pass", "FAILED": "fail", "ignored": "skip"}[m[2]]
+			tests = append(tests, models.CITestResult{Package: pkg, Test: m[1], Status: status})
+			continue
+		}
+		if m := cargoCaptured.FindStringSubmatch(line); m != nil {
+			captured = slices.IndexFunc(tests, func(t models.CITestResult) bool {
+				return t.Package == pkg && t.Test == m[1]
+			})
+			continue
+		}
+		if m := cargoSummary.FindStringSubmatch(line); m != nil {
+			elapsed[pkg], _ = strconv.ParseFloat(m[1], 64)
+			captured = -1
+			continue
+		}
+		if line == "failures:" {
+			captured = -1
+			continue
+		}
+		if captured >= 0 {
+			tests[captured].Output += line + "\n"
+		}
+	}
+	results := withPackages(tests)
+	for i, r := range results {
+		if r.Test == "" {
+			results[i].Elapsed = elapsed[r.Package]
+		}
+	}
+	return results
+}
@@ -0,0 +1,213 @@
+package ci
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestCIHandler_Runners(t *testing.T) {
+	tests := []struct {
+		name    string
+		tool    string
+		files   map[string]string
+		passed  int
+		failed  int
+		skipped int
+		want    []string // packages
+	}{
+		{
+			name: "npm",
+			tool: "npm",
+			files: map[string]string{
+				"package.json": `{"name": "demo", "scripts": {"test": "node check.js"}}`,
+				"check.js":     "console.log('checking'); process.exit(1)\n",
+			},
+			want: []string{"."},
+		},
+		{
+			name: "cargo",
+			tool: "cargo",
+			files: map[string]string{
+				"Cargo.toml": "[package]\nname = \"demo\"\nversion = \"0.1.0\"\nedition = \"2021\"\n",
+				"src/lib.rs": "pub fn add(a: i32, b: i32) -> i32 { a + b }\n\n#[cfg(test)]\nmod tests {\n" +
+					"    #[test]\n    fn works() { assert_eq!(super::add(1, 2), 3); }\n" +
+					"    #[test]\n    fn fails() { assert_eq!(super::add(1, 2), 4); }\n" +
+					"    #[test]\n    #[ignore]\n    fn later() {}\n}\n",
+			},
+			passed: 1, failed: 1, skipped: 1,
+			want: []string{"demo src/lib.rs"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := exec.LookPath(tt.tool); err != nil {
+				t.Skipf("%s not available", tt.tool)
+			}
+
+			// Setup
+			tempDir := t.TempDir()
+			srv, err := server.NewServer(tempDir)
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+			defer srv.Close()
+
+			handler := NewCIHandler(srv)
+			for name, content := range tt.files {
+				path := filepath.Join(tempDir, filepath.FromSlash(name))
+				os.MkdirAll(filepath.Dir(path), 0755)
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to write %s: %v", name, err)
+				}
+			}
+
+			_, output, err := handler.CIRunTests(context.Background(), nil, types.CIRunTestsInput{})
+			if err != nil {
+				t.Fatalf("CIRunTests() unexpected error: %v", err)
+			}
+			if output.Runner != tt.name || output.Status != "fail" {
+				t.Fatalf("CIRunTests() = %+v, want a failed %s run", output, tt.name)
+			}
+			if output.Passed != tt.passed || output.Failed != tt.failed || output.Skipped != tt.skipped {
+				t.Errorf("CIRunTests() = %d passed, %d failed, %d skipped, want %d, %d, %d",
+					output.Passed, output.Failed, output.Skipped, tt.passed, tt.failed, tt.skipped)
+			}
+			var packages []string
+			for _, p := range output.Packages {
+				packages = append(packages, p.Package)
+			}
+			if !reflect.DeepEqual(packages, tt.want) {
+				t.Errorf("CIRunTests() packages = %v, want %v", packages, tt.want)
+			}
+			if len(output.Failures) != 1 || output.Failures[0].Output == "" {
+				t.Errorf("CIRunTests() failures = %+v, want one with output", output.Failures)
+			}
+		})
+	}
+}
+
+func TestCIHandler_RunnerChoice(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewCIHandler(srv)
+	os.WriteFile(filepath.Join(tempDir, "pyproject.toml"), nil, 0644)
+
+	if spec, _, err := handler.testSpec(context.Background(), "", nil, "", nil, false); err != nil || spec.runner != "pytest" || spec.scope != "" {
+		t.Errorf("testSpec() = %+v, %v, want pytest over every test", spec, err)
+	}
+	t.Setenv("MCP_CI_RUNNER", "cargo")
+	if spec, _, err := handler.testSpec(context.Background(), "", nil, "", nil, false); err != nil || spec.runner != "cargo" {
+		t.Errorf("testSpec() with MCP_CI_RUNNER = %+v, %v, want cargo", spec, err)
+	}
+	if spec, _, err := handler.testSpec(context.Background(), "go", nil, "", nil, true); err != nil || spec.runner != "go" || spec.scope != defaultScope {
+		t.Errorf("testSpec(go) = %+v, %v, want go test of the default scope", spec, err)
+	}
+	if _, _, err := handler.testSpec(context.Background(), "pytest", nil, "", nil, true); err == nil {
+		t.Errorf("testSpec(pytest) with coverage succeeded, want an error")
+	}
+	if _, _, err := handler.testSpec(context.Background(), "maven", nil, "", nil, false); err == nil {
+		t.Errorf("testSpec(maven) succeeded, want an error")
+	}
+	for _, scope := range []string{"--exec=rm", "tests/a.py -x"} {
+		if _, _, err := handler.testSpec(context.Background(), "pytest", &scope, "", nil, false); err == nil {
+			t.Errorf("testSpec() with scope %q succeeded, want an error", scope)
+		}
+	}
+}
+
+func TestDetectRunner(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"go", map[string]string{"go.mod": "module m\n", "package.json": "{}"}, "go"},
+		{"vitest", map[string]string{"package.json": `{"devDependencies": {"vitest": "^2.0.0"}}`}, "vitest"},
+		{"jest", map[string]string{"package.json": `{"devDependencies": {"jest": "^29.0.0", "jest-junit": "^16.0.0"}}`}, "jest"},
+		{"jest without a JUnit reporter", map[string]string{"package.json": `{"devDependencies": {"jest": "^29.0.0"}}`}, "npm"},
+		{"npm", map[string]string{"package.json": `{"scripts": {"test": "mocha"}}`}, "npm"},
+		{"python", map[string]string{"requirements.txt": ""}, "pytest"},
+		{"rust", map[string]string{"Cargo.toml": ""}, "cargo"},
+		{"generic", nil, "go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+			}
+			if got := detectRunner(dir); got != tt.want {
+				t.Errorf("detectRunner() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJUnit(t *testing.T) {
+	// pytest's report: one suite, modules as class names
+	report := `<?xml version="1.0" encoding="utf-8"?>
+<testsuites><testsuite name="pytest" errors="0" failures="1" skipped="1" tests="3" time="0.05">
+<testcase classname="tests.test_a" name="test_ok" time="0.001" />
+<testcase classname="tests.test_a" name="test_bad" time="0.002"><failure message="assert 1 == 2">def test_bad():
+&gt;       assert 1 == 2</failure><system-out>printed</system-out></testcase>
+<testcase classname="tests.test_b" name="test_later" time="0.000"><skipped type="pytest.skip" message="not yet">skipped</skipped></testcase>
+</testsuite></testsuites>`
+	results, err := parseJUnit(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("parseJUnit() unexpected error: %v", err)
+	}
+	want := []models.CITestResult{
+		{Package: "tests.test_a", Status: "fail", Elapsed: 0.003},
+		{Package: "tests.test_a", Test: "test_ok", Status: "pass", Elapsed: 0.001},
+		{Package: "tests.test_a", Test: "test_bad", Status: "fail", Elapsed: 0.002, Output: "assert 1 == 2\ndef test_bad():\n>       assert 1 == 2\nprinted\n"},
+		{Package: "tests.test_b", Status: "skip"},
+		{Package: "tests.test_b", Test: "test_later", Status: "skip", Output: "not yet\nskipped\n"},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("parseJUnit() = %+v, want %+v", results, want)
+	}
+
+	// A bare testsuite whose cases have no class name
+	results, err = parseJUnit(strings.NewReader(`<testsuite name="src/a.test.ts"><testcase name="adds" time="1,250.5"/></testsuite>`))
+	if err != nil || len(results) != 2 || results[1].Package != "src/a.test.ts" || results[1].Elapsed != 1250.5 {
+		t.Errorf("parseJUnit() = %+v, %v, want the case under its suite", results, err)
+	}
+
+	if _, err := parseJUnit(strings.NewReader("")); err == nil {
+		t.Errorf("parseJUnit() of an empty report succeeded, want an error")
+	}
+}
+
+func TestParseCargoTest(t *testing.T) {
+	output := "     Running unittests src/lib.rs (target/debug/deps/demo-ed92602a0e8eaf5e)\n\nrunning 2 tests\n" +
+		"test tests::fails ... FAILED\ntest tests::works ... ok\n\nfailures:\n\n" +
+		"---- tests::fails stdout ----\nsome output\nthread 'tests::fails' panicked at src/lib.rs:13:9\n\n" +
+		"failures:\n    tests::fails\n\ntest result: FAILED. 1 passed; 1 failed; 0 ignored; 0 measured; 0 filtered out; finished in 0.02s\n\n" +
+		"     Running tests/it.rs (target/debug/deps/it-9be4081cb8e975c0)\n\nrunning 1 test\n" +
+		"test integration ... ignored, slow\n\ntest result: ok. 0 passed; 0 failed; 1 ignored; 0 measured; 0 filtered out; finished in 0.00s\n"
+	want := []models.CITestResult{
+		{Package: "demo src/lib.rs", Status: "fail", Elapsed: 0.02},
+		{Package: "demo src/lib.rs", Test: "tests::fails", Status: "fail", Output: "some output\nthread 'tests::fails' panicked at src/lib.rs:13:9\n\n"},
+		{Package: "demo src/lib.rs", Test: "tests::works", Status: "pass"},
+		{Package: "it tests/it.rs", Status: "skip"},
+		{Package: "it tests/it.rs", Test: "integration", Status: "skip"},
+	}
+	if got := parseCargoTest(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCargoTest() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,149 @@
+package ci
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/thornzero/project-manager/internal/models"
+)
+
+// testEvent is one line of `go test -json` output, as described by
+// `go doc test2json`.
+type testEvent struct {
+	Time       time.Time
+	Action     string
+	Package    string
+	Test       string
+	Elapsed    float64
+	Output     string
+	ImportPath string // build-output and build-fail events, Go 1.24+
+}
+
+// parseTestJSON reads a `go test -json` event stream into one result per
+// package and per test, in the order they started. Lines that are not
+// events, such as build errors from older toolchains, are returned as
+// other output. Tests that never finished, because the binary panicked or
+// timed out, count as failed.
+func parseTestJSON(r io.Reader) ([]models.CITestResult, string) {
+	p := newTestParser()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		p.line(scanner.Bytes())
+	}
+	return p.finish()
+}
+
+// testParser collects results from a `go test -json` event stream one line
+// at a time, so that a run can be followed while it is going.
+type testParser struct {
+	results []models.CITestResult
+	index   map[[2]string]int
+	other   strings.Builder
+}
+
+func newTestParser() *testParser {
+	return &testParser{index: make(map[[2]string]int)}
+}
+
+func (p *testParser) result(pkg, test string) *models.CITestResult {
+	key := [2]string{pkg, test}
+	i, ok := p.index[key]
+	if !ok {
+		i = len(p.results)
+		p.index[key] = i
+		p.results = append(p.results, models.CITestResult{Package: pkg, Test: test})
+	}
+	return &p.results[i]
+}
+
+// line reads one line of the stream and returns the text it adds to the
+// run's output as go test would have printed it without -json.
+func (p *testParser) line(line []byte) string {
+	var event testEvent
+	if len(line) == 0 || line[0] != '{' || json.Unmarshal(line, &event) != nil {
+		p.other.Write(line)
+		p.other.WriteByte('\n')
+		return string(line) + "\n"
+	}
+
+	switch event.Action {
+	case "build-output":
+		// The import path reads "pkg [pkg.test]" for test binaries
+		pkg, _, _ := strings.Cut(event.ImportPath, " ")
+		p.result(pkg, "").Output += event.Output
+		return event.Output
+	case "build-fail":
+		pkg, _, _ := strings.Cut(event.ImportPath, " ")
+		p.result(pkg, "").Status = "fail"
+	case "start", "run", "pause", "cont", "bench":
+		p.result(event.Package, event.Test)
+	case "output":
+		r := p.result(event.Package, event.Test)
+		r.Output += event.Output
+		return event.Output
+	case "pass", "fail", "skip":
+		r := p.result(event.Package, event.Test)
+		r.Status = event.Action
+		r.Elapsed = event.Elapsed
+	}
+	return ""
+}
+
+// finish marks the tests that never finished as failed and returns the
+// results with the other output. Benchmarks report no result of their
+// own, so they pass with their package.
+func (p *testParser) finish() ([]models.CITestResult, string) {
+	for i, r := range p.results {
+		if r.Status != "" {
+			continue
+		}
+		p.results[i].Status = "fail"
+		if pkg, ok := p.index[[2]string{r.Package, ""}]; ok && strings.HasPrefix(r.Test, "Benchmark") && p.results[pkg].Status == "pass" {
+			p.results[i].Status = "pass"
+		}
+	}
+	return p.results, p.other.String()
+}
+
+// testSummary counts the tests in a run by status; package rows are not
+// counted.
+func testSummary(results []models.CITestResult) (passed, failed, skipped int) {
+	for _, r := range results {
+		if r.Test == "" {
+			continue
+		}
+		switch r.Status {
+		case "pass":
+			passed++
+		case "fail":
+			failed++
+		case "skip":
+			skipped++
+		}
+	}
+	return passed, failed, skipped
+}
+
+// failedResults returns the failing tests followed by the packages that
+// failed without a failing test, because they did not build or crashed
+// outside of a test.
+func failedResults(results []models.CITestResult) []models.CITestResult {
+	var failed []models.CITestResult
+	failedTests := make(map[string]bool)
+	for _, r := range results {
+		if r.Test != "" && r.Status == "fail" {
+			failedTests[r.Package] = true
+			failed = append(failed, r)
+		}
+	}
+	for _, r := range results {
+		if r.Test == "" && r.Status == "fail" && !failedTests[r.Package] {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
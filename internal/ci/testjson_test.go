@@ -0,0 +1,65 @@
+package ci
+
+import (
+	"strings"
+	"testing"
+)
+
+const testJSONStream = `{"Action":"start","Package":"example.com/a"}
+{"Action":"run","Package":"example.com/a","Test":"TestOK"}
+{"Action":"output","Package":"example.com/a","Test":"TestOK","Output":"=== RUN   TestOK\n"}
+{"Action":"output","Package":"example.com/a","Test":"TestOK","Output":"--- PASS: TestOK (0.00s)\n"}
+{"Action":"pass","Package":"example.com/a","Test":"TestOK","Elapsed":0.01}
+{"Action":"run","Package":"example.com/a","Test":"TestBad"}
+{"Action":"run","Package":"example.com/a","Test":"TestBad/sub"}
+{"Action":"output","Package":"example.com/a","Test":"TestBad/sub","Output":"    a_test.go:4: boom\n"}
+{"Action":"fail","Package":"example.com/a","Test":"TestBad/sub","Elapsed":0}
+{"Action":"fail","Package":"example.com/a","Test":"TestBad","Elapsed":0.02}
+{"Action":"run","Package":"example.com/a","Test":"TestSkip"}
+{"Action":"skip","Package":"example.com/a","Test":"TestSkip","Elapsed":0}
+{"Action":"run","Package":"example.com/a","Test":"TestHang"}
+{"Action":"output","Package":"example.com/a","Output":"FAIL\texample.com/a\t0.003s\n"}
+{"Action":"fail","Package":"example.com/a","Elapsed":0.003}
+{"ImportPath":"example.com/b [example.com/b.test]","Action":"build-output","Output":"b/b.go:2:23: cannot use \"x\" as int value\n"}
+{"ImportPath":"example.com/b [example.com/b.test]","Action":"build-fail"}
+{"Action":"start","Package":"example.com/b"}
+{"Action":"fail","Package":"example.com/b","Elapsed":0,"FailedBuild":"example.com/b [example.com/b.test]"}
+{"Action":"start","Package":"example.com/c"}
+{"Action":"skip","Package":"example.com/c","Elapsed":0}
+go: warning: "./nothing/..." matched no packages
+`
+
+func TestParseTestJSON(t *testing.T) {
+	results, other := parseTestJSON(strings.NewReader(testJSONStream))
+
+	want := []struct {
+		pkg, test, status, output string
+		elapsed                   float64
+	}{
+		{"example.com/a", "", "fail", "FAIL\texample.com/a\t0.003s\n", 0.003},
+		{"example.com/a", "TestOK", "pass", "=== RUN   TestOK\n--- PASS: TestOK (0.00s)\n", 0.01},
+		{"example.com/a", "TestBad", "fail", "", 0.02},
+		{"example.com/a", "TestBad/sub", "fail", "    a_test.go:4: boom\n", 0},
+		{"example.com/a", "TestSkip", "skip", "", 0},
+		{"example.com/a", "TestHang", "fail", "", 0},
+		{"example.com/b", "", "fail", "b/b.go:2:23: cannot use \"x\" as int value\n", 0},
+		{"example.com/c", "", "skip", "", 0},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("parseTestJSON() = %d results, want %d: %+v", len(results), len(want), results)
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Package != w.pkg || r.Test != w.test || r.Status != w.status || r.Output != w.output || r.Elapsed != w.elapsed {
+			t.Errorf("result %d = %+v, want %+v", i, r, w)
+		}
+	}
+	if other != "go: warning: \"./nothing/...\" matched no packages\n" {
+		t.Errorf("parseTestJSON() other output = %q", other)
+	}
+
+	passed, failed, skipped := testSummary(results)
+	if passed != 1 || failed != 3 || skipped != 1 {
+		t.Errorf("testSummary() = %d, %d, %d, want 1, 3, 1", passed, failed, skipped)
+	}
+}
@@ -0,0 +1,414 @@
+package ci
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
+)
+
+// vetTools are the tools ci_vet knows, in the order they run. go vet
+// always runs by default; the linters when they are installed.
+var vetTools = []string{"vet", "staticcheck", "golangci-lint"}
+
+var (
+	// compileError matches an error loading a package as the go command
+	// prints it, e.g. "vet: a/a.go:3:23: cannot use ...".
+	compileError = regexp.MustCompile(`^(?:vet: )?(\S+\.go):(\d+):(\d+): (.+)$`)
+	// vetPosition splits a go vet position, "file:line:column".
+	vetPosition = regexp.MustCompile(`^(.+?):(\d+)(?::(\d+))?$`)
+)
+
+// CIVet runs go vet and the installed linters over a scope, stores their
+// findings with the run and reports the ones that are new since a
+// baseline run. Findings are matched by tool, analyzer, file and message,
+// without line numbers, so that edits elsewhere in a file do not make old
+// findings new.
+func (h *CIHandler) CIVet(ctx context.Context, req *mcp.CallToolRequest, input types.CIVetInput) (*mcp.CallToolResult, types.CIVetOutput, error) {
+	db := h.server.GetDB()
+	var vetRun models.CIVetRun
+	if input.RunID != 0 {
+		result := db.Preload("Diagnostics", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).Limit(1).Find(&vetRun, input.RunID)
+		if result.Error != nil {
+			return nil, types.CIVetOutput{}, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, types.CIVetOutput{}, fmt.Errorf("vet run %d not found", input.RunID)
+		}
+	} else {
+		run, err := h.runVet(ctx, input)
+		if err != nil {
+			return nil, types.CIVetOutput{}, err
+		}
+		vetRun = run
+	}
+
+	var baseline models.CIVetRun
+	query := db.Preload("Diagnostics")
+	switch {
+	case input.Baseline != 0:
+		query = query.Where("id = ?", input.Baseline)
+	case input.BaselineCommit != "":
+		query = query.Where("`commit` LIKE ?", input.BaselineCommit+"%")
+	default:
+		// A run a tool failed in would make everything it missed look new
+		query = query.Where("scope = ? AND status <> 'error' AND id <> ? AND started_at <= ?", vetRun.Scope, vetRun.ID, vetRun.StartedAt)
+	}
+	result := query.Order("started_at DESC, id DESC").Limit(1).Find(&baseline)
+	if result.Error != nil {
+		return nil, types.CIVetOutput{}, result.Error
+	}
+	if result.RowsAffected == 0 && (input.Baseline != 0 || input.BaselineCommit != "") {
+		return nil, types.CIVetOutput{}, fmt.Errorf("no vet run found for the baseline")
+	}
+
+	output := types.CIVetOutput{
+		RunID:  vetRun.ID,
+		Status: vetRun.Status,
+		Commit: vetRun.Commit,
+		Tools:  strings.Split(vetRun.Tools, ","),
+		Total:  len(vetRun.Diagnostics),
+		New:    []types.CIDiagnostic{},
+		Output: vetRun.Output,
+	}
+	if baseline.ID != 0 {
+		output.BaselineRunID = baseline.ID
+		output.BaselineCommit = baseline.Commit
+	}
+
+	// Each finding in the baseline accounts for one identical finding now
+	known := make(map[[4]string]int)
+	for _, d := range baseline.Diagnostics {
+		known[diagnosticKey(d)]++
+	}
+	for _, d := range vetRun.Diagnostics {
+		if input.All {
+			output.Diagnostics = append(output.Diagnostics, diagnosticOutput(d))
+		}
+		if key := diagnosticKey(d); known[key] > 0 {
+			known[key]--
+			continue
+		}
+		output.New = append(output.New, diagnosticOutput(d))
+	}
+	for _, n := range known {
+		output.Fixed += n
+	}
+	return nil, output, nil
+}
+
+// runVet runs the tools asked for and stores the run with its findings.
+func (h *CIHandler) runVet(ctx context.Context, input types.CIVetInput) (models.CIVetRun, error) {
+	scope := defaultScope
+	if input.Scope != nil && *input.Scope != "" {
+		scope = *input.Scope
+	}
+	if strings.HasPrefix(scope, "-") {
+		return models.CIVetRun{}, fmt.Errorf("invalid scope %q", scope)
+	}
+	timeout, err := h.timeout(input.Timeout)
+	if err != nil {
+		return models.CIVetRun{}, err
+	}
+	tools, err := vetToolList(input.Tools)
+	if err != nil {
+		return models.CIVetRun{}, err
+	}
+
+	root := h.server.GetRepoRoot()
+	commit, tree := treeState(ctx, root)
+	vetRun := models.CIVetRun{
+		Scope:     scope,
+		Tools:     strings.Join(tools, ","),
+		Commit:    commit,
+		Tree:      tree,
+		Status:    "pass",
+		StartedAt: time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var diagnostics []models.CIDiagnostic
+	var output strings.Builder
+	for _, tool := range tools {
+		found, other, err := runVetTool(ctx, root, tool, scope)
+		diagnostics = append(diagnostics, found...)
+		output.WriteString(other)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			vetRun.Status = "error"
+			fmt.Fprintf(&output, "%s: timed out after %s\n", tool, timeout)
+			break
+		}
+		if err != nil {
+			vetRun.Status = "error"
+			fmt.Fprintf(&output, "%s: %v\n", tool, err)
+		}
+	}
+	if vetRun.Status == "pass" && len(diagnostics) > 0 {
+		vetRun.Status = "fail"
+	}
+	slices.SortStableFunc(diagnostics, func(a, b models.CIDiagnostic) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+	})
+	finished := time.Now()
+	vetRun.FinishedAt = &finished
+	vetRun.Output = output.String()
+
+	db := h.server.GetDB()
+	if err := db.Create(&vetRun).Error; err != nil {
+		return models.CIVetRun{}, err
+	}
+	for i := range diagnostics {
+		diagnostics[i].RunID = vetRun.ID
+	}
+	if len(diagnostics) > 0 {
+		if err := db.CreateInBatches(diagnostics, 200).Error; err != nil {
+			return models.CIVetRun{}, err
+		}
+	}
+	vetRun.Diagnostics = diagnostics
+	return vetRun, nil
+}
+
+// vetToolList checks the tools asked for, or picks go vet and the linters
+// found on PATH.
+func vetToolList(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		tools := []string{"vet"}
+		for _, tool := range vetTools[1:] {
+			if _, err := exec.LookPath(tool); err == nil {
+				tools = append(tools, tool)
+			}
+		}
+		return tools, nil
+	}
+	for _, tool := range requested {
+		if !slices.Contains(vetTools, tool) {
+			return nil, fmt.Errorf("unknown tool %q (expected one of %s)", tool, strings.Join(vetTools, ", "))
+		}
+		if tool == "vet" {
+			continue
+		}
+		if _, err := exec.LookPath(tool); err != nil {
+			return nil, fmt.Errorf("%s is not installed", tool)
+		}
+	}
+	var tools []string
+	for _, tool := range vetTools {
+		if slices.Contains(requested, tool) {
+			tools = append(tools, tool)
+		}
+	}
+	return tools, nil
+}
+
+// runVetTool runs one tool with JSON output and returns its findings and
+// whatever else it printed. A tool that exits with an error without
+// reporting anything failed to run.
+func runVetTool(ctx context.Context, root, tool, scope string) ([]models.CIDiagnostic, string, error) {
+	var cmd *exec.Cmd
+	switch tool {
+	case "vet":
+		cmd = exec.CommandContext(ctx, "go", "vet", "-json", scope)
+	case "staticcheck":
+		cmd = exec.CommandContext(ctx, "staticcheck", "-f", "json", scope)
+	case "golangci-lint":
+		cmd = exec.CommandContext(ctx, "golangci-lint", "run", golangciFormat(ctx), scope)
+	}
+	cmd.Dir = root
+	cmd.WaitDelay = 5 * time.Second
+	out, err := cmd.CombinedOutput()
+
+	var diagnostics []models.CIDiagnostic
+	var other string
+	switch tool {
+	case "vet":
+		diagnostics, other = parseVet(root, string(out))
+	case "staticcheck":
+		diagnostics, other = parseStaticcheck(root, string(out))
+	case "golangci-lint":
+		diagnostics, other = parseGolangci(root, string(out))
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(diagnostics) > 0 {
+		err = nil
+	}
+	return diagnostics, other, err
+}
+
+// golangciFormat returns the flag for JSON output on standard output,
+// which golangci-lint v2 renamed.
+func golangciFormat(ctx context.Context) string {
+	out, _ := exec.CommandContext(ctx, "golangci-lint", "--version").Output()
+	if strings.Contains(string(out), "version 1.") {
+		return "--out-format=json"
+	}
+	return "--output.json.path=stdout"
+}
+
+// parseVet reads go vet -json output: a JSON object per package mapping
+// analyzers to their findings, between "# package" headers and the errors
+// of packages that did not load, which become typecheck findings.
+func parseVet(root, out string) ([]models.CIDiagnostic, string) {
+	var diagnostics []models.CIDiagnostic
+	var other strings.Builder
+	var object strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if object.Len() > 0 || line == "{" {
+			object.WriteString(line)
+			object.WriteByte('\n')
+			if line != "}" {
+				continue
+			}
+			var packages map[string]map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(object.String()), &packages); err != nil {
+				other.WriteString(object.String())
+			}
+			object.Reset()
+			for _, analyzers := range packages {
+				for analyzer, raw := range analyzers {
+					var found []struct {
+						Posn    string `json:"posn"`
+						Message string `json:"message"`
+					}
+					if err := json.Unmarshal(raw, &found); err != nil {
+						// An analyzer that failed reports {"error": "..."}
+						var failed struct {
+							Error string `json:"error"`
+						}
+						json.Unmarshal(raw, &failed)
+						fmt.Fprintf(&other, "%s: %s\n", analyzer, failed.Error)
+						continue
+					}
+					for _, f := range found {
+						d := models.CIDiagnostic{Tool: "vet", Analyzer: analyzer, Message: f.Message}
+						if m := vetPosition.FindStringSubmatch(f.Posn); m != nil {
+							d.File = relativeFile(root, m[1])
+							d.Line, _ = strconv.Atoi(m[2])
+							d.Column, _ = strconv.Atoi(m[3])
+						}
+						diagnostics = append(diagnostics, d)
+					}
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "# ") {
+			continue
+		}
+		if m := compileError.FindStringSubmatch(line); m != nil {
+			d := models.CIDiagnostic{Tool: "vet", Analyzer: "typecheck", File: relativeFile(root, m[1]), Message: m[4]}
+			d.Line, _ = strconv.Atoi(m[2])
+			d.Column, _ = strconv.Atoi(m[3])
+			diagnostics = append(diagnostics, d)
+			continue
+		}
+		other.WriteString(line)
+		other.WriteByte('\n')
+	}
+	other.WriteString(object.String())
+	return diagnostics, other.String()
+}
+
+// parseStaticcheck reads staticcheck -f json output, one finding per
+// line.
+func parseStaticcheck(root, out string) ([]models.CIDiagnostic, string) {
+	var diagnostics []models.CIDiagnostic
+	var other strings.Builder
+	for line := range strings.Lines(out) {
+		var found struct {
+			Code     string `json:"code"`
+			Location struct {
+				File   string `json:"file"`
+				Line   int    `json:"line"`
+				Column int    `json:"column"`
+			} `json:"location"`
+			Message string `json:"message"`
+		}
+		if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &found) != nil {
+			other.WriteString(line)
+			continue
+		}
+		analyzer := found.Code
+		if analyzer == "compile" {
+			analyzer = "typecheck"
+		}
+		diagnostics = append(diagnostics, models.CIDiagnostic{
+			Tool: "staticcheck", Analyzer: analyzer, File: relativeFile(root, found.Location.File),
+			Line: found.Location.Line, Column: found.Location.Column, Message: found.Message,
+		})
+	}
+	return diagnostics, other.String()
+}
+
+// parseGolangci reads the JSON report of golangci-lint from among its log
+// lines.
+func parseGolangci(root, out string) ([]models.CIDiagnostic, string) {
+	var diagnostics []models.CIDiagnostic
+	var other strings.Builder
+	for line := range strings.Lines(out) {
+		var report struct {
+			Issues []struct {
+				FromLinter string
+				Text       string
+				Pos        struct {
+					Filename string
+					Line     int
+					Column   int
+				}
+			}
+		}
+		if !strings.HasPrefix(line, `{"Issues"`) || json.Unmarshal([]byte(line), &report) != nil {
+			other.WriteString(line)
+			continue
+		}
+		for _, issue := range report.Issues {
+			diagnostics = append(diagnostics, models.CIDiagnostic{
+				Tool: "golangci-lint", Analyzer: issue.FromLinter, File: relativeFile(root, issue.Pos.Filename),
+				Line: issue.Pos.Line, Column: issue.Pos.Column, Message: issue.Text,
+			})
+		}
+	}
+	return diagnostics, other.String()
+}
+
+// relativeFile returns a file reported by a tool relative to the
+// repository root.
+func relativeFile(root, file string) string {
+	if file == "" {
+		return ""
+	}
+	if filepath.IsAbs(file) {
+		if rel, err := filepath.Rel(root, file); err == nil {
+			file = rel
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(file))
+}
+
+func diagnosticKey(d models.CIDiagnostic) [4]string {
+	return [4]string{d.Tool, d.Analyzer, d.File, d.Message}
+}
+
+func diagnosticOutput(d models.CIDiagnostic) types.CIDiagnostic {
+	return types.CIDiagnostic{Tool: d.Tool, Analyzer: d.Analyzer, File: d.File, Line: d.Line, Column: d.Column, Message: d.Message}
+}
@@ -0,0 +1,133 @@
+package ci
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestCIHandler_Vet(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewCIHandler(srv)
+	ctx := context.Background()
+
+	writeModule(t, tempDir, map[string]string{
+		"a/a.go": "package a\n\nimport \"fmt\"\n\nfunc F() {\n\tfmt.Printf(\"%d\\n\", \"x\")\n}\n",
+	})
+
+	scope := "./..."
+	vet := []string{"vet"}
+	flag := "-vettool=/bin/sh"
+	if _, _, err := handler.CIVet(ctx, nil, types.CIVetInput{Scope: &flag, Tools: vet}); err == nil {
+		t.Errorf("CIVet() with scope %q succeeded, want an error", flag)
+	}
+	_, output, err := handler.CIVet(ctx, nil, types.CIVetInput{Scope: &scope, Tools: vet})
+	if err != nil {
+		t.Fatalf("CIVet() unexpected error: %v", err)
+	}
+	printf := types.CIDiagnostic{Tool: "vet", Analyzer: "printf", File: "a/a.go", Line: 6, Column: 14, Message: `fmt.Printf format %d has arg "x" of wrong type string`}
+	if output.Status != "fail" || output.Total != 1 || output.BaselineRunID != 0 || !reflect.DeepEqual(output.New, []types.CIDiagnostic{printf}) {
+		t.Fatalf("CIVet() = %+v, want the printf finding as new without a baseline", output)
+	}
+	first := output.RunID
+
+	// The old finding moves down a line and a new one appears
+	writeModule(t, tempDir, map[string]string{
+		"a/a.go": "package a\n\nimport \"fmt\"\n\nfunc F() {\n\tvar x int\n\tfmt.Printf(\"%d\\n\", \"x\")\n\tx = x\n}\n",
+	})
+	_, output, err = handler.CIVet(ctx, nil, types.CIVetInput{Scope: &scope, Tools: vet, All: true})
+	if err != nil {
+		t.Fatalf("CIVet() unexpected error: %v", err)
+	}
+	assign := types.CIDiagnostic{Tool: "vet", Analyzer: "assign", File: "a/a.go", Line: 8, Column: 2, Message: "self-assignment of x"}
+	if output.BaselineRunID != first || output.Total != 2 || output.Fixed != 0 || !reflect.DeepEqual(output.New, []types.CIDiagnostic{assign}) {
+		t.Errorf("CIVet() = %+v, want only the self-assignment as new against run %d", output, first)
+	}
+	if len(output.Diagnostics) != 2 || output.Diagnostics[0].Line != 7 {
+		t.Errorf("CIVet() diagnostics = %+v, want both findings in line order", output.Diagnostics)
+	}
+	second := output.RunID
+
+	// A package that does not compile is reported, not an error
+	os.WriteFile(filepath.Join(tempDir, "a", "a.go"), []byte("package a\n\nfunc G() int { return \"s\" }\n"), 0644)
+	_, output, err = handler.CIVet(ctx, nil, types.CIVetInput{Scope: &scope, Tools: vet})
+	if err != nil {
+		t.Fatalf("CIVet() unexpected error: %v", err)
+	}
+	if output.Status != "fail" || output.Fixed != 2 || len(output.New) != 1 || output.New[0].Analyzer != "typecheck" || output.New[0].Line != 3 {
+		t.Errorf("CIVet() = %+v, want a new typecheck finding and both old findings fixed", output)
+	}
+
+	// A stored run against an explicit baseline
+	_, output, err = handler.CIVet(ctx, nil, types.CIVetInput{RunID: second, Baseline: second})
+	if err != nil || len(output.New) != 0 || output.Total != 2 {
+		t.Errorf("CIVet() run %d against itself = %+v, %v, want nothing new", second, output, err)
+	}
+
+	var stored int64
+	srv.GetDB().Model(&models.CIDiagnostic{}).Count(&stored)
+	if stored != 4 {
+		t.Errorf("stored %d diagnostics, want 4", stored)
+	}
+
+	if _, _, err := handler.CIVet(ctx, nil, types.CIVetInput{Tools: []string{"lint"}}); err == nil {
+		t.Errorf("CIVet() with an unknown tool succeeded, want an error")
+	}
+	if _, _, err := handler.CIVet(ctx, nil, types.CIVetInput{RunID: 99}); err == nil {
+		t.Errorf("CIVet() with an unknown run succeeded, want an error")
+	}
+}
+
+func TestParseVet(t *testing.T) {
+	out := "# example.com/m/a\n{\n\t\"example.com/m/a\": {\n\t\t\"printf\": [\n\t\t\t{\n" +
+		"\t\t\t\t\"posn\": \"/repo/a/a.go:6:14\",\n\t\t\t\t\"message\": \"bad format\"\n\t\t\t}\n\t\t]\n\t}\n}\n" +
+		"# example.com/m/b\nvet: b/b.go:3:23: cannot use \"s\" as int value\n" +
+		"go: downloading example.com/x v1.0.0\n"
+	diagnostics, other := parseVet("/repo", out)
+	want := []models.CIDiagnostic{
+		{Tool: "vet", Analyzer: "printf", File: "a/a.go", Line: 6, Column: 14, Message: "bad format"},
+		{Tool: "vet", Analyzer: "typecheck", File: "b/b.go", Line: 3, Column: 23, Message: `cannot use "s" as int value`},
+	}
+	if !reflect.DeepEqual(diagnostics, want) {
+		t.Errorf("parseVet() = %+v, want %+v", diagnostics, want)
+	}
+	if other != "go: downloading example.com/x v1.0.0\n" {
+		t.Errorf("parseVet() other = %q, want the download line", other)
+	}
+}
+
+func TestParseLinters(t *testing.T) {
+	staticcheck := `{"code":"U1000","severity":"error","location":{"file":"/repo/a/a.go","line":3,"column":6},"end":{"file":"/repo/a/a.go","line":3,"column":12},"message":"func unused is unused"}` + "\n" +
+		`{"code":"compile","severity":"error","location":{"file":"/repo/b/b.go","line":1,"column":1},"message":"expected 'package'"}` + "\n"
+	diagnostics, other := parseStaticcheck("/repo", staticcheck)
+	want := []models.CIDiagnostic{
+		{Tool: "staticcheck", Analyzer: "U1000", File: "a/a.go", Line: 3, Column: 6, Message: "func unused is unused"},
+		{Tool: "staticcheck", Analyzer: "typecheck", File: "b/b.go", Line: 1, Column: 1, Message: "expected 'package'"},
+	}
+	if !reflect.DeepEqual(diagnostics, want) || other != "" {
+		t.Errorf("parseStaticcheck() = %+v, %q, want %+v", diagnostics, other, want)
+	}
+
+	golangci := "level=warning msg=\"[runner] slow\"\n" +
+		`{"Issues":[{"FromLinter":"errcheck","Text":"Error return value is not checked","Pos":{"Filename":"a/a.go","Offset":40,"Line":5,"Column":10}}],"Report":{}}` + "\n"
+	diagnostics, other = parseGolangci("/repo", golangci)
+	want = []models.CIDiagnostic{
+		{Tool: "golangci-lint", Analyzer: "errcheck", File: "a/a.go", Line: 5, Column: 10, Message: "Error return value is not checked"},
+	}
+	if !reflect.DeepEqual(diagnostics, want) || other != "level=warning msg=\"[runner] slow\"\n" {
+		t.Errorf("parseGolangci() = %+v, %q, want %+v", diagnostics, other, want)
+	}
+}
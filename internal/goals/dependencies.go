@@ -0,0 +1,226 @@
+package goals
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm/clause"
+)
+
+// GoalsDepend records that one goal is blocked by another.
+//
+// The edge is rejected if it would introduce a cycle, i.e. if the goal being
+// depended on already (transitively) depends on the blocked goal.
+func (h *GoalsHandler) GoalsDepend(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsDependInput) (*mcp.CallToolResult, types.GoalsDependOutput, error) {
+	if input.ID == 0 || input.DependsOn == 0 {
+		return nil, types.GoalsDependOutput{}, fmt.Errorf("id and depends_on are required")
+	}
+	if input.ID == input.DependsOn {
+		return nil, types.GoalsDependOutput{}, fmt.Errorf("goal %d cannot depend on itself", input.ID)
+	}
+
+	db := h.server.GetDB()
+	for _, id := range []int{input.ID, input.DependsOn} {
+		var count int64
+		if err := db.Model(&models.Goal{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return nil, types.GoalsDependOutput{}, err
+		}
+		if count == 0 {
+			return nil, types.GoalsDependOutput{}, fmt.Errorf("goal %d not found", id)
+		}
+	}
+
+	path, err := h.dependencyPath(uint(input.DependsOn), uint(input.ID))
+	if err != nil {
+		return nil, types.GoalsDependOutput{}, err
+	}
+	if path != nil {
+		return nil, types.GoalsDependOutput{}, fmt.Errorf("dependency would create a cycle: %s", formatCycle(uint(input.ID), path))
+	}
+
+	dep := models.GoalDependency{GoalID: uint(input.ID), DependsOnID: uint(input.DependsOn)}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&dep)
+	if result.Error != nil {
+		return nil, types.GoalsDependOutput{}, result.Error
+	}
+
+	return nil, types.GoalsDependOutput{Added: result.RowsAffected > 0}, nil
+}
+
+// GoalsUndepend removes a dependency edge between two goals.
+func (h *GoalsHandler) GoalsUndepend(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsUndependInput) (*mcp.CallToolResult, types.GoalsUndependOutput, error) {
+	if input.ID == 0 || input.DependsOn == 0 {
+		return nil, types.GoalsUndependOutput{}, fmt.Errorf("id and depends_on are required")
+	}
+
+	result := h.server.GetDB().
+		Where("goal_id = ? AND depends_on_id = ?", input.ID, input.DependsOn).
+		Delete(&models.GoalDependency{})
+	if result.Error != nil {
+		return nil, types.GoalsUndependOutput{}, result.Error
+	}
+
+	return nil, types.GoalsUndependOutput{Removed: int(result.RowsAffected)}, nil
+}
+
+// GoalsNext returns the goals an agent should pick up next.
+//
+// These are goals in a todo or active status whose dependencies are all in a
+// done status, ordered the same way as GoalsList (priority ascending, then
+// most recently updated).
+func (h *GoalsHandler) GoalsNext(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsNextInput) (*mcp.CallToolResult, types.GoalsNextOutput, error) {
+	limit := input.Limit
+	if limit == 0 {
+		limit = 5
+	}
+
+	db := h.server.GetDB()
+	w, err := loadWorkflow(db)
+	if err != nil {
+		return nil, types.GoalsNextOutput{}, err
+	}
+
+	unfinished := db.Table("goal_dependencies").
+		Select("goal_dependencies.goal_id").
+		Joins("JOIN goals deps ON deps.id = goal_dependencies.depends_on_id").
+		Where("deps.status NOT IN ?", w.statusesIn(categoryDone))
+
+	var goals []models.Goal
+	err = db.
+		Where("status IN ?", w.statusesIn(categoryTodo, categoryActive)).
+		Where("id NOT IN (?)", unfinished).
+		Order("priority ASC, updated_at DESC").
+		Limit(limit).
+		Find(&goals).Error
+	if err != nil {
+		return nil, types.GoalsNextOutput{}, err
+	}
+
+	resultGoals := make([]types.Goal, 0, len(goals))
+	for _, g := range goals {
+		resultGoals = append(resultGoals, toTypesGoal(g))
+	}
+
+	return nil, types.GoalsNextOutput{Goals: resultGoals}, nil
+}
+
+// GoalsBlocked lists unfinished goals together with the dependencies they are
+// still waiting on.
+func (h *GoalsHandler) GoalsBlocked(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsBlockedInput) (*mcp.CallToolResult, types.GoalsBlockedOutput, error) {
+	db := h.server.GetDB()
+	w, err := loadWorkflow(db)
+	if err != nil {
+		return nil, types.GoalsBlockedOutput{}, err
+	}
+	done := w.statusesIn(categoryDone)
+	closed := w.statusesIn(categoryDone, categoryCancelled)
+
+	type edge struct {
+		GoalID      uint
+		DependsOnID uint
+		Title       string
+		Status      string
+	}
+	var edges []edge
+	err = db.Table("goal_dependencies").
+		Select("goal_dependencies.goal_id, goal_dependencies.depends_on_id, deps.title, deps.status").
+		Joins("JOIN goals deps ON deps.id = goal_dependencies.depends_on_id").
+		Joins("JOIN goals blocked ON blocked.id = goal_dependencies.goal_id").
+		Where("deps.status NOT IN ? AND blocked.status NOT IN ?", done, closed).
+		Order("goal_dependencies.depends_on_id ASC").
+		Scan(&edges).Error
+	if err != nil {
+		return nil, types.GoalsBlockedOutput{}, err
+	}
+
+	waiting := make(map[uint][]types.GoalSummary)
+	var ids []uint
+	for _, e := range edges {
+		if _, ok := waiting[e.GoalID]; !ok {
+			ids = append(ids, e.GoalID)
+		}
+		waiting[e.GoalID] = append(waiting[e.GoalID], types.GoalSummary{
+			ID:     int(e.DependsOnID),
+			Title:  e.Title,
+			Status: e.Status,
+		})
+	}
+
+	blocked := []types.BlockedGoal{}
+	if len(ids) == 0 {
+		return nil, types.GoalsBlockedOutput{Blocked: blocked}, nil
+	}
+
+	query := db.Where("id IN ?", ids).Order("priority ASC, updated_at DESC")
+	if input.Limit > 0 {
+		query = query.Limit(input.Limit)
+	}
+	var goals []models.Goal
+	if err := query.Find(&goals).Error; err != nil {
+		return nil, types.GoalsBlockedOutput{}, err
+	}
+
+	for _, g := range goals {
+		blocked = append(blocked, types.BlockedGoal{
+			Goal:      toTypesGoal(g),
+			WaitingOn: waiting[g.ID],
+		})
+	}
+
+	return nil, types.GoalsBlockedOutput{Blocked: blocked}, nil
+}
+
+// dependencyPath searches the dependency graph for a path from one goal to
+// another, following "depends on" edges. It returns the goals along the path
+// (including both ends) or nil if the target is unreachable.
+func (h *GoalsHandler) dependencyPath(from, to uint) ([]uint, error) {
+	var deps []models.GoalDependency
+	if err := h.server.GetDB().Find(&deps).Error; err != nil {
+		return nil, err
+	}
+
+	edges := make(map[uint][]uint)
+	for _, d := range deps {
+		edges[d.GoalID] = append(edges[d.GoalID], d.DependsOnID)
+	}
+
+	return findPath(edges, from, to), nil
+}
+
+// findPath does a breadth-first search over edges and returns the shortest
+// path from one node to another, or nil if there is none.
+func findPath(edges map[uint][]uint, from, to uint) []uint {
+	prev := map[uint]uint{from: from}
+	queue := []uint{from}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if node == to {
+			path := []uint{to}
+			for node != from {
+				node = prev[node]
+				path = append([]uint{node}, path...)
+			}
+			return path
+		}
+		for _, next := range edges[node] {
+			if _, seen := prev[next]; !seen {
+				prev[next] = node
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// formatCycle renders the cycle that adding goal -> path[0] would close.
+func formatCycle(goal uint, path []uint) string {
+	s := fmt.Sprintf("#%d", goal)
+	for _, id := range path {
+		s += fmt.Sprintf(" -> #%d", id)
+	}
+	return s
+}
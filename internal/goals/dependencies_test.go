@@ -0,0 +1,160 @@
+package goals
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func addTestGoal(t *testing.T, handler *GoalsHandler, title string, priority int) int {
+	t.Helper()
+	_, output, err := handler.GoalsAdd(context.Background(), nil, types.GoalsAddInput{
+		Title:    title,
+		Priority: &priority,
+	})
+	if err != nil {
+		t.Fatalf("Failed to add goal %q: %v", title, err)
+	}
+	return output.ID
+}
+
+func TestGoalsHandler_GoalsDepend(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+
+	a := addTestGoal(t, handler, "A", 1)
+	b := addTestGoal(t, handler, "B", 1)
+	c := addTestGoal(t, handler, "C", 1)
+
+	tests := []struct {
+		name      string
+		input     types.GoalsDependInput
+		wantAdded bool
+		wantError bool
+	}{
+		{
+			name:      "A depends on B",
+			input:     types.GoalsDependInput{ID: a, DependsOn: b},
+			wantAdded: true,
+		},
+		{
+			name:      "B depends on C",
+			input:     types.GoalsDependInput{ID: b, DependsOn: c},
+			wantAdded: true,
+		},
+		{
+			name:      "Duplicate edge is a no-op",
+			input:     types.GoalsDependInput{ID: a, DependsOn: b},
+			wantAdded: false,
+		},
+		{
+			name:      "C depends on A closes a cycle",
+			input:     types.GoalsDependInput{ID: c, DependsOn: a},
+			wantError: true,
+		},
+		{
+			name:      "Self dependency",
+			input:     types.GoalsDependInput{ID: a, DependsOn: a},
+			wantError: true,
+		},
+		{
+			name:      "Unknown goal",
+			input:     types.GoalsDependInput{ID: a, DependsOn: 99999},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := handler.GoalsDepend(context.Background(), nil, tt.input)
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("GoalsDepend() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("GoalsDepend() unexpected error: %v", err)
+				return
+			}
+
+			if output.Added != tt.wantAdded {
+				t.Errorf("GoalsDepend() added = %v, want %v", output.Added, tt.wantAdded)
+			}
+		})
+	}
+}
+
+func TestGoalsHandler_GoalsNextAndBlocked(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	ctx := context.Background()
+
+	blocked := addTestGoal(t, handler, "Blocked", 1)
+	first := addTestGoal(t, handler, "First dependency", 5)
+	second := addTestGoal(t, handler, "Second dependency", 10)
+
+	for _, dep := range []int{first, second} {
+		if _, _, err := handler.GoalsDepend(ctx, nil, types.GoalsDependInput{ID: blocked, DependsOn: dep}); err != nil {
+			t.Fatalf("GoalsDepend() unexpected error: %v", err)
+		}
+	}
+
+	_, next, err := handler.GoalsNext(ctx, nil, types.GoalsNextInput{})
+	if err != nil {
+		t.Fatalf("GoalsNext() unexpected error: %v", err)
+	}
+	if len(next.Goals) != 2 || next.Goals[0].ID != first || next.Goals[1].ID != second {
+		t.Errorf("GoalsNext() = %+v, want goals %d then %d", next.Goals, first, second)
+	}
+
+	_, out, err := handler.GoalsBlocked(ctx, nil, types.GoalsBlockedInput{})
+	if err != nil {
+		t.Fatalf("GoalsBlocked() unexpected error: %v", err)
+	}
+	if len(out.Blocked) != 1 || out.Blocked[0].Goal.ID != blocked || len(out.Blocked[0].WaitingOn) != 2 {
+		t.Fatalf("GoalsBlocked() = %+v, want goal %d waiting on 2 goals", out.Blocked, blocked)
+	}
+
+	// Finishing both dependencies makes the blocked goal actionable
+	done := "done"
+	for _, dep := range []int{first, second} {
+		if _, _, err := handler.GoalsUpdate(ctx, nil, types.GoalsUpdateInput{ID: dep, Status: &done}); err != nil {
+			t.Fatalf("GoalsUpdate() unexpected error: %v", err)
+		}
+	}
+
+	_, next, err = handler.GoalsNext(ctx, nil, types.GoalsNextInput{})
+	if err != nil {
+		t.Fatalf("GoalsNext() unexpected error: %v", err)
+	}
+	if len(next.Goals) != 1 || next.Goals[0].ID != blocked {
+		t.Errorf("GoalsNext() = %+v, want only goal %d", next.Goals, blocked)
+	}
+
+	_, out, err = handler.GoalsBlocked(ctx, nil, types.GoalsBlockedInput{})
+	if err != nil {
+		t.Fatalf("GoalsBlocked() unexpected error: %v", err)
+	}
+	if len(out.Blocked) != 0 {
+		t.Errorf("GoalsBlocked() = %+v, want none", out.Blocked)
+	}
+}
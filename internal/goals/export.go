@@ -0,0 +1,369 @@
+package goals
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/markdown"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+// goalExport is the data every export format is rendered from. Goals are
+// sorted by priority and ID so exports are stable between runs.
+type goalExport struct {
+	workflow *workflow
+	goals    []models.Goal
+	items    map[uint][]models.GoalItem
+}
+
+// exportFormat describes one goals_export output.
+type exportFormat struct {
+	file   string
+	render func(e *goalExport) (string, error)
+}
+
+var exportFormats = map[string]exportFormat{
+	"roadmap": {file: "ROADMAP.md", render: renderRoadmap},
+	"board":   {file: "BOARD.md", render: renderBoard},
+	"html":    {file: "board.html", render: renderHTMLBoard},
+
+	// Formats other tools can import, see interchange.go
+	"todotxt":     {file: "todo.txt", render: renderTodoTxt},
+	"taskwarrior": {file: "tasks.json", render: renderTaskwarrior},
+	"github":      {file: "issues.json", render: renderGitHubIssues},
+}
+
+var defaultExportFormats = []string{"roadmap", "board", "html"}
+
+// GoalsExport writes the project goals to files for people who do not use an
+// MCP client: a roadmap grouped by milestone and status, a Kanban board in
+// markdown and the same board as a self-contained HTML page.
+//
+// The output contains no timestamps and is ordered deterministically, so
+// regenerating it only changes the lines of goals that changed.
+func (h *GoalsHandler) GoalsExport(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsExportInput) (*mcp.CallToolResult, types.GoalsExportOutput, error) {
+	formats := input.Formats
+	if len(formats) == 0 {
+		formats = defaultExportFormats
+	}
+	for _, name := range formats {
+		if _, ok := exportFormats[name]; !ok {
+			return nil, types.GoalsExportOutput{}, fmt.Errorf("unknown export format %q (allowed: %s)", name, strings.Join(exportFormatNames(), ", "))
+		}
+	}
+
+	outputDir := h.server.GetDocsOutputPath()
+	if input.OutputDir != nil && *input.OutputDir != "" {
+		dir := path.Clean(filepath.ToSlash(*input.OutputDir))
+		if strings.HasPrefix(dir, "../") || dir == ".." || path.IsAbs(dir) {
+			return nil, types.GoalsExportOutput{}, fmt.Errorf("output_dir must be inside the repository")
+		}
+		outputDir = filepath.Join(h.server.GetRepoRoot(), filepath.FromSlash(dir))
+	}
+
+	e, err := h.loadExport()
+	if err != nil {
+		return nil, types.GoalsExportOutput{}, err
+	}
+
+	output := types.GoalsExportOutput{Files: []types.ExportedFile{}, Goals: len(e.goals)}
+	written := make(map[string]bool)
+	for _, name := range formats {
+		if written[name] {
+			continue
+		}
+		written[name] = true
+
+		format := exportFormats[name]
+		content, err := format.render(e)
+		if err != nil {
+			return nil, types.GoalsExportOutput{}, fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		path := filepath.Join(outputDir, format.file)
+		changed, err := writeIfChanged(path, content)
+		if err != nil {
+			return nil, types.GoalsExportOutput{}, err
+		}
+		output.Files = append(output.Files, types.ExportedFile{Format: name, Path: path, Changed: changed})
+	}
+
+	return nil, output, nil
+}
+
+func (h *GoalsHandler) loadExport() (*goalExport, error) {
+	db := h.server.GetDB()
+	w, err := loadWorkflow(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var goals []models.Goal
+	if err := db.Order("priority ASC, id ASC").Find(&goals).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(goals))
+	for i, g := range goals {
+		ids[i] = g.ID
+	}
+	items, err := loadItemsFor(db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return &goalExport{workflow: w, goals: goals, items: items}, nil
+}
+
+// columns groups goals by status in workflow order. Goals in a status the
+// workflow no longer knows about get a column after the known ones.
+func (e *goalExport) columns() []boardColumn {
+	byStatus := make(map[string][]models.Goal)
+	for _, g := range e.goals {
+		byStatus[g.Status] = append(byStatus[g.Status], g)
+	}
+
+	var columns []boardColumn
+	for _, name := range e.workflow.names() {
+		columns = append(columns, boardColumn{Status: name, Goals: byStatus[name]})
+		delete(byStatus, name)
+	}
+	var unknown []string
+	for name := range byStatus {
+		unknown = append(unknown, name)
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		columns = append(columns, boardColumn{Status: name, Goals: byStatus[name]})
+	}
+	return columns
+}
+
+// milestones returns the milestone names in use, sorted, with goals without a
+// milestone ("") last.
+func (e *goalExport) milestones() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, g := range e.goals {
+		if !seen[g.Milestone] {
+			seen[g.Milestone] = true
+			names = append(names, g.Milestone)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == "" || names[j] == "" {
+			return names[j] == ""
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+type boardColumn struct {
+	Status string
+	Goals  []models.Goal
+}
+
+func renderRoadmap(e *goalExport) (string, error) {
+	md := markdown.NewBuilder()
+	md.AddHeader(1, "Roadmap")
+	md.AddParagraph("Generated from the project goals by goals_export. Update the goals and regenerate instead of editing this file.")
+	if len(e.goals) == 0 {
+		md.AddParagraph("No goals yet.")
+		return md.String(), nil
+	}
+
+	done := e.workflow.statusesIn(categoryDone)
+	for _, milestone := range e.milestones() {
+		var goals []models.Goal
+		finished := 0
+		for _, g := range e.goals {
+			if g.Milestone != milestone {
+				continue
+			}
+			goals = append(goals, g)
+			if slices.Contains(done, g.Status) {
+				finished++
+			}
+		}
+
+		title := milestone
+		if title == "" {
+			title = "No milestone"
+		}
+		md.AddHeader(2, title)
+		md.AddParagraph(fmt.Sprintf("%d of %d goals done.", finished, len(goals)))
+
+		sub := &goalExport{workflow: e.workflow, goals: goals, items: e.items}
+		for _, column := range sub.columns() {
+			if len(column.Goals) == 0 {
+				continue
+			}
+			md.AddHeader(3, column.Status)
+			for _, g := range column.Goals {
+				writeGoalMarkdown(md, 4, g, e.items[g.ID])
+			}
+		}
+	}
+	return md.String(), nil
+}
+
+func renderBoard(e *goalExport) (string, error) {
+	columns := e.columns()
+	headers := make([]string, len(columns))
+	rows := 0
+	for i, column := range columns {
+		headers[i] = fmt.Sprintf("%s (%d)", column.Status, len(column.Goals))
+		rows = max(rows, len(column.Goals))
+	}
+
+	cells := make([][]string, rows)
+	for r := range cells {
+		cells[r] = make([]string, len(columns))
+		for c, column := range columns {
+			if r < len(column.Goals) {
+				g := column.Goals[r]
+				cells[r][c] = escapeTableCell(fmt.Sprintf("#%d %s", g.ID, g.Title))
+			}
+		}
+	}
+
+	md := markdown.NewBuilder()
+	md.AddHeader(1, "Board")
+	md.AddParagraph("Generated from the project goals by goals_export. Update the goals and regenerate instead of editing this file.")
+	md.AddTable(headers, cells)
+	return md.String(), nil
+}
+
+func escapeTableCell(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "|", `\|`), "\n", " ")
+}
+
+// htmlCard is the template view of a goal on the HTML board.
+type htmlCard struct {
+	ID       uint
+	Title    string
+	Details  []string
+	Tags     []string
+	Criteria string
+}
+
+var htmlBoardTemplate = template.Must(template.New("board").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Board</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 1.5rem; background: #f6f8fa; color: #1f2328; }
+h1 { font-size: 1.5rem; }
+.board { display: flex; gap: 1rem; align-items: flex-start; overflow-x: auto; }
+.column { flex: 0 0 16rem; background: #eaeef2; border-radius: 6px; padding: 0.5rem; }
+.column h2 { font-size: 0.9rem; text-transform: uppercase; margin: 0.25rem 0.25rem 0.5rem; }
+.card { background: #fff; border: 1px solid #d0d7de; border-radius: 6px; padding: 0.5rem; margin-bottom: 0.5rem; }
+.card .id { color: #656d76; font-size: 0.8rem; }
+.card .title { font-weight: 600; margin: 0.25rem 0; }
+.card .details, .card .criteria { color: #656d76; font-size: 0.8rem; }
+.tag { display: inline-block; background: #ddf4ff; color: #0969da; border-radius: 1em; padding: 0 0.5em; font-size: 0.75rem; margin-right: 0.25em; }
+</style>
+</head>
+<body>
+<h1>Board</h1>
+<div class="board">
+{{- range .}}
+<section class="column">
+<h2>{{.Status}} ({{len .Cards}})</h2>
+{{- range .Cards}}
+<article class="card">
+<div class="id">#{{.ID}}</div>
+<div class="title">{{.Title}}</div>
+{{- if .Details}}
+<div class="details">{{range $i, $d := .Details}}{{if $i}} · {{end}}{{$d}}{{end}}</div>
+{{- end}}
+{{- if .Criteria}}
+<div class="criteria">{{.Criteria}}</div>
+{{- end}}
+{{- if .Tags}}
+<div class="tags">{{range .Tags}}<span class="tag">{{.}}</span>{{end}}</div>
+{{- end}}
+</article>
+{{- end}}
+</section>
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+func renderHTMLBoard(e *goalExport) (string, error) {
+	type htmlColumn struct {
+		Status string
+		Cards  []htmlCard
+	}
+
+	var columns []htmlColumn
+	for _, column := range e.columns() {
+		view := htmlColumn{Status: column.Status, Cards: []htmlCard{}}
+		for _, g := range column.Goals {
+			card := htmlCard{ID: g.ID, Title: g.Title, Tags: splitTags(g.Tags)}
+			card.Details = append(card.Details, fmt.Sprintf("P%d", g.Priority))
+			if g.Owner != "" {
+				card.Details = append(card.Details, g.Owner)
+			}
+			if g.DueAt != nil {
+				card.Details = append(card.Details, "due "+g.DueAt.Format("2006-01-02"))
+			}
+			if g.Milestone != "" {
+				card.Details = append(card.Details, g.Milestone)
+			}
+			if items := e.items[g.ID]; len(items) > 0 {
+				checked := 0
+				for _, item := range items {
+					if item.Checked {
+						checked++
+					}
+				}
+				card.Criteria = fmt.Sprintf("%d/%d criteria", checked, len(items))
+			}
+			view.Cards = append(view.Cards, card)
+		}
+		columns = append(columns, view)
+	}
+
+	var buf bytes.Buffer
+	if err := htmlBoardTemplate.Execute(&buf, columns); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// writeIfChanged writes content to path and reports whether it differs from
+// the file's previous content.
+func writeIfChanged(path, content string) (bool, error) {
+	if existing, err := os.ReadFile(path); err == nil && string(existing) == content {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return true, nil
+}
+
+func exportFormatNames() []string {
+	names := make([]string, 0, len(exportFormats))
+	for name := range exportFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
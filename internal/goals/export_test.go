@@ -0,0 +1,109 @@
+package goals
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestGoalsHandler_GoalsExport(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	ctx := context.Background()
+
+	str := func(s string) *string { return &s }
+	prio := func(p int) *int { return &p }
+	goals := []types.GoalsAddInput{
+		{Title: "Ship login", Priority: prio(1), Milestone: str("v1"), Criteria: []string{"Form works"}},
+		{Title: "Escape <b>|pipes|</b>", Priority: prio(2), Milestone: str("v1")},
+		{Title: "Plan v2", Priority: prio(3)},
+	}
+	ids := make([]int, len(goals))
+	for i, g := range goals {
+		_, out, err := handler.GoalsAdd(ctx, nil, g)
+		if err != nil {
+			t.Fatalf("GoalsAdd() unexpected error: %v", err)
+		}
+		ids[i] = out.ID
+	}
+	if _, _, err := handler.GoalsUpdate(ctx, nil, types.GoalsUpdateInput{ID: ids[1], Status: str("done")}); err != nil {
+		t.Fatalf("GoalsUpdate() unexpected error: %v", err)
+	}
+
+	if _, _, err := handler.GoalsExport(ctx, nil, types.GoalsExportInput{Formats: []string{"pdf"}}); err == nil {
+		t.Errorf("GoalsExport() with unknown format expected error, got nil")
+	}
+	for _, dir := range []string{"../outside", "out/../../outside", "/tmp/outside"} {
+		if _, _, err := handler.GoalsExport(ctx, nil, types.GoalsExportInput{OutputDir: str(dir)}); err == nil {
+			t.Errorf("GoalsExport() to %s expected error, got nil", dir)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(tempDir), "outside")); !os.IsNotExist(err) {
+		t.Errorf("GoalsExport() wrote outside the repository")
+	}
+
+	input := types.GoalsExportInput{OutputDir: str("out")}
+	_, output, err := handler.GoalsExport(ctx, nil, input)
+	if err != nil {
+		t.Fatalf("GoalsExport() unexpected error: %v", err)
+	}
+	if output.Goals != 3 || len(output.Files) != 3 {
+		t.Fatalf("GoalsExport() = %+v, want 3 goals in 3 files", output)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(tempDir, "out", name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		return string(data)
+	}
+
+	roadmap := read("ROADMAP.md")
+	for _, want := range []string{"## v1\n\n1 of 2 goals done.", "### active\n\n#### #1 Ship login", "- [ ] Form works", "### done", "## No milestone"} {
+		if !strings.Contains(roadmap, want) {
+			t.Errorf("ROADMAP.md missing %q:\n%s", want, roadmap)
+		}
+	}
+	if strings.Index(roadmap, "## v1") > strings.Index(roadmap, "## No milestone") {
+		t.Errorf("ROADMAP.md lists goals without a milestone before v1:\n%s", roadmap)
+	}
+
+	board := read("BOARD.md")
+	for _, want := range []string{"| active (2) | paused (0) | done (1) |", `| #1 Ship login |  | #2 Escape <b>\|pipes\|</b> |`, "| #3 Plan v2 |  |  |"} {
+		if !strings.Contains(board, want) {
+			t.Errorf("BOARD.md missing %q:\n%s", want, board)
+		}
+	}
+
+	html := read("board.html")
+	if strings.Contains(html, "<b>") || !strings.Contains(html, "Escape &lt;b&gt;|pipes|&lt;/b&gt;") {
+		t.Errorf("board.html does not escape goal titles:\n%s", html)
+	}
+	if !strings.Contains(html, "0/1 criteria") {
+		t.Errorf("board.html missing criteria progress:\n%s", html)
+	}
+
+	// Regenerating without changes leaves every file as it was
+	_, output, err = handler.GoalsExport(ctx, nil, input)
+	if err != nil {
+		t.Fatalf("GoalsExport() unexpected error: %v", err)
+	}
+	for _, f := range output.Files {
+		if f.Changed {
+			t.Errorf("GoalsExport() rewrote %s without changes", f.Path)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package goals
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
+)
+
+// goalFilter is the parsed form of a goals_list query. It can be built from
+// the structured input fields, from a filter expression, or from both.
+type goalFilter struct {
+	statuses  []string
+	completed bool
+	tags      []string
+	owner     string
+	milestone string
+	overdue   bool
+	dueBefore *time.Time
+	query     []string
+	sort      string
+	desc      bool
+}
+
+// sortColumns maps goals_list sort fields to their ORDER BY clauses. Each
+// clause ends with id so pagination is stable.
+var sortColumns = map[string][2]string{
+	"priority": {"priority ASC, updated_at DESC, id ASC", "priority DESC, updated_at ASC, id DESC"},
+	"due":      {"due_at IS NULL, due_at ASC, priority ASC, id ASC", "due_at IS NULL, due_at DESC, priority ASC, id DESC"},
+	"updated":  {"updated_at ASC, id ASC", "updated_at DESC, id DESC"},
+	"created":  {"id ASC", "id DESC"},
+	"title":    {"title COLLATE NOCASE ASC, id ASC", "title COLLATE NOCASE DESC, id DESC"},
+}
+
+// newGoalFilter builds a filter from the goals_list input. Structured fields
+// are applied on top of whatever the filter expression specifies.
+func newGoalFilter(input types.GoalsListInput) (goalFilter, error) {
+	f, err := parseFilter(input.Filter)
+	if err != nil {
+		return goalFilter{}, err
+	}
+
+	if len(input.Statuses) > 0 {
+		f.statuses = input.Statuses
+	}
+	f.completed = f.completed || input.Completed
+	if input.Tag != "" {
+		f.tags = append(f.tags, normalizeTag(input.Tag))
+	}
+	if input.Owner != "" {
+		f.owner = input.Owner
+	}
+	if input.Milestone != "" {
+		f.milestone = input.Milestone
+	}
+	f.overdue = f.overdue || input.Overdue
+	if input.DueBefore != "" {
+		due, err := parseDueDate(input.DueBefore)
+		if err != nil {
+			return goalFilter{}, err
+		}
+		f.dueBefore = &due
+	}
+	if strings.TrimSpace(input.Query) != "" {
+		f.query = append(f.query, strings.TrimSpace(input.Query))
+	}
+	if input.Sort != "" {
+		if err := f.setSort(input.Sort); err != nil {
+			return goalFilter{}, err
+		}
+	}
+	if f.sort == "" {
+		f.sort = "priority"
+		if f.completed {
+			f.sort, f.desc = "updated", true
+		}
+	}
+
+	return f, nil
+}
+
+// parseFilter parses a filter expression made of space-separated terms:
+//
+//	status:todo,in_progress   goals in any of these statuses
+//	completed                 goals in a done status
+//	tag:api                   goals with this tag (repeat for several)
+//	owner:sam                 goals owned by sam
+//	milestone:v1              goals in milestone v1
+//	overdue                   open goals due before today
+//	due<2025-07-01            goals due before this date
+//	sort:-due                 sort field, - reverses the order
+//	"some words"              text query on title and notes
+//
+// Any other word is added to the text query.
+func parseFilter(expr string) (goalFilter, error) {
+	var f goalFilter
+	for _, term := range splitTerms(expr) {
+		key, value, hasKey := strings.Cut(term, ":")
+		switch {
+		case term == "overdue":
+			f.overdue = true
+		case term == "completed":
+			f.completed = true
+		case strings.HasPrefix(term, "due<"):
+			due, err := parseDueDate(strings.TrimPrefix(term, "due<"))
+			if err != nil {
+				return goalFilter{}, err
+			}
+			f.dueBefore = &due
+		case hasKey && key == "status":
+			f.statuses = append(f.statuses, splitList(value)...)
+		case hasKey && key == "tag":
+			f.tags = append(f.tags, normalizeTag(value))
+		case hasKey && key == "owner":
+			f.owner = value
+		case hasKey && key == "milestone":
+			f.milestone = value
+		case hasKey && key == "sort":
+			if err := f.setSort(value); err != nil {
+				return goalFilter{}, err
+			}
+		default:
+			f.query = append(f.query, term)
+		}
+	}
+	return f, nil
+}
+
+func (f *goalFilter) setSort(value string) error {
+	field := strings.TrimPrefix(value, "-")
+	if _, ok := sortColumns[field]; !ok {
+		return fmt.Errorf("unknown sort field %q (allowed: priority, due, updated, created, title)", field)
+	}
+	f.sort = field
+	f.desc = strings.HasPrefix(value, "-")
+	return nil
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern that uses ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// apply adds the filter's conditions and ordering to a goals query.
+func (f goalFilter) apply(query *gorm.DB, w *workflow) *gorm.DB {
+	closed := w.statusesIn(categoryDone, categoryCancelled)
+	switch {
+	case len(f.statuses) > 0:
+		query = query.Where("status IN ?", f.statuses)
+	case f.completed:
+		query = query.Where("status IN ?", w.statusesIn(categoryDone))
+	default:
+		query = query.Where("status NOT IN ?", closed)
+	}
+
+	for _, tag := range f.tags {
+		query = query.Where("instr(',' || tags || ',', ?) > 0", ","+tag+",")
+	}
+	if f.owner != "" {
+		query = query.Where("owner = ?", f.owner)
+	}
+	if f.milestone != "" {
+		query = query.Where("milestone = ?", f.milestone)
+	}
+	if f.overdue {
+		query = query.Where("due_at IS NOT NULL AND due_at < ? AND status NOT IN ?", today(), closed)
+	}
+	if f.dueBefore != nil {
+		query = query.Where("due_at IS NOT NULL AND due_at < ?", *f.dueBefore)
+	}
+	for _, q := range f.query {
+		like := "%" + likeEscaper.Replace(q) + "%"
+		query = query.Where(`title LIKE ? ESCAPE '\' OR notes LIKE ? ESCAPE '\'`, like, like)
+	}
+
+	order := sortColumns[f.sort][0]
+	if f.desc {
+		order = sortColumns[f.sort][1]
+	}
+	return query.Order(order)
+}
+
+// splitTerms splits a filter expression on whitespace, keeping double-quoted
+// phrases together.
+func splitTerms(expr string) []string {
+	var terms []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range expr {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ' ' && !quoted:
+			if current.Len() > 0 {
+				terms = append(terms, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		terms = append(terms, current.String())
+	}
+	return terms
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseDueDate accepts a plain date (YYYY-MM-DD) or an RFC 3339 timestamp.
+// Due dates are stored in UTC so SQLite can compare them as strings.
+func parseDueDate(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD or RFC 3339)", value)
+}
+
+// today returns the current calendar date in the same form as parseDueDate,
+// so a goal due today is not yet overdue.
+func today() time.Time {
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// joinTags normalizes, de-duplicates and joins tags for storage.
+func joinTags(tags []string) string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] || strings.Contains(tag, ",") {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return strings.Join(result, ",")
+}
+
+// splitTags is the inverse of joinTags.
+func splitTags(tags string) []string {
+	if tags == "" {
+		return nil
+	}
+	return strings.Split(tags, ",")
+}
+
+// encodeCursor and decodeCursor turn a result offset into an opaque cursor.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if value, ok := strings.CutPrefix(string(raw), "offset:"); ok {
+			if offset, err := strconv.Atoi(value); err == nil && offset >= 0 {
+				return offset, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor %q", cursor)
+}
@@ -0,0 +1,206 @@
+package goals
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		want      goalFilter
+		wantError bool
+	}{
+		{
+			name: "Empty expression",
+			expr: "",
+			want: goalFilter{},
+		},
+		{
+			name: "All term kinds",
+			expr: `status:todo,in_progress tag:API owner:sam milestone:v1 overdue sort:-due "login bug" flaky`,
+			want: goalFilter{
+				statuses:  []string{"todo", "in_progress"},
+				tags:      []string{"api"},
+				owner:     "sam",
+				milestone: "v1",
+				overdue:   true,
+				sort:      "due",
+				desc:      true,
+				query:     []string{"login bug", "flaky"},
+			},
+		},
+		{
+			name: "Completed goals",
+			expr: "completed",
+			want: goalFilter{completed: true},
+		},
+		{
+			name:      "Unknown sort field",
+			expr:      "sort:size",
+			wantError: true,
+		},
+		{
+			name:      "Invalid due date",
+			expr:      "due<tomorrow",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFilter(tt.expr)
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("parseFilter() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("parseFilter() unexpected error: %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoalsHandler_GoalsListFilters(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	ctx := context.Background()
+
+	str := func(s string) *string { return &s }
+	goals := []types.GoalsAddInput{
+		{Title: "Fix login bug", DueDate: str("2000-01-01"), Tags: []string{"auth", "bug"}, Owner: str("sam")},
+		{Title: "Write docs", DueDate: str("2999-01-01"), Tags: []string{"docs"}},
+		{Title: "Refactor auth", Tags: []string{"Auth"}, Owner: str("kim")},
+		{Title: "Release v1"},
+	}
+	ids := make([]int, len(goals))
+	for i, g := range goals {
+		_, out, err := handler.GoalsAdd(ctx, nil, g)
+		if err != nil {
+			t.Fatalf("GoalsAdd() unexpected error: %v", err)
+		}
+		ids[i] = out.ID
+	}
+	if _, _, err := handler.GoalsUpdate(ctx, nil, types.GoalsUpdateInput{ID: ids[3], Status: str("done")}); err != nil {
+		t.Fatalf("GoalsUpdate() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		input   types.GoalsListInput
+		wantIDs []int
+	}{
+		{name: "Open goals by default", input: types.GoalsListInput{Sort: "created"}, wantIDs: []int{ids[0], ids[1], ids[2]}},
+		{name: "Tag filter", input: types.GoalsListInput{Tag: "auth", Sort: "created"}, wantIDs: []int{ids[0], ids[2]}},
+		{name: "Owner filter", input: types.GoalsListInput{Owner: "kim"}, wantIDs: []int{ids[2]}},
+		{name: "Overdue", input: types.GoalsListInput{Overdue: true}, wantIDs: []int{ids[0]}},
+		{name: "Due before", input: types.GoalsListInput{DueBefore: "2500-01-01"}, wantIDs: []int{ids[0]}},
+		{name: "Sort by due date puts undated goals last", input: types.GoalsListInput{Sort: "due"}, wantIDs: []int{ids[0], ids[1], ids[2]}},
+		{name: "Text query", input: types.GoalsListInput{Query: "docs"}, wantIDs: []int{ids[1]}},
+		{name: "Completed goals", input: types.GoalsListInput{Completed: true}, wantIDs: []int{ids[3]}},
+		{name: "Filter expression", input: types.GoalsListInput{Filter: "tag:auth owner:sam"}, wantIDs: []int{ids[0]}},
+		{name: "Explicit status set", input: types.GoalsListInput{Filter: "status:active,done sort:-created"}, wantIDs: []int{ids[3], ids[2], ids[1], ids[0]}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := handler.GoalsList(ctx, nil, tt.input)
+			if err != nil {
+				t.Fatalf("GoalsList() unexpected error: %v", err)
+			}
+
+			var got []int
+			for _, g := range output.Goals {
+				got = append(got, g.ID)
+			}
+			if !reflect.DeepEqual(got, tt.wantIDs) {
+				t.Errorf("GoalsList() ids = %v, want %v", got, tt.wantIDs)
+			}
+		})
+	}
+
+	// A filter that matches nothing lists no goals rather than null
+	_, empty, err := handler.GoalsList(ctx, nil, types.GoalsListInput{Tag: "missing"})
+	if err != nil {
+		t.Fatalf("GoalsList() unexpected error: %v", err)
+	}
+	if empty.Goals == nil || len(empty.Goals) != 0 {
+		t.Errorf("GoalsList() with no match = %#v, want an empty list", empty.Goals)
+	}
+
+	// Pagination walks through all open goals exactly once
+	var seen []int
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		_, output, err := handler.GoalsList(ctx, nil, types.GoalsListInput{Limit: 2, Sort: "created", Cursor: cursor})
+		if err != nil {
+			t.Fatalf("GoalsList() unexpected error: %v", err)
+		}
+		for _, g := range output.Goals {
+			seen = append(seen, g.ID)
+		}
+		cursor = output.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+	if !reflect.DeepEqual(seen, []int{ids[0], ids[1], ids[2]}) {
+		t.Errorf("GoalsList() pages = %v, want %v", seen, ids[:3])
+	}
+
+	// Titles and other fields can be changed
+	_, _, err = handler.GoalsUpdate(ctx, nil, types.GoalsUpdateInput{ID: ids[1], Title: str("Write user docs"), DueDate: str(""), Tags: []string{}})
+	if err != nil {
+		t.Fatalf("GoalsUpdate() unexpected error: %v", err)
+	}
+	_, output, err := handler.GoalsList(ctx, nil, types.GoalsListInput{Query: "user docs"})
+	if err != nil {
+		t.Fatalf("GoalsList() unexpected error: %v", err)
+	}
+	if len(output.Goals) != 1 || output.Goals[0].DueDate != "" || len(output.Goals[0].Tags) != 0 {
+		t.Errorf("GoalsList() after update = %+v, want renamed goal without due date or tags", output.Goals)
+	}
+
+	if _, _, err := handler.GoalsUpdate(ctx, nil, types.GoalsUpdateInput{ID: ids[1], Title: str("  ")}); err == nil {
+		t.Errorf("GoalsUpdate() with blank title expected error, got nil")
+	}
+
+	// Wildcards in tags and text are matched literally
+	_, wild, err := handler.GoalsAdd(ctx, nil, types.GoalsAddInput{Title: "Reach 100% coverage", Tags: []string{"db_tuning"}})
+	if err != nil {
+		t.Fatalf("GoalsAdd() unexpected error: %v", err)
+	}
+	if _, _, err := handler.GoalsAdd(ctx, nil, types.GoalsAddInput{Title: "Reach 1000 users", Tags: []string{"dbxtuning"}}); err != nil {
+		t.Fatalf("GoalsAdd() unexpected error: %v", err)
+	}
+	for _, input := range []types.GoalsListInput{{Query: "100%"}, {Tag: "db_tuning"}, {Filter: "tag:db_tuning"}} {
+		_, output, err := handler.GoalsList(ctx, nil, input)
+		if err != nil {
+			t.Fatalf("GoalsList() unexpected error: %v", err)
+		}
+		if len(output.Goals) != 1 || output.Goals[0].ID != wild.ID {
+			t.Errorf("GoalsList(%+v) = %+v, want only goal %d", input, output.Goals, wild.ID)
+		}
+	}
+}
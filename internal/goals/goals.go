@@ -14,11 +14,13 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/thornzero/project-manager/internal/models"
 	"github.com/thornzero/project-manager/internal/server"
 	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
 )
 
 // GoalsHandler handles MCP tool requests for goal management operations.
@@ -36,16 +38,20 @@ func NewGoalsHandler(s *server.Server) *GoalsHandler {
 	return &GoalsHandler{server: s}
 }
 
-// GoalsList retrieves a list of active project goals.
+// GoalsList retrieves a filtered page of project goals.
 //
-// It returns goals that are not marked as "done", ordered by priority (ascending)
-// and then by update time (descending). The number of results is limited by the
-// input limit parameter, defaulting to 10 if not specified.
+// By default it returns goals that are not in a closed (done or cancelled)
+// status of the project workflow, ordered by priority (ascending) and then by
+// update time (descending). The input can narrow this down by status, tag,
+// owner, due date and text, either with structured fields or with a filter
+// expression (see parseFilter). The number of results is limited by the input
+// limit parameter, defaulting to 10 if not specified; NextCursor is set when
+// more results are available.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout
 //   - req: MCP tool request (unused but required by interface)
-//   - input: GoalsListInput containing optional limit parameter
+//   - input: GoalsListInput containing filters, sort order and pagination
 //
 // Returns:
 //   - result: MCP call result with JSON response
@@ -57,30 +63,56 @@ func (h *GoalsHandler) GoalsList(ctx context.Context, req *mcp.CallToolRequest,
 		limit = 10
 	}
 
+	filter, err := newGoalFilter(input)
+	if err != nil {
+		return nil, types.GoalsListOutput{}, err
+	}
+	offset, err := decodeCursor(input.Cursor)
+	if err != nil {
+		return nil, types.GoalsListOutput{}, err
+	}
+
+	w, err := loadWorkflow(h.server.GetDB())
+	if err != nil {
+		return nil, types.GoalsListOutput{}, err
+	}
+
+	// Fetch one extra row to find out whether there is another page
 	var goals []models.Goal
-	err := h.server.GetDB().
-		Where("status != ?", "done").
-		Order("priority ASC, updated_at DESC").
-		Limit(limit).
+	err = filter.apply(h.server.GetDB(), w).
+		Offset(offset).
+		Limit(limit + 1).
 		Find(&goals).Error
 	if err != nil {
 		return nil, types.GoalsListOutput{}, err
 	}
 
+	nextCursor := ""
+	if len(goals) > limit {
+		goals = goals[:limit]
+		nextCursor = encodeCursor(offset + limit)
+	}
+
+	ids := make([]uint, 0, len(goals))
+	for _, g := range goals {
+		ids = append(ids, g.ID)
+	}
+	items, err := loadItemsFor(h.server.GetDB(), ids)
+	if err != nil {
+		return nil, types.GoalsListOutput{}, err
+	}
+
 	// Convert to types.Goal
-	var resultGoals []types.Goal
+	resultGoals := make([]types.Goal, 0, len(goals))
 	for _, g := range goals {
-		resultGoals = append(resultGoals, types.Goal{
-			ID:        int(g.ID),
-			Title:     g.Title,
-			Priority:  g.Priority,
-			Status:    g.Status,
-			Notes:     g.Notes,
-			UpdatedAt: g.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
+		goal := toTypesGoal(g)
+		if len(items[g.ID]) > 0 {
+			goal.Criteria = toTypesItems(items[g.ID])
+		}
+		resultGoals = append(resultGoals, goal)
 	}
 
-	return nil, types.GoalsListOutput{Goals: resultGoals}, nil
+	return nil, types.GoalsListOutput{Goals: resultGoals, NextCursor: nextCursor}, nil
 }
 
 func (h *GoalsHandler) GoalsAdd(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsAddInput) (*mcp.CallToolResult, types.GoalsAddOutput, error) {
@@ -96,15 +128,51 @@ func (h *GoalsHandler) GoalsAdd(ctx context.Context, req *mcp.CallToolRequest, i
 	if input.Notes != nil {
 		notes = *input.Notes
 	}
+	owner := ""
+	if input.Owner != nil {
+		owner = strings.TrimSpace(*input.Owner)
+	}
+	milestone := ""
+	if input.Milestone != nil {
+		milestone = strings.TrimSpace(*input.Milestone)
+	}
+	var dueAt *time.Time
+	if input.DueDate != nil && *input.DueDate != "" {
+		due, err := parseDueDate(*input.DueDate)
+		if err != nil {
+			return nil, types.GoalsAddOutput{}, err
+		}
+		dueAt = &due
+	}
+
+	w, err := loadWorkflow(h.server.GetDB())
+	if err != nil {
+		return nil, types.GoalsAddOutput{}, err
+	}
 
 	goal := models.Goal{
-		Title:    input.Title,
-		Priority: prio,
-		Notes:    notes,
-		Status:   "active",
+		Title:     input.Title,
+		Priority:  prio,
+		Notes:     notes,
+		Status:    w.initial(),
+		DueAt:     dueAt,
+		Tags:      joinTags(input.Tags),
+		Owner:     owner,
+		Milestone: milestone,
+	}
+	if input.AutoComplete != nil {
+		goal.AutoComplete = *input.AutoComplete
 	}
 
-	err := h.server.GetDB().Create(&goal).Error
+	err = h.server.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&goal).Error; err != nil {
+			return err
+		}
+		if err := addItems(tx, goal.ID, input.Criteria); err != nil {
+			return err
+		}
+		return tx.Create(&models.GoalStatusChange{GoalID: goal.ID, ToStatus: goal.Status}).Error
+	})
 	if err != nil {
 		return nil, types.GoalsAddOutput{}, err
 	}
@@ -117,10 +185,37 @@ func (h *GoalsHandler) GoalsUpdate(ctx context.Context, req *mcp.CallToolRequest
 		return nil, types.GoalsUpdateOutput{}, fmt.Errorf("id required")
 	}
 
+	var goal models.Goal
+	err := h.server.GetDB().Where("id = ?", input.ID).Limit(1).Find(&goal).Error
+	if err != nil {
+		return nil, types.GoalsUpdateOutput{}, err
+	}
+	if goal.ID == 0 {
+		return nil, types.GoalsUpdateOutput{Updated: 0}, nil
+	}
+
 	updates := make(map[string]interface{})
 
-	if input.Status != nil {
-		updates["status"] = *input.Status
+	statusChanged := false
+	var w *workflow
+	if input.Status != nil && *input.Status != goal.Status {
+		w, err = loadWorkflow(h.server.GetDB())
+		if err != nil {
+			return nil, types.GoalsUpdateOutput{}, err
+		}
+		if _, ok := w.status(*input.Status); !ok {
+			return nil, types.GoalsUpdateOutput{}, fmt.Errorf("unknown status %q (allowed: %s)", *input.Status, strings.Join(w.names(), ", "))
+		}
+		if !w.canTransition(goal.Status, *input.Status) {
+			return nil, types.GoalsUpdateOutput{}, fmt.Errorf("transition from %q to %q is not allowed by the goal workflow", goal.Status, *input.Status)
+		}
+		statusChanged = true
+	}
+	if input.Title != nil {
+		if strings.TrimSpace(*input.Title) == "" {
+			return nil, types.GoalsUpdateOutput{}, fmt.Errorf("title cannot be empty")
+		}
+		updates["title"] = *input.Title
 	}
 	if input.Notes != nil {
 		updates["notes"] = *input.Notes
@@ -128,15 +223,77 @@ func (h *GoalsHandler) GoalsUpdate(ctx context.Context, req *mcp.CallToolRequest
 	if input.Priority != nil {
 		updates["priority"] = *input.Priority
 	}
+	if input.DueDate != nil {
+		if *input.DueDate == "" {
+			updates["due_at"] = nil
+		} else {
+			due, err := parseDueDate(*input.DueDate)
+			if err != nil {
+				return nil, types.GoalsUpdateOutput{}, err
+			}
+			updates["due_at"] = due
+		}
+	}
+	if input.Tags != nil {
+		updates["tags"] = joinTags(input.Tags)
+	}
+	if input.Owner != nil {
+		updates["owner"] = strings.TrimSpace(*input.Owner)
+	}
+	if input.Milestone != nil {
+		updates["milestone"] = strings.TrimSpace(*input.Milestone)
+	}
+	if input.AutoComplete != nil {
+		updates["auto_complete"] = *input.AutoComplete
+	}
 
-	if len(updates) == 0 {
+	if len(updates) == 0 && !statusChanged {
 		return nil, types.GoalsUpdateOutput{Updated: 0}, nil
 	}
 
-	result := h.server.GetDB().Model(&models.Goal{}).Where("id = ?", input.ID).Updates(updates)
-	if result.Error != nil {
-		return nil, types.GoalsUpdateOutput{}, result.Error
+	err = h.server.GetDB().Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(&goal).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if statusChanged {
+			return changeStatus(tx, w, goal.ID, goal.Status, *input.Status, "")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, types.GoalsUpdateOutput{}, err
 	}
 
-	return nil, types.GoalsUpdateOutput{Updated: int(result.RowsAffected)}, nil
+	return nil, types.GoalsUpdateOutput{Updated: 1}, nil
+}
+
+// toTypesGoal converts a database goal into its MCP representation.
+func toTypesGoal(g models.Goal) types.Goal {
+	dueDate := ""
+	if g.DueAt != nil {
+		dueDate = g.DueAt.Format("2006-01-02")
+	}
+	completedAt := ""
+	if g.CompletedAt != nil {
+		completedAt = g.CompletedAt.Format("2006-01-02 15:04:05")
+	}
+	return types.Goal{
+		ID:           int(g.ID),
+		Title:        g.Title,
+		Priority:     g.Priority,
+		Status:       g.Status,
+		Notes:        g.Notes,
+		DueDate:      dueDate,
+		Tags:         splitTags(g.Tags),
+		Owner:        g.Owner,
+		Milestone:    g.Milestone,
+		AutoComplete: g.AutoComplete,
+		Source:       g.Source,
+		SourceRef:    g.SourceRef,
+		CreatedAt:    g.CreatedAt.Format("2006-01-02 15:04:05"),
+		CompletedAt:  completedAt,
+		UpdatedAt:    g.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
 }
@@ -0,0 +1,190 @@
+package goals
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// listRepoFiles returns the files under dir (relative to root) that are not
+// ignored by git, as slash-separated paths relative to root. It asks git when
+// root is a git checkout and otherwise walks the tree, applying the
+// .gitignore files it finds along the way.
+func listRepoFiles(ctx context.Context, root, dir string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", root, "ls-files", "-z", "--cached", "--others", "--exclude-standard", "--", dir)
+	if output, err := cmd.Output(); err == nil {
+		var files []string
+		for _, file := range bytes.Split(output, []byte{0}) {
+			if len(file) == 0 {
+				continue
+			}
+			// Deleted but not yet staged files are still listed by git
+			if info, err := os.Stat(filepath.Join(root, string(file))); err == nil && info.Mode().IsRegular() {
+				files = append(files, string(file))
+			}
+		}
+		return files, nil
+	}
+
+	return walkRepoFiles(root, dir)
+}
+
+// walkRepoFiles is the fallback for listRepoFiles outside of a git checkout.
+func walkRepoFiles(root, dir string) ([]string, error) {
+	var files []string
+	var rules []ignoreRule
+
+	dir = path.Clean(filepath.ToSlash(dir))
+	if dir == "." {
+		dir = ""
+	}
+	// .gitignore files above the scanned directory still apply to it
+	for parent := path.Dir(dir); parent != "." && parent != "/"; parent = path.Dir(parent) {
+		rules = append(readIgnoreFile(root, parent), rules...)
+	}
+	if dir != "" {
+		rules = append(readIgnoreFile(root, ""), rules...)
+	}
+
+	err := filepath.WalkDir(filepath.Join(root, dir), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			start := rel == "." || rel == dir
+			if d.Name() == ".git" || (!start && ignored(rules, rel, true)) {
+				return filepath.SkipDir
+			}
+			rules = append(rules, readIgnoreFile(root, rel)...)
+			return nil
+		}
+		if d.Type().IsRegular() && !ignored(rules, rel, false) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// ignoreRule is a single .gitignore pattern, compiled to a regular expression
+// over slash-separated paths relative to the repository root.
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// readIgnoreFile parses the .gitignore in dir, if there is one. Patterns
+// without a slash match at any depth below dir; patterns with one are
+// anchored to dir.
+func readIgnoreFile(root, dir string) []ignoreRule {
+	if dir == "." {
+		dir = ""
+	}
+	data, err := os.ReadFile(filepath.Join(root, dir, ".gitignore"))
+	if err != nil {
+		return nil
+	}
+
+	prefix := ""
+	if dir != "" {
+		prefix = regexp.QuoteMeta(dir) + "/"
+	}
+
+	var rules []ignoreRule
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		expr := "^" + prefix
+		if !anchored {
+			expr += "(?:.*/)?"
+		}
+		// Anything below a matched directory is ignored with it
+		expr += globToRegexp(line) + "(?:/.*)?$"
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			continue
+		}
+		rule.re = re
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// ignored reports whether the last rule matching rel ignores it.
+func ignored(rules []ignoreRule, rel string, isDir bool) bool {
+	result := false
+	for _, rule := range rules {
+		// Files below ignored directories are never visited, so directory
+		// patterns only need to be checked against directories
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(rel) {
+			result = !rule.negate
+		}
+	}
+	return result
+}
+
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			if end := strings.IndexByte(glob[i:], ']'); end > 0 {
+				class := glob[i+1 : i+end]
+				class = strings.Replace(class, "!", "^", 1)
+				b.WriteString("[" + class + "]")
+				i += end
+				continue
+			}
+			b.WriteString(`\[`)
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,193 @@
+package goals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
+)
+
+// errDryRun rolls back the import transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+// GoalsImport creates or updates goals from a todo.txt file, a Taskwarrior
+// export or a GitHub issues JSON dump.
+//
+// Goals are matched on the task's ID in the source format, so importing the
+// same file again updates the goals it created instead of duplicating them.
+// Title, priority, tags and status always follow the source; notes, owner,
+// milestone and due date only when the source has a value, so details added
+// here are kept. Statuses are mapped through the workflow categories and set
+// directly, without checking the workflow's transitions. A dry run reports the
+// same result without changing anything.
+func (h *GoalsHandler) GoalsImport(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsImportInput) (*mcp.CallToolResult, types.GoalsImportOutput, error) {
+	parse, ok := importFormats[input.Format]
+	if !ok {
+		return nil, types.GoalsImportOutput{}, fmt.Errorf("unknown import format %q (allowed: github, taskwarrior, todotxt)", input.Format)
+	}
+	if strings.TrimSpace(input.Path) == "" {
+		return nil, types.GoalsImportOutput{}, fmt.Errorf("path required")
+	}
+
+	file := path.Clean(filepath.ToSlash(input.Path))
+	if strings.HasPrefix(file, "../") || file == ".." || path.IsAbs(file) {
+		return nil, types.GoalsImportOutput{}, fmt.Errorf("path must be inside the repository")
+	}
+
+	data, err := os.ReadFile(filepath.Join(h.server.GetRepoRoot(), filepath.FromSlash(file)))
+	if err != nil {
+		return nil, types.GoalsImportOutput{}, fmt.Errorf("failed to read %s: %v", input.Path, err)
+	}
+	external, err := parse(data, input.Path)
+	if err != nil {
+		return nil, types.GoalsImportOutput{}, err
+	}
+
+	db := h.server.GetDB()
+	w, err := loadWorkflow(db)
+	if err != nil {
+		return nil, types.GoalsImportOutput{}, err
+	}
+
+	output := types.GoalsImportOutput{Goals: []types.ImportedGoal{}, DryRun: input.DryRun}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var existing []models.Goal
+		if err := tx.Where("source = ?", input.Format).Find(&existing).Error; err != nil {
+			return err
+		}
+		byExternalID := make(map[string]models.Goal, len(existing))
+		for _, g := range existing {
+			byExternalID[g.ExternalID] = g
+		}
+
+		done := w.statusesIn(categoryDone)
+		for _, ext := range external {
+			status := w.statusFor(ext.state)
+			result := types.ImportedGoal{ExternalID: ext.externalID, Title: ext.title, Status: status}
+
+			goal, found := byExternalID[ext.externalID]
+			if !found {
+				goal = models.Goal{
+					Title:      ext.title,
+					Priority:   ext.priority,
+					Status:     status,
+					Notes:      ext.notes,
+					DueAt:      ext.due,
+					Tags:       joinTags(ext.tags),
+					Owner:      ext.owner,
+					Milestone:  ext.milestone,
+					Source:     input.Format,
+					ExternalID: ext.externalID,
+					SourceRef:  ext.ref,
+				}
+				if ext.created != nil {
+					goal.CreatedAt = *ext.created
+				}
+				if slices.Contains(done, status) {
+					completed := time.Now()
+					if ext.completed != nil {
+						completed = *ext.completed
+					}
+					goal.CompletedAt = &completed
+				}
+				if err := tx.Create(&goal).Error; err != nil {
+					return err
+				}
+				if err := tx.Create(&models.GoalStatusChange{GoalID: goal.ID, ToStatus: status}).Error; err != nil {
+					return err
+				}
+				byExternalID[ext.externalID] = goal
+				result.GoalID, result.Action = int(goal.ID), "created"
+				output.Created++
+				output.Goals = append(output.Goals, result)
+				continue
+			}
+
+			result.GoalID = int(goal.ID)
+			updates := importUpdates(goal, ext)
+			if len(updates) == 0 && goal.Status == status {
+				result.Action = "unchanged"
+				output.Unchanged++
+				output.Goals = append(output.Goals, result)
+				continue
+			}
+			if len(updates) > 0 {
+				if err := tx.Model(&goal).Updates(updates).Error; err != nil {
+					return err
+				}
+			}
+			if goal.Status != status {
+				if err := changeStatus(tx, w, goal.ID, goal.Status, status, ""); err != nil {
+					return err
+				}
+				if ext.completed != nil && slices.Contains(done, status) {
+					if err := tx.Model(&goal).Update("completed_at", *ext.completed).Error; err != nil {
+						return err
+					}
+				}
+			}
+			result.Action = "updated"
+			output.Updated++
+			output.Goals = append(output.Goals, result)
+		}
+
+		if input.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, types.GoalsImportOutput{}, err
+	}
+
+	if input.DryRun {
+		// IDs assigned inside the rolled back transaction do not exist
+		for i := range output.Goals {
+			if output.Goals[i].Action == "created" {
+				output.Goals[i].GoalID = 0
+			}
+		}
+	}
+	return nil, output, nil
+}
+
+// importUpdates returns the columns of an existing goal that differ from the
+// imported task.
+func importUpdates(goal models.Goal, ext externalGoal) map[string]interface{} {
+	updates := make(map[string]interface{})
+	if goal.Title != ext.title {
+		updates["title"] = ext.title
+	}
+	if goal.Priority != ext.priority {
+		updates["priority"] = ext.priority
+	}
+	if tags := joinTags(ext.tags); goal.Tags != tags {
+		updates["tags"] = tags
+	}
+	if goal.SourceRef != ext.ref {
+		updates["source_ref"] = ext.ref
+	}
+	if ext.notes != "" && goal.Notes != ext.notes {
+		updates["notes"] = ext.notes
+	}
+	if ext.owner != "" && goal.Owner != ext.owner {
+		updates["owner"] = ext.owner
+	}
+	if ext.milestone != "" && goal.Milestone != ext.milestone {
+		updates["milestone"] = ext.milestone
+	}
+	if ext.due != nil && (goal.DueAt == nil || !goal.DueAt.Equal(*ext.due)) {
+		updates["due_at"] = *ext.due
+	}
+	return updates
+}
@@ -0,0 +1,469 @@
+package goals
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thornzero/project-manager/internal/models"
+)
+
+// externalGoal is a task read from another tool, mapped onto goal fields.
+// Its state is one of the workflow categories; the import picks the matching
+// status of the project workflow.
+type externalGoal struct {
+	externalID string
+	title      string
+	notes      string
+	priority   int
+	state      string
+	tags       []string
+	due        *time.Time
+	owner      string
+	milestone  string
+	ref        string
+	created    *time.Time
+	completed  *time.Time
+}
+
+// importFormats parse a file exported by another tool. The file name is used
+// for source references.
+var importFormats = map[string]func(data []byte, file string) ([]externalGoal, error){
+	"todotxt":     parseTodoTxt,
+	"taskwarrior": parseTaskwarrior,
+	"github":      parseGitHubIssues,
+}
+
+// todo.txt (http://todotxt.org): one task per line, "x" marks completed tasks,
+// (A)-(Z) is the priority, +project and @context become tags and key:value
+// pairs carry the due date and an optional id.
+
+var (
+	todoTxtDate     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	todoTxtPriority = regexp.MustCompile(`^\(([A-Z])\)$`)
+	todoTxtKeyValue = regexp.MustCompile(`^([A-Za-z][\w-]*):([^\s/][^\s]*)$`)
+)
+
+func parseTodoTxt(data []byte, file string) ([]externalGoal, error) {
+	var goals []externalGoal
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		g := externalGoal{priority: 100, state: categoryTodo, ref: fmt.Sprintf("%s:%d", file, line)}
+		if fields[0] == "x" {
+			g.state = categoryDone
+			fields = fields[1:]
+			if len(fields) > 0 && todoTxtDate.MatchString(fields[0]) {
+				g.completed = parseDatePtr(fields[0])
+				fields = fields[1:]
+			}
+		} else if len(fields) > 0 {
+			if m := todoTxtPriority.FindStringSubmatch(fields[0]); m != nil {
+				g.priority = todoTxtPriorityValue(m[1][0])
+				fields = fields[1:]
+			}
+		}
+		if len(fields) > 0 && todoTxtDate.MatchString(fields[0]) {
+			g.created = parseDatePtr(fields[0])
+			fields = fields[1:]
+		}
+
+		var words []string
+		for _, field := range fields {
+			switch {
+			case len(field) > 1 && (field[0] == '+' || field[0] == '@'):
+				g.tags = append(g.tags, field[1:])
+				continue
+			case todoTxtKeyValue.MatchString(field):
+				m := todoTxtKeyValue.FindStringSubmatch(field)
+				switch m[1] {
+				case "due":
+					if due, err := parseDueDate(m[2]); err == nil {
+						g.due = &due
+						continue
+					}
+				case "id":
+					g.externalID = m[2]
+					continue
+				case "pri":
+					if len(m[2]) == 1 && m[2][0] >= 'A' && m[2][0] <= 'Z' {
+						g.priority = todoTxtPriorityValue(m[2][0])
+						continue
+					}
+				}
+			}
+			words = append(words, field)
+		}
+
+		g.title = strings.Join(words, " ")
+		if g.title == "" {
+			continue
+		}
+		if g.externalID == "" {
+			// Without an id the text is all there is to recognize a task by
+			sum := sha1.Sum([]byte(g.title))
+			g.externalID = hex.EncodeToString(sum[:6])
+		}
+		goals = append(goals, g)
+	}
+	return goals, scanner.Err()
+}
+
+// todoTxtPriorityValue maps (A) to 10, (B) to 20 and so on.
+func todoTxtPriorityValue(letter byte) int {
+	return int(letter-'A'+1) * 10
+}
+
+func renderTodoTxt(e *goalExport) (string, error) {
+	var b strings.Builder
+	for _, g := range e.goals {
+		category := e.category(g)
+		var parts []string
+		if category == categoryDone || category == categoryCancelled {
+			parts = append(parts, "x")
+			if g.CompletedAt != nil {
+				parts = append(parts, g.CompletedAt.Format("2006-01-02"))
+			}
+		} else if g.Priority < 100 {
+			letter := byte('A' + min(max((g.Priority-1)/10, 0), 25))
+			parts = append(parts, "("+string(letter)+")")
+		}
+		if !g.CreatedAt.IsZero() {
+			parts = append(parts, g.CreatedAt.Format("2006-01-02"))
+		}
+		parts = append(parts, strings.Join(strings.Fields(g.Title), " "))
+		for _, tag := range splitTags(g.Tags) {
+			parts = append(parts, "+"+strings.ReplaceAll(tag, " ", "_"))
+		}
+		if g.DueAt != nil {
+			parts = append(parts, "due:"+g.DueAt.Format("2006-01-02"))
+		}
+		parts = append(parts, "id:"+externalIDFor(g, "todotxt"))
+		b.WriteString(strings.Join(parts, " ") + "\n")
+	}
+	return b.String(), nil
+}
+
+// Taskwarrior: the JSON array written by `task export`.
+
+const taskwarriorTime = "20060102T150405Z"
+
+type taskwarriorTask struct {
+	UUID        string                  `json:"uuid"`
+	Description string                  `json:"description"`
+	Status      string                  `json:"status"`
+	Entry       string                  `json:"entry,omitempty"`
+	Start       string                  `json:"start,omitempty"`
+	End         string                  `json:"end,omitempty"`
+	Due         string                  `json:"due,omitempty"`
+	Priority    string                  `json:"priority,omitempty"`
+	Project     string                  `json:"project,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+	Annotations []taskwarriorAnnotation `json:"annotations,omitempty"`
+}
+
+type taskwarriorAnnotation struct {
+	Entry       string `json:"entry,omitempty"`
+	Description string `json:"description"`
+}
+
+func parseTaskwarrior(data []byte, file string) ([]externalGoal, error) {
+	var tasks []taskwarriorTask
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return nil, fmt.Errorf("invalid Taskwarrior export: %v", err)
+	}
+
+	var goals []externalGoal
+	for i, t := range tasks {
+		if t.Status == "recurring" {
+			// Recurrence templates; their pending instances are exported too
+			continue
+		}
+		if t.UUID == "" {
+			return nil, fmt.Errorf("task %d in %s has no uuid", i+1, file)
+		}
+
+		g := externalGoal{
+			externalID: t.UUID,
+			title:      strings.TrimSpace(t.Description),
+			priority:   map[string]int{"H": 10, "M": 50, "L": 90}[t.Priority],
+			tags:       t.Tags,
+			milestone:  t.Project,
+			ref:        file,
+			created:    parseTaskwarriorTime(t.Entry),
+			completed:  parseTaskwarriorTime(t.End),
+			due:        parseTaskwarriorTime(t.Due),
+		}
+		if g.priority == 0 {
+			g.priority = 100
+		}
+		switch t.Status {
+		case "completed":
+			g.state = categoryDone
+		case "deleted":
+			g.state = categoryCancelled
+			g.completed = nil
+		case "waiting":
+			g.state = categoryWaiting
+		default:
+			g.state = categoryTodo
+			if t.Start != "" {
+				g.state = categoryActive
+			}
+		}
+		var notes []string
+		for _, a := range t.Annotations {
+			notes = append(notes, a.Description)
+		}
+		g.notes = strings.Join(notes, "\n")
+		goals = append(goals, g)
+	}
+	return goals, nil
+}
+
+func parseTaskwarriorTime(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse(taskwarriorTime, value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func renderTaskwarrior(e *goalExport) (string, error) {
+	tasks := make([]taskwarriorTask, 0, len(e.goals))
+	for _, g := range e.goals {
+		t := taskwarriorTask{
+			UUID:        externalIDFor(g, "taskwarrior"),
+			Description: g.Title,
+			Status:      "pending",
+			Project:     g.Milestone,
+			Tags:        splitTags(g.Tags),
+		}
+		switch e.category(g) {
+		case categoryDone:
+			t.Status = "completed"
+		case categoryCancelled:
+			t.Status = "deleted"
+		case categoryWaiting:
+			t.Status = "waiting"
+		}
+		switch {
+		case g.Priority <= 30:
+			t.Priority = "H"
+		case g.Priority <= 70:
+			t.Priority = "M"
+		case g.Priority < 100:
+			t.Priority = "L"
+		}
+		if !g.CreatedAt.IsZero() {
+			t.Entry = g.CreatedAt.UTC().Format(taskwarriorTime)
+		}
+		if g.CompletedAt != nil {
+			t.End = g.CompletedAt.UTC().Format(taskwarriorTime)
+		}
+		if g.DueAt != nil {
+			t.Due = g.DueAt.UTC().Format(taskwarriorTime)
+		}
+		if notes := strings.TrimSpace(g.Notes); notes != "" {
+			t.Annotations = []taskwarriorAnnotation{{Entry: t.Entry, Description: notes}}
+		}
+		tasks = append(tasks, t)
+	}
+	return marshalExport(tasks)
+}
+
+// GitHub issues: the JSON written by `gh issue list --json
+// number,title,body,state,stateReason,labels,assignees,milestone,createdAt,closedAt,url`.
+
+type githubIssue struct {
+	Number      int              `json:"number"`
+	Title       string           `json:"title"`
+	Body        string           `json:"body"`
+	State       string           `json:"state"`
+	StateReason string           `json:"stateReason,omitempty"`
+	Labels      []githubLabel    `json:"labels"`
+	Assignees   []githubUser     `json:"assignees"`
+	Milestone   *githubMilestone `json:"milestone"`
+	CreatedAt   *time.Time       `json:"createdAt,omitempty"`
+	ClosedAt    *time.Time       `json:"closedAt,omitempty"`
+	URL         string           `json:"url,omitempty"`
+}
+
+type githubLabel struct {
+	Name string `json:"name"`
+}
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+type githubMilestone struct {
+	Title string     `json:"title"`
+	DueOn *time.Time `json:"dueOn,omitempty"`
+}
+
+// githubPriorityLabel matches labels such as "P1" or "priority: high".
+var githubPriorityLabel = regexp.MustCompile(`(?i)^(?:p([0-4])|priority:\s*(critical|high|medium|low))$`)
+
+func parseGitHubIssues(data []byte, file string) ([]externalGoal, error) {
+	var issues []githubIssue
+	if err := json.Unmarshal(data, &issues); err != nil {
+		return nil, fmt.Errorf("invalid GitHub issues JSON: %v", err)
+	}
+
+	var goals []externalGoal
+	for i, issue := range issues {
+		if issue.Number == 0 {
+			return nil, fmt.Errorf("issue %d in %s has no number", i+1, file)
+		}
+
+		g := externalGoal{
+			externalID: strconv.Itoa(issue.Number),
+			title:      strings.TrimSpace(issue.Title),
+			notes:      strings.TrimSpace(issue.Body),
+			priority:   100,
+			state:      categoryTodo,
+			ref:        issue.URL,
+			created:    issue.CreatedAt,
+		}
+		if g.ref == "" {
+			g.ref = fmt.Sprintf("%s#%d", file, issue.Number)
+		}
+		if strings.EqualFold(issue.State, "closed") {
+			g.state = categoryDone
+			g.completed = issue.ClosedAt
+			if strings.EqualFold(issue.StateReason, "not_planned") {
+				g.state = categoryCancelled
+				g.completed = nil
+			}
+		}
+		for _, label := range issue.Labels {
+			if m := githubPriorityLabel.FindStringSubmatch(label.Name); m != nil {
+				if m[1] != "" {
+					n, _ := strconv.Atoi(m[1])
+					g.priority = (n + 1) * 10
+				} else {
+					g.priority = map[string]int{"critical": 10, "high": 20, "medium": 50, "low": 90}[strings.ToLower(m[2])]
+				}
+				continue
+			}
+			g.tags = append(g.tags, label.Name)
+		}
+		if len(issue.Assignees) > 0 {
+			g.owner = issue.Assignees[0].Login
+		}
+		if issue.Milestone != nil {
+			g.milestone = issue.Milestone.Title
+			g.due = issue.Milestone.DueOn
+		}
+		goals = append(goals, g)
+	}
+	return goals, nil
+}
+
+func renderGitHubIssues(e *goalExport) (string, error) {
+	issues := make([]githubIssue, 0, len(e.goals))
+	for _, g := range e.goals {
+		number := int(g.ID)
+		if g.Source == "github" {
+			if n, err := strconv.Atoi(g.ExternalID); err == nil {
+				number = n
+			}
+		}
+
+		issue := githubIssue{
+			Number:    number,
+			Title:     g.Title,
+			Body:      g.Notes,
+			State:     "OPEN",
+			Labels:    []githubLabel{},
+			Assignees: []githubUser{},
+			ClosedAt:  g.CompletedAt,
+		}
+		if !g.CreatedAt.IsZero() {
+			created := g.CreatedAt.UTC()
+			issue.CreatedAt = &created
+		}
+		if issue.ClosedAt != nil {
+			closed := issue.ClosedAt.UTC()
+			issue.ClosedAt = &closed
+		}
+		switch e.category(g) {
+		case categoryDone:
+			issue.State, issue.StateReason = "CLOSED", "COMPLETED"
+		case categoryCancelled:
+			issue.State, issue.StateReason = "CLOSED", "NOT_PLANNED"
+		}
+		if g.Priority < 100 {
+			issue.Labels = append(issue.Labels, githubLabel{Name: fmt.Sprintf("P%d", min(max(g.Priority/10-1, 0), 4))})
+		}
+		for _, tag := range splitTags(g.Tags) {
+			issue.Labels = append(issue.Labels, githubLabel{Name: tag})
+		}
+		if g.Owner != "" {
+			issue.Assignees = append(issue.Assignees, githubUser{Login: g.Owner})
+		}
+		if g.Milestone != "" {
+			issue.Milestone = &githubMilestone{Title: g.Milestone}
+		}
+		if g.Source == "github" && strings.HasPrefix(g.SourceRef, "http") {
+			issue.URL = g.SourceRef
+		}
+		issues = append(issues, issue)
+	}
+	return marshalExport(issues)
+}
+
+// category returns the workflow category of a goal's status.
+func (e *goalExport) category(g models.Goal) string {
+	st, _ := e.workflow.status(g.Status)
+	return st.Category
+}
+
+// externalIDFor returns the ID a goal is exported under: its original ID when
+// it was imported from the same format, otherwise one derived from the goal ID
+// so repeated exports agree.
+func externalIDFor(g models.Goal, format string) string {
+	if g.Source == format && g.ExternalID != "" {
+		return g.ExternalID
+	}
+	if format == "taskwarrior" {
+		// Taskwarrior requires a UUID; derive a name-based (version 5 style) one
+		sum := sha1.Sum([]byte(fmt.Sprintf("project-manager/goal/%d", g.ID)))
+		sum[6] = sum[6]&0x0f | 0x50
+		sum[8] = sum[8]&0x3f | 0x80
+		h := hex.EncodeToString(sum[:16])
+		return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+	}
+	return strconv.Itoa(int(g.ID))
+}
+
+func marshalExport(v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+func parseDatePtr(value string) *time.Time {
+	t, err := parseDueDate(value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,239 @@
+package goals
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestParseTodoTxt(t *testing.T) {
+	data := "(A) 2025-01-02 Call Mom +family @phone due:2025-01-10 id:42\n" +
+		"\n" +
+		"x 2025-01-05 2025-01-01 Pay rent pri:B\n" +
+		"Visit https://example.com today\n"
+
+	goals, err := parseTodoTxt([]byte(data), "todo.txt")
+	if err != nil {
+		t.Fatalf("parseTodoTxt() unexpected error: %v", err)
+	}
+	if len(goals) != 3 {
+		t.Fatalf("parseTodoTxt() = %d goals, want 3", len(goals))
+	}
+
+	first := goals[0]
+	if first.externalID != "42" || first.title != "Call Mom" || first.priority != 10 || first.state != categoryTodo ||
+		!reflect.DeepEqual(first.tags, []string{"family", "phone"}) || first.due == nil || first.due.Format("2006-01-02") != "2025-01-10" ||
+		first.created == nil || first.ref != "todo.txt:1" {
+		t.Errorf("parseTodoTxt() first = %+v", first)
+	}
+
+	second := goals[1]
+	if second.state != categoryDone || second.priority != 20 || second.completed == nil || second.completed.Format("2006-01-02") != "2025-01-05" || second.title != "Pay rent" {
+		t.Errorf("parseTodoTxt() second = %+v", second)
+	}
+
+	// URLs are not key:value pairs, and tasks without an id get a stable one
+	third := goals[2]
+	if third.title != "Visit https://example.com today" || third.externalID == "" {
+		t.Errorf("parseTodoTxt() third = %+v", third)
+	}
+	again, _ := parseTodoTxt([]byte(data), "todo.txt")
+	if again[2].externalID != third.externalID {
+		t.Errorf("parseTodoTxt() external ID not stable: %q != %q", again[2].externalID, third.externalID)
+	}
+}
+
+func TestParseImportFormats(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    string
+		data      string
+		want      []string // externalID:state:priority:title
+		wantError bool
+	}{
+		{
+			name:   "Taskwarrior statuses and priorities",
+			format: "taskwarrior",
+			data: `[
+				{"uuid":"a-1","description":"Write spec","status":"pending","priority":"H","start":"20250101T090000Z","project":"v1","tags":["docs"]},
+				{"uuid":"a-2","description":"Old idea","status":"deleted"},
+				{"uuid":"a-3","description":"Weekly sync","status":"recurring"},
+				{"uuid":"a-4","description":"Ship","status":"completed","end":"20250102T100000Z","priority":"L"}
+			]`,
+			want: []string{"a-1:active:10:Write spec", "a-2:cancelled:100:Old idea", "a-4:done:90:Ship"},
+		},
+		{
+			name:      "Taskwarrior task without uuid",
+			format:    "taskwarrior",
+			data:      `[{"description":"No id","status":"pending"}]`,
+			wantError: true,
+		},
+		{
+			name:   "GitHub issues",
+			format: "github",
+			data: `[
+				{"number":7,"title":"Crash on start","state":"OPEN","labels":[{"name":"bug"},{"name":"P1"}],"assignees":[{"login":"sam"}],"milestone":{"title":"v1"}},
+				{"number":8,"title":"Won't fix","state":"CLOSED","stateReason":"NOT_PLANNED","labels":[]},
+				{"number":9,"title":"Done","state":"closed","labels":[{"name":"priority: high"}]}
+			]`,
+			want: []string{"7:todo:20:Crash on start", "8:cancelled:100:Won't fix", "9:done:20:Done"},
+		},
+		{
+			name:      "Invalid GitHub JSON",
+			format:    "github",
+			data:      `{"number":1}`,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goals, err := importFormats[tt.format]([]byte(tt.data), "tasks.json")
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("parse() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parse() unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, g := range goals {
+				got = append(got, strings.Join([]string{g.externalID, g.state, strconv.Itoa(g.priority), g.title}, ":"))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoalsHandler_GoalsImport(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	ctx := context.Background()
+
+	writeTestFile(t, tempDir, "issues.json", `[
+		{"number":7,"title":"Crash on start","state":"OPEN","labels":[{"name":"bug"}],"assignees":[{"login":"sam"}]},
+		{"number":8,"title":"Add dark mode","state":"CLOSED","stateReason":"COMPLETED","closedAt":"2025-01-02T10:00:00Z","labels":[]}
+	]`)
+
+	if _, _, err := handler.GoalsImport(ctx, nil, types.GoalsImportInput{Format: "jira", Path: "issues.json"}); err == nil {
+		t.Errorf("GoalsImport() with unknown format expected error, got nil")
+	}
+	if _, _, err := handler.GoalsImport(ctx, nil, types.GoalsImportInput{Format: "github", Path: "missing.json"}); err == nil {
+		t.Errorf("GoalsImport() with missing file expected error, got nil")
+	}
+	if _, _, err := handler.GoalsImport(ctx, nil, types.GoalsImportInput{Format: "github", Path: "../x"}); err == nil || !strings.Contains(err.Error(), "inside the repository") {
+		t.Errorf("GoalsImport() of a file outside the repository = %v, want it rejected", err)
+	}
+
+	// A dry run reports the import without creating anything
+	_, output, err := handler.GoalsImport(ctx, nil, types.GoalsImportInput{Format: "github", Path: "issues.json", DryRun: true})
+	if err != nil {
+		t.Fatalf("GoalsImport() dry run unexpected error: %v", err)
+	}
+	if output.Created != 2 || !output.DryRun || output.Goals[0].GoalID != 0 {
+		t.Errorf("GoalsImport() dry run = %+v, want 2 goals to create", output)
+	}
+	var count int64
+	srv.GetDB().Table("goals").Count(&count)
+	if count != 0 {
+		t.Fatalf("GoalsImport() dry run created %d goals", count)
+	}
+
+	_, output, err = handler.GoalsImport(ctx, nil, types.GoalsImportInput{Format: "github", Path: "issues.json"})
+	if err != nil {
+		t.Fatalf("GoalsImport() unexpected error: %v", err)
+	}
+	if output.Created != 2 || output.Goals[1].Status != "done" {
+		t.Errorf("GoalsImport() = %+v, want 2 created, the second done", output)
+	}
+
+	// Re-importing updates the goals matched by issue number
+	writeTestFile(t, tempDir, "issues.json", `[
+		{"number":7,"title":"Crash on start","state":"CLOSED","labels":[{"name":"bug"}],"assignees":[{"login":"sam"}]},
+		{"number":8,"title":"Add dark mode","state":"CLOSED","stateReason":"COMPLETED","closedAt":"2025-01-02T10:00:00Z","labels":[]}
+	]`)
+	_, output, err = handler.GoalsImport(ctx, nil, types.GoalsImportInput{Format: "github", Path: "issues.json"})
+	if err != nil {
+		t.Fatalf("GoalsImport() unexpected error: %v", err)
+	}
+	if output.Created != 0 || output.Updated != 1 || output.Unchanged != 1 {
+		t.Errorf("GoalsImport() again = %+v, want 1 updated and 1 unchanged", output)
+	}
+
+	_, list, err := handler.GoalsList(ctx, nil, types.GoalsListInput{Completed: true, Sort: "created"})
+	if err != nil {
+		t.Fatalf("GoalsList() unexpected error: %v", err)
+	}
+	if len(list.Goals) != 2 || list.Goals[0].Owner != "sam" || list.Goals[1].CompletedAt != "2025-01-02 10:00:00" {
+		t.Errorf("GoalsList() = %+v, want both imported goals done", list.Goals)
+	}
+}
+
+func TestGoalsHandler_GoalsExportInterchange(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	ctx := context.Background()
+
+	str := func(s string) *string { return &s }
+	prio := 20
+	if _, _, err := handler.GoalsAdd(ctx, nil, types.GoalsAddInput{Title: "Ship login", Priority: &prio, Tags: []string{"auth"}, DueDate: str("2025-07-01"), Milestone: str("v1")}); err != nil {
+		t.Fatalf("GoalsAdd() unexpected error: %v", err)
+	}
+
+	_, output, err := handler.GoalsExport(ctx, nil, types.GoalsExportInput{Formats: []string{"todotxt", "taskwarrior", "github"}, OutputDir: str("out")})
+	if err != nil {
+		t.Fatalf("GoalsExport() unexpected error: %v", err)
+	}
+	if len(output.Files) != 3 {
+		t.Fatalf("GoalsExport() files = %+v, want 3", output.Files)
+	}
+
+	// Every export can be read back by the matching importer. Taskwarrior only
+	// knows high, medium and low, so priority 20 comes back as high.
+	wantPriority := map[string]int{"todotxt": 20, "taskwarrior": 10, "github": 20}
+	for _, f := range output.Files {
+		data, err := os.ReadFile(f.Path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", f.Path, err)
+		}
+		goals, err := importFormats[f.Format](data, filepath.Base(f.Path))
+		if err != nil {
+			t.Fatalf("%s: parse unexpected error: %v", f.Format, err)
+		}
+		if len(goals) != 1 {
+			t.Fatalf("%s: parsed %d goals, want 1", f.Format, len(goals))
+		}
+		g := goals[0]
+		if g.title != "Ship login" || g.priority != wantPriority[f.Format] || g.state != categoryTodo || !reflect.DeepEqual(g.tags, []string{"auth"}) {
+			t.Errorf("%s: round trip = %+v", f.Format, g)
+		}
+	}
+}
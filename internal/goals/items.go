@@ -0,0 +1,221 @@
+package goals
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/markdown"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
+)
+
+// GoalsAddItems appends acceptance criteria to a goal.
+func (h *GoalsHandler) GoalsAddItems(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsAddItemsInput) (*mcp.CallToolResult, types.GoalsAddItemsOutput, error) {
+	if input.GoalID == 0 || len(input.Items) == 0 {
+		return nil, types.GoalsAddItemsOutput{}, fmt.Errorf("goal_id and items are required")
+	}
+
+	var goal models.Goal
+	if err := h.server.GetDB().First(&goal, input.GoalID).Error; err != nil {
+		return nil, types.GoalsAddItemsOutput{}, fmt.Errorf("goal %d not found", input.GoalID)
+	}
+
+	if err := addItems(h.server.GetDB(), goal.ID, input.Items); err != nil {
+		return nil, types.GoalsAddItemsOutput{}, err
+	}
+
+	items, err := loadItems(h.server.GetDB(), goal.ID)
+	if err != nil {
+		return nil, types.GoalsAddItemsOutput{}, err
+	}
+
+	return nil, types.GoalsAddItemsOutput{Items: toTypesItems(items)}, nil
+}
+
+// GoalsCheckItem checks off (or unchecks) an acceptance criterion.
+//
+// When the last open criterion is checked and the goal has auto-complete
+// enabled, the goal is moved to the workflow's done status, provided the
+// workflow allows that transition from the goal's current status.
+func (h *GoalsHandler) GoalsCheckItem(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsCheckItemInput) (*mcp.CallToolResult, types.GoalsCheckItemOutput, error) {
+	if input.GoalID == 0 || input.ItemID == 0 {
+		return nil, types.GoalsCheckItemOutput{}, fmt.Errorf("goal_id and item_id are required")
+	}
+	checked := true
+	if input.Checked != nil {
+		checked = *input.Checked
+	}
+
+	db := h.server.GetDB()
+	var goal models.Goal
+	if err := db.First(&goal, input.GoalID).Error; err != nil {
+		return nil, types.GoalsCheckItemOutput{}, fmt.Errorf("goal %d not found", input.GoalID)
+	}
+	var item models.GoalItem
+	if err := db.Where("id = ? AND goal_id = ?", input.ItemID, goal.ID).First(&item).Error; err != nil {
+		return nil, types.GoalsCheckItemOutput{}, fmt.Errorf("item %d not found on goal %d", input.ItemID, goal.ID)
+	}
+
+	w, err := loadWorkflow(db)
+	if err != nil {
+		return nil, types.GoalsCheckItemOutput{}, err
+	}
+
+	var items []models.GoalItem
+	message := ""
+	err = db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"checked": checked, "checked_at": nil}
+		if checked {
+			updates["checked_at"] = time.Now()
+		}
+		if err := tx.Model(&item).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		var err error
+		items, err = loadItems(tx, goal.ID)
+		if err != nil {
+			return err
+		}
+		if !allChecked(items) || !goal.AutoComplete {
+			return nil
+		}
+
+		done := w.statusesIn(categoryDone)
+		st, _ := w.status(goal.Status)
+		switch {
+		case st.Category == categoryDone:
+			return nil
+		case !w.canTransition(goal.Status, done[0]):
+			message = fmt.Sprintf("all criteria checked, but the workflow does not allow moving from %q to %q", goal.Status, done[0])
+			return nil
+		}
+		if err := changeStatus(tx, w, goal.ID, goal.Status, done[0], ""); err != nil {
+			return err
+		}
+		message = fmt.Sprintf("all criteria checked, goal moved from %q to %q", goal.Status, done[0])
+		goal.Status = done[0]
+		return nil
+	})
+	if err != nil {
+		return nil, types.GoalsCheckItemOutput{}, err
+	}
+
+	return nil, types.GoalsCheckItemOutput{
+		Items:      toTypesItems(items),
+		AllChecked: allChecked(items),
+		Status:     goal.Status,
+		Message:    message,
+	}, nil
+}
+
+// addItems appends acceptance criteria after the goal's existing ones.
+func addItems(tx *gorm.DB, goalID uint, texts []string) error {
+	var last models.GoalItem
+	position := 0
+	if err := tx.Where("goal_id = ?", goalID).Order("position DESC").Limit(1).Find(&last).Error; err != nil {
+		return err
+	}
+	if last.ID != 0 {
+		position = last.Position + 1
+	}
+
+	for _, text := range texts {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		if err := tx.Create(&models.GoalItem{GoalID: goalID, Position: position, Text: text}).Error; err != nil {
+			return err
+		}
+		position++
+	}
+	return nil
+}
+
+// loadItems returns a goal's acceptance criteria in order.
+func loadItems(db *gorm.DB, goalID uint) ([]models.GoalItem, error) {
+	var items []models.GoalItem
+	err := db.Where("goal_id = ?", goalID).Order("position ASC, id ASC").Find(&items).Error
+	return items, err
+}
+
+// loadItemsFor returns the acceptance criteria of several goals, keyed by goal.
+func loadItemsFor(db *gorm.DB, goalIDs []uint) (map[uint][]models.GoalItem, error) {
+	byGoal := make(map[uint][]models.GoalItem)
+	if len(goalIDs) == 0 {
+		return byGoal, nil
+	}
+
+	var items []models.GoalItem
+	err := db.Where("goal_id IN ?", goalIDs).Order("position ASC, id ASC").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		byGoal[item.GoalID] = append(byGoal[item.GoalID], item)
+	}
+	return byGoal, nil
+}
+
+func allChecked(items []models.GoalItem) bool {
+	for _, item := range items {
+		if !item.Checked {
+			return false
+		}
+	}
+	return len(items) > 0
+}
+
+func toTypesItems(items []models.GoalItem) []types.GoalItem {
+	result := make([]types.GoalItem, 0, len(items))
+	for _, item := range items {
+		checkedAt := ""
+		if item.CheckedAt != nil {
+			checkedAt = item.CheckedAt.Format("2006-01-02 15:04:05")
+		}
+		result = append(result, types.GoalItem{
+			ID:        int(item.ID),
+			Text:      item.Text,
+			Checked:   item.Checked,
+			CheckedAt: checkedAt,
+		})
+	}
+	return result
+}
+
+// writeGoalMarkdown renders a goal, its metadata and its acceptance criteria
+// as a section of a markdown document.
+func writeGoalMarkdown(md *markdown.Builder, level int, goal models.Goal, items []models.GoalItem) {
+	md.AddHeader(level, fmt.Sprintf("#%d %s", goal.ID, goal.Title))
+
+	details := []string{"Status: " + goal.Status, fmt.Sprintf("Priority: %d", goal.Priority)}
+	if goal.Owner != "" {
+		details = append(details, "Owner: "+goal.Owner)
+	}
+	if goal.DueAt != nil {
+		details = append(details, "Due: "+goal.DueAt.Format("2006-01-02"))
+	}
+	if goal.Tags != "" {
+		details = append(details, "Tags: "+strings.ReplaceAll(goal.Tags, ",", ", "))
+	}
+	md.AddParagraph(strings.Join(details, ", "))
+
+	if strings.TrimSpace(goal.Notes) != "" {
+		md.AddParagraph(strings.TrimSpace(goal.Notes))
+	}
+
+	if len(items) > 0 {
+		texts := make([]string, len(items))
+		checked := make([]bool, len(items))
+		for i, item := range items {
+			texts[i] = item.Text
+			checked[i] = item.Checked
+		}
+		md.AddChecklist(texts, checked)
+	}
+}
@@ -0,0 +1,147 @@
+package goals
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/markdown"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestGoalsHandler_GoalsCheckItem(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	ctx := context.Background()
+
+	autoComplete := true
+	_, added, err := handler.GoalsAdd(ctx, nil, types.GoalsAddInput{
+		Title:        "Ship login",
+		Criteria:     []string{"Form validates input", "  "},
+		AutoComplete: &autoComplete,
+	})
+	if err != nil {
+		t.Fatalf("GoalsAdd() unexpected error: %v", err)
+	}
+
+	_, itemsOut, err := handler.GoalsAddItems(ctx, nil, types.GoalsAddItemsInput{GoalID: added.ID, Items: []string{"Errors are shown"}})
+	if err != nil {
+		t.Fatalf("GoalsAddItems() unexpected error: %v", err)
+	}
+	if len(itemsOut.Items) != 2 || itemsOut.Items[1].Text != "Errors are shown" {
+		t.Fatalf("GoalsAddItems() items = %+v, want two items in order", itemsOut.Items)
+	}
+	first, second := itemsOut.Items[0].ID, itemsOut.Items[1].ID
+
+	unchecked := false
+	tests := []struct {
+		name           string
+		input          types.GoalsCheckItemInput
+		wantAllChecked bool
+		wantStatus     string
+		wantError      bool
+	}{
+		{name: "Missing item id", input: types.GoalsCheckItemInput{GoalID: added.ID}, wantError: true},
+		{name: "Item of another goal", input: types.GoalsCheckItemInput{GoalID: added.ID, ItemID: 999}, wantError: true},
+		{name: "Check first item", input: types.GoalsCheckItemInput{GoalID: added.ID, ItemID: first}, wantStatus: "active"},
+		{name: "Uncheck first item", input: types.GoalsCheckItemInput{GoalID: added.ID, ItemID: first, Checked: &unchecked}, wantStatus: "active"},
+		{name: "Check second item", input: types.GoalsCheckItemInput{GoalID: added.ID, ItemID: second}, wantStatus: "active"},
+		{name: "Last item completes the goal", input: types.GoalsCheckItemInput{GoalID: added.ID, ItemID: first}, wantAllChecked: true, wantStatus: "done"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := handler.GoalsCheckItem(ctx, nil, tt.input)
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("GoalsCheckItem() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("GoalsCheckItem() unexpected error: %v", err)
+				return
+			}
+
+			if output.AllChecked != tt.wantAllChecked {
+				t.Errorf("GoalsCheckItem() all checked = %v, want %v", output.AllChecked, tt.wantAllChecked)
+			}
+			if output.Status != tt.wantStatus {
+				t.Errorf("GoalsCheckItem() status = %q, want %q", output.Status, tt.wantStatus)
+			}
+		})
+	}
+
+	_, list, err := handler.GoalsList(ctx, nil, types.GoalsListInput{Completed: true})
+	if err != nil {
+		t.Fatalf("GoalsList() unexpected error: %v", err)
+	}
+	if len(list.Goals) != 1 || len(list.Goals[0].Criteria) != 2 || !list.Goals[0].AutoComplete {
+		t.Errorf("GoalsList() = %+v, want the completed goal with its criteria", list.Goals)
+	}
+}
+
+func TestGoalsHandler_GoalsCheckItemWorkflow(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	ctx := context.Background()
+
+	kanban := "kanban"
+	if _, _, err := handler.GoalsWorkflowSet(ctx, nil, types.GoalsWorkflowSetInput{Preset: &kanban}); err != nil {
+		t.Fatalf("GoalsWorkflowSet() unexpected error: %v", err)
+	}
+
+	// Kanban only reaches done from in_review, so auto-complete has to wait
+	autoComplete := true
+	_, added, err := handler.GoalsAdd(ctx, nil, types.GoalsAddInput{
+		Title:        "Review gated goal",
+		Criteria:     []string{"Tests pass"},
+		AutoComplete: &autoComplete,
+	})
+	if err != nil {
+		t.Fatalf("GoalsAdd() unexpected error: %v", err)
+	}
+
+	items, err := loadItems(srv.GetDB(), uint(added.ID))
+	if err != nil || len(items) != 1 {
+		t.Fatalf("loadItems() = %v, %v; want one item", items, err)
+	}
+
+	_, output, err := handler.GoalsCheckItem(ctx, nil, types.GoalsCheckItemInput{GoalID: added.ID, ItemID: int(items[0].ID)})
+	if err != nil {
+		t.Fatalf("GoalsCheckItem() unexpected error: %v", err)
+	}
+	if output.Status != "todo" || output.Message == "" {
+		t.Errorf("GoalsCheckItem() = %+v, want goal left in todo with an explanation", output)
+	}
+}
+
+func TestWriteGoalMarkdown(t *testing.T) {
+	md := markdown.NewBuilder()
+	goal := models.Goal{ID: 7, Title: "Ship login", Status: "active", Priority: 2, Tags: "auth,ui"}
+	items := []models.GoalItem{{Text: "Form validates input", Checked: true}, {Text: "Errors are shown"}}
+
+	writeGoalMarkdown(md, 2, goal, items)
+
+	want := "## #7 Ship login\n\nStatus: active, Priority: 2, Tags: auth, ui\n\n- [x] Form validates input\n- [ ] Errors are shown\n\n"
+	if got := md.String(); got != want {
+		t.Errorf("writeGoalMarkdown() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,234 @@
+package goals
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Kinds of things a goal can be linked to.
+const (
+	linkADR       = "adr"
+	linkChangelog = "changelog"
+	linkFile      = "file"
+	linkCommit    = "commit"
+)
+
+var commitSHA = regexp.MustCompile(`^[0-9a-fA-F]{4,40}$`)
+
+// LinksAdd links a goal to an ADR, a changelog entry, a file or a git commit.
+//
+// ADRs and changelog entries must exist. Files are stored relative to the
+// repository root and must not point outside of it, but may have been deleted
+// since. Commits are expanded to their full SHA when the repository is a git
+// checkout that knows them.
+func (h *GoalsHandler) LinksAdd(ctx context.Context, req *mcp.CallToolRequest, input types.LinksAddInput) (*mcp.CallToolResult, types.LinksAddOutput, error) {
+	if input.GoalID == 0 || strings.TrimSpace(input.Target) == "" {
+		return nil, types.LinksAddOutput{}, fmt.Errorf("goal_id and target are required")
+	}
+
+	db := h.server.GetDB()
+	var count int64
+	if err := db.Model(&models.Goal{}).Where("id = ?", input.GoalID).Count(&count).Error; err != nil {
+		return nil, types.LinksAddOutput{}, err
+	}
+	if count == 0 {
+		return nil, types.LinksAddOutput{}, fmt.Errorf("goal %d not found", input.GoalID)
+	}
+
+	target, err := h.linkTarget(ctx, db, input.Kind, strings.TrimSpace(input.Target))
+	if err != nil {
+		return nil, types.LinksAddOutput{}, err
+	}
+
+	link := models.GoalLink{GoalID: uint(input.GoalID), Kind: input.Kind, Target: target}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&link)
+	if result.Error != nil {
+		return nil, types.LinksAddOutput{}, result.Error
+	}
+	added := result.RowsAffected > 0
+	if !added {
+		if err := db.Where("goal_id = ? AND kind = ? AND target = ?", link.GoalID, link.Kind, link.Target).First(&link).Error; err != nil {
+			return nil, types.LinksAddOutput{}, err
+		}
+	}
+
+	return nil, types.LinksAddOutput{Added: added, Link: toTypesLink(link)}, nil
+}
+
+// linkTarget validates a link target and returns it in the form it is stored.
+func (h *GoalsHandler) linkTarget(ctx context.Context, db *gorm.DB, kind, target string) (string, error) {
+	switch kind {
+	case linkADR:
+		var count int64
+		if err := db.Model(&models.ADR{}).Where("id = ?", target).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return "", fmt.Errorf("ADR %s not found", target)
+		}
+		return target, nil
+
+	case linkChangelog:
+		id, err := strconv.Atoi(target)
+		if err != nil {
+			return "", fmt.Errorf("changelog target must be an entry ID, got %q", target)
+		}
+		var count int64
+		if err := db.Model(&models.ChangelogEntry{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return "", fmt.Errorf("changelog entry %d not found", id)
+		}
+		return strconv.Itoa(id), nil
+
+	case linkFile:
+		root := h.server.GetRepoRoot()
+		path := target
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", fmt.Errorf("file %s is not inside the repository", target)
+		}
+		return filepath.ToSlash(rel), nil
+
+	case linkCommit:
+		if !commitSHA.MatchString(target) {
+			return "", fmt.Errorf("commit target must be a hexadecimal SHA, got %q", target)
+		}
+		cmd := exec.CommandContext(ctx, "git", "-C", h.server.GetRepoRoot(), "rev-parse", "--verify", "--quiet", target+"^{commit}")
+		if output, err := cmd.Output(); err == nil {
+			return strings.TrimSpace(string(output)), nil
+		}
+		return strings.ToLower(target), nil
+
+	default:
+		return "", fmt.Errorf("unknown link kind %q (allowed: adr, changelog, commit, file)", kind)
+	}
+}
+
+// GoalsGet returns a goal with its acceptance criteria, its dependencies and
+// everything linked to it.
+//
+// Files include those of linked changelog entries, so logging a change with a
+// goal_id is enough to connect the goal to the files it touched.
+func (h *GoalsHandler) GoalsGet(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsGetInput) (*mcp.CallToolResult, types.GoalsGetOutput, error) {
+	if input.ID == 0 {
+		return nil, types.GoalsGetOutput{}, fmt.Errorf("id required")
+	}
+
+	db := h.server.GetDB()
+	var goal models.Goal
+	if err := db.First(&goal, input.ID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, types.GoalsGetOutput{}, fmt.Errorf("goal %d not found", input.ID)
+		}
+		return nil, types.GoalsGetOutput{}, err
+	}
+
+	items, err := loadItems(db, goal.ID)
+	if err != nil {
+		return nil, types.GoalsGetOutput{}, err
+	}
+	output := types.GoalsGetOutput{
+		Goal:    toTypesGoal(goal),
+		ADRs:    []types.LinkedADR{},
+		Changes: []types.LinkedChange{},
+		Files:   []string{},
+		Commits: []string{},
+	}
+	output.Goal.Criteria = toTypesItems(items)
+
+	var dependsOn []models.Goal
+	if err := db.Joins("JOIN goal_dependencies ON goal_dependencies.depends_on_id = goals.id").
+		Where("goal_dependencies.goal_id = ?", goal.ID).
+		Order("goals.id ASC").
+		Find(&dependsOn).Error; err != nil {
+		return nil, types.GoalsGetOutput{}, err
+	}
+	for _, d := range dependsOn {
+		output.DependsOn = append(output.DependsOn, types.GoalSummary{ID: int(d.ID), Title: d.Title, Status: d.Status})
+	}
+
+	var links []models.GoalLink
+	if err := db.Where("goal_id = ?", goal.ID).Order("id ASC").Find(&links).Error; err != nil {
+		return nil, types.GoalsGetOutput{}, err
+	}
+
+	var adrIDs, changeIDs []string
+	for _, l := range links {
+		switch l.Kind {
+		case linkADR:
+			adrIDs = append(adrIDs, l.Target)
+		case linkChangelog:
+			changeIDs = append(changeIDs, l.Target)
+		case linkFile:
+			output.Files = append(output.Files, l.Target)
+		case linkCommit:
+			output.Commits = append(output.Commits, l.Target)
+		}
+	}
+
+	if len(adrIDs) > 0 {
+		var adrs []models.ADR
+		if err := db.Where("id IN ?", adrIDs).Find(&adrs).Error; err != nil {
+			return nil, types.GoalsGetOutput{}, err
+		}
+		titles := make(map[string]string, len(adrs))
+		for _, a := range adrs {
+			titles[a.ID] = a.Title
+		}
+		slices.Sort(adrIDs)
+		for _, id := range adrIDs {
+			output.ADRs = append(output.ADRs, types.LinkedADR{ID: id, Title: titles[id]})
+		}
+	}
+
+	if len(changeIDs) > 0 {
+		var entries []models.ChangelogEntry
+		if err := db.Where("id IN ?", changeIDs).Order("created_at ASC, id ASC").Find(&entries).Error; err != nil {
+			return nil, types.GoalsGetOutput{}, err
+		}
+		for _, e := range entries {
+			var files []string
+			if e.Files != "" {
+				files = strings.Split(e.Files, ", ")
+			}
+			output.Changes = append(output.Changes, types.LinkedChange{
+				ID:        int(e.ID),
+				Summary:   e.Summary,
+				Files:     files,
+				CreatedAt: e.CreatedAt.Format("2006-01-02 15:04:05"),
+			})
+			output.Files = append(output.Files, files...)
+		}
+	}
+
+	slices.Sort(output.Files)
+	output.Files = slices.Compact(output.Files)
+	return nil, output, nil
+}
+
+func toTypesLink(l models.GoalLink) types.GoalLink {
+	return types.GoalLink{
+		ID:        int(l.ID),
+		GoalID:    int(l.GoalID),
+		Kind:      l.Kind,
+		Target:    l.Target,
+		CreatedAt: l.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
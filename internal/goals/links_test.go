@@ -0,0 +1,153 @@
+package goals
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/state"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestGoalsHandler_LinksAdd(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	ctx := context.Background()
+
+	goalID := addTestGoal(t, handler, "Ship login", 10)
+	srv.GetDB().Create(&models.ADR{ID: "ADR-001", Title: "Use sessions"})
+	entry := models.ChangelogEntry{Summary: "Add login form"}
+	srv.GetDB().Create(&entry)
+
+	tests := []struct {
+		name       string
+		input      types.LinksAddInput
+		wantTarget string
+		wantAdded  bool
+		wantError  bool
+	}{
+		{name: "Missing target", input: types.LinksAddInput{GoalID: goalID, Kind: "file"}, wantError: true},
+		{name: "Unknown goal", input: types.LinksAddInput{GoalID: 999, Kind: "file", Target: "main.go"}, wantError: true},
+		{name: "Unknown kind", input: types.LinksAddInput{GoalID: goalID, Kind: "issue", Target: "7"}, wantError: true},
+		{name: "Unknown ADR", input: types.LinksAddInput{GoalID: goalID, Kind: "adr", Target: "ADR-009"}, wantError: true},
+		{name: "Changelog entry by name", input: types.LinksAddInput{GoalID: goalID, Kind: "changelog", Target: "latest"}, wantError: true},
+		{name: "File outside the repository", input: types.LinksAddInput{GoalID: goalID, Kind: "file", Target: "../etc/passwd"}, wantError: true},
+		{name: "Commit that is not a SHA", input: types.LinksAddInput{GoalID: goalID, Kind: "commit", Target: "HEAD~1"}, wantError: true},
+		{name: "ADR", input: types.LinksAddInput{GoalID: goalID, Kind: "adr", Target: "ADR-001"}, wantTarget: "ADR-001", wantAdded: true},
+		{name: "Changelog entry", input: types.LinksAddInput{GoalID: goalID, Kind: "changelog", Target: "1"}, wantTarget: "1", wantAdded: true},
+		{name: "File is cleaned", input: types.LinksAddInput{GoalID: goalID, Kind: "file", Target: "./internal/../main.go"}, wantTarget: "main.go", wantAdded: true},
+		{name: "Same file again", input: types.LinksAddInput{GoalID: goalID, Kind: "file", Target: "main.go"}, wantTarget: "main.go"},
+		{name: "Commit outside a git checkout", input: types.LinksAddInput{GoalID: goalID, Kind: "commit", Target: "ABC1234"}, wantTarget: "abc1234", wantAdded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := handler.LinksAdd(ctx, nil, tt.input)
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("LinksAdd() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("LinksAdd() unexpected error: %v", err)
+			}
+			if output.Added != tt.wantAdded || output.Link.Target != tt.wantTarget || output.Link.ID == 0 {
+				t.Errorf("LinksAdd() = %+v, want target %q added %v", output, tt.wantTarget, tt.wantAdded)
+			}
+		})
+	}
+}
+
+func TestGoalsHandler_GoalsGet(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	stateHandler := state.NewStateHandler(srv)
+	ctx := context.Background()
+
+	if _, _, err := handler.GoalsGet(ctx, nil, types.GoalsGetInput{ID: 1}); err == nil {
+		t.Errorf("GoalsGet() for unknown goal expected error, got nil")
+	}
+
+	_, added, err := handler.GoalsAdd(ctx, nil, types.GoalsAddInput{Title: "Ship login", Criteria: []string{"Form validates input"}})
+	if err != nil {
+		t.Fatalf("GoalsAdd() unexpected error: %v", err)
+	}
+	other := addTestGoal(t, handler, "Design sessions", 10)
+	if _, _, err := handler.GoalsDepend(ctx, nil, types.GoalsDependInput{ID: added.ID, DependsOn: other}); err != nil {
+		t.Fatalf("GoalsDepend() unexpected error: %v", err)
+	}
+	srv.GetDB().Create(&models.ADR{ID: "ADR-002", Title: "Use sessions"})
+
+	// Logging a change for a goal links the entry to it
+	if _, _, err := stateHandler.StateLogChange(ctx, nil, types.StateLogChangeInput{Summary: "Nothing", GoalID: 999}); err == nil {
+		t.Errorf("StateLogChange() for unknown goal expected error, got nil")
+	}
+	_, logged, err := stateHandler.StateLogChange(ctx, nil, types.StateLogChangeInput{Summary: "Add login form", Files: []string{"web/login.go", "main.go"}, GoalID: added.ID})
+	if err != nil {
+		t.Fatalf("StateLogChange() unexpected error: %v", err)
+	}
+	if _, _, err := stateHandler.StateLogChange(ctx, nil, types.StateLogChangeInput{Summary: "Unrelated"}); err != nil {
+		t.Fatalf("StateLogChange() unexpected error: %v", err)
+	}
+
+	for _, link := range []types.LinksAddInput{
+		{GoalID: added.ID, Kind: "adr", Target: "ADR-002"},
+		{GoalID: added.ID, Kind: "file", Target: "main.go"},
+		{GoalID: added.ID, Kind: "file", Target: "docs/login.md"},
+		{GoalID: added.ID, Kind: "commit", Target: "0123abcd"},
+	} {
+		if _, _, err := handler.LinksAdd(ctx, nil, link); err != nil {
+			t.Fatalf("LinksAdd(%+v) unexpected error: %v", link, err)
+		}
+	}
+	// The ADR was deleted after it was linked
+	srv.GetDB().Create(&models.ADR{ID: "ADR-001", Title: "Old"})
+	if _, _, err := handler.LinksAdd(ctx, nil, types.LinksAddInput{GoalID: added.ID, Kind: "adr", Target: "ADR-001"}); err != nil {
+		t.Fatalf("LinksAdd() unexpected error: %v", err)
+	}
+	srv.GetDB().Delete(&models.ADR{ID: "ADR-001"})
+
+	_, output, err := handler.GoalsGet(ctx, nil, types.GoalsGetInput{ID: added.ID})
+	if err != nil {
+		t.Fatalf("GoalsGet() unexpected error: %v", err)
+	}
+
+	if output.Goal.Title != "Ship login" || len(output.Goal.Criteria) != 1 {
+		t.Errorf("GoalsGet() goal = %+v, want it with its criterion", output.Goal)
+	}
+	if len(output.DependsOn) != 1 || output.DependsOn[0].ID != other {
+		t.Errorf("GoalsGet() depends on = %+v, want goal %d", output.DependsOn, other)
+	}
+	wantADRs := []types.LinkedADR{{ID: "ADR-001"}, {ID: "ADR-002", Title: "Use sessions"}}
+	if !reflect.DeepEqual(output.ADRs, wantADRs) {
+		t.Errorf("GoalsGet() ADRs = %+v, want %+v", output.ADRs, wantADRs)
+	}
+	if len(output.Changes) != 1 || output.Changes[0].ID != logged.ID || output.Changes[0].Summary != "Add login form" {
+		t.Errorf("GoalsGet() changes = %+v, want entry %d", output.Changes, logged.ID)
+	}
+	if want := []string{"docs/login.md", "main.go", "web/login.go"}; !reflect.DeepEqual(output.Files, want) {
+		t.Errorf("GoalsGet() files = %v, want %v", output.Files, want)
+	}
+	if want := []string{"0123abcd"}; !reflect.DeepEqual(output.Commits, want) {
+		t.Errorf("GoalsGet() commits = %v, want %v", output.Commits, want)
+	}
+}
@@ -0,0 +1,282 @@
+package goals
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
+)
+
+// defaultStaleHours is how long a session can stay open before the time
+// report assumes somebody forgot to stop it.
+const defaultStaleHours = 8
+
+// untaggedKey groups time on goals without tags in the time report.
+const untaggedKey = "(untagged)"
+
+// GoalsStart opens a work session on a goal.
+//
+// Only one session is open at a time: starting a goal while another goal has
+// an open session closes (pauses) that session first. Starting the goal that
+// already has the open session returns that session unchanged.
+func (h *GoalsHandler) GoalsStart(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsStartInput) (*mcp.CallToolResult, types.GoalsStartOutput, error) {
+	if input.GoalID == 0 {
+		return nil, types.GoalsStartOutput{}, fmt.Errorf("goal_id required")
+	}
+
+	db := h.server.GetDB()
+	var goal models.Goal
+	if err := db.First(&goal, input.GoalID).Error; err != nil {
+		return nil, types.GoalsStartOutput{}, fmt.Errorf("goal %d not found", input.GoalID)
+	}
+
+	var output types.GoalsStartOutput
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var open models.GoalSession
+		if err := tx.Where("ended_at IS NULL").Limit(1).Find(&open).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if open.ID != 0 {
+			if open.GoalID == goal.ID {
+				output.Session = toTypesSession(open, goal.Title, now)
+				return nil
+			}
+			if err := tx.Model(&open).Update("ended_at", now).Error; err != nil {
+				return err
+			}
+			open.EndedAt = &now
+			var paused models.Goal
+			if err := tx.Select("title").First(&paused, open.GoalID).Error; err != nil && err != gorm.ErrRecordNotFound {
+				return err
+			}
+			session := toTypesSession(open, paused.Title, now)
+			output.Paused = &session
+		}
+
+		session := models.GoalSession{GoalID: goal.ID, StartedAt: now}
+		if input.Note != nil {
+			session.Note = *input.Note
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		output.Session = toTypesSession(session, goal.Title, now)
+		return nil
+	})
+	if err != nil {
+		return nil, types.GoalsStartOutput{}, err
+	}
+
+	return nil, output, nil
+}
+
+// GoalsStop closes the open work session, optionally checking that it belongs
+// to the given goal.
+func (h *GoalsHandler) GoalsStop(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsStopInput) (*mcp.CallToolResult, types.GoalsStopOutput, error) {
+	db := h.server.GetDB()
+
+	query := db.Where("ended_at IS NULL")
+	if input.GoalID != nil {
+		query = query.Where("goal_id = ?", *input.GoalID)
+	}
+	var open models.GoalSession
+	if err := query.Limit(1).Find(&open).Error; err != nil {
+		return nil, types.GoalsStopOutput{}, err
+	}
+	if open.ID == 0 {
+		if input.GoalID != nil {
+			return nil, types.GoalsStopOutput{}, fmt.Errorf("goal %d has no open session", *input.GoalID)
+		}
+		return nil, types.GoalsStopOutput{}, fmt.Errorf("no open session")
+	}
+
+	now := time.Now()
+	if err := db.Model(&open).Update("ended_at", now).Error; err != nil {
+		return nil, types.GoalsStopOutput{}, err
+	}
+	open.EndedAt = &now
+
+	var goal models.Goal
+	if err := db.Select("title").First(&goal, open.GoalID).Error; err != nil && err != gorm.ErrRecordNotFound {
+		return nil, types.GoalsStopOutput{}, err
+	}
+
+	return nil, types.GoalsStopOutput{Session: toTypesSession(open, goal.Title, now)}, nil
+}
+
+// GoalsTimeReport summarizes tracked time per goal, per tag and per ISO week.
+//
+// Open sessions count up to now. Sessions are attributed to the week they
+// started in. Sessions open for longer than StaleHours are listed separately
+// and their goals flagged, since their time is probably overstated.
+func (h *GoalsHandler) GoalsTimeReport(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsTimeReportInput) (*mcp.CallToolResult, types.GoalsTimeReportOutput, error) {
+	staleAfter := time.Duration(defaultStaleHours) * time.Hour
+	if input.StaleHours > 0 {
+		staleAfter = time.Duration(input.StaleHours) * time.Hour
+	}
+
+	db := h.server.GetDB()
+	query := db.Order("started_at ASC, id ASC")
+	if input.Since != "" {
+		since, err := parseDay(input.Since)
+		if err != nil {
+			return nil, types.GoalsTimeReportOutput{}, err
+		}
+		query = query.Where("started_at >= ?", since)
+	}
+	if input.Until != "" {
+		until, err := parseDay(input.Until)
+		if err != nil {
+			return nil, types.GoalsTimeReportOutput{}, err
+		}
+		query = query.Where("started_at < ?", until)
+	}
+
+	var sessions []models.GoalSession
+	if err := query.Find(&sessions).Error; err != nil {
+		return nil, types.GoalsTimeReportOutput{}, err
+	}
+
+	goalIDs := make([]uint, 0, len(sessions))
+	for _, s := range sessions {
+		goalIDs = append(goalIDs, s.GoalID)
+	}
+	var goals []models.Goal
+	if len(goalIDs) > 0 {
+		if err := db.Where("id IN ?", goalIDs).Find(&goals).Error; err != nil {
+			return nil, types.GoalsTimeReportOutput{}, err
+		}
+	}
+	goalsByID := make(map[uint]models.Goal, len(goals))
+	for _, g := range goals {
+		goalsByID[g.ID] = g
+	}
+
+	tag := normalizeTag(input.Tag)
+	now := time.Now()
+	byGoal := make(map[uint]*types.GoalTimeEntry)
+	byTag := make(map[string]int64)
+	byWeek := make(map[string]int64)
+	output := types.GoalsTimeReportOutput{LeftOpen: []types.GoalSession{}}
+
+	for _, s := range sessions {
+		goal := goalsByID[s.GoalID]
+		tags := splitTags(goal.Tags)
+		if tag != "" && !slices.Contains(tags, tag) {
+			continue
+		}
+
+		seconds := sessionSeconds(s, now)
+		output.TotalSeconds += seconds
+
+		entry, ok := byGoal[s.GoalID]
+		if !ok {
+			entry = &types.GoalTimeEntry{GoalID: int(s.GoalID), Title: goal.Title}
+			byGoal[s.GoalID] = entry
+		}
+		entry.Seconds += seconds
+		entry.Sessions++
+		if s.EndedAt == nil && now.Sub(s.StartedAt) > staleAfter {
+			entry.LeftOpen = true
+			output.LeftOpen = append(output.LeftOpen, toTypesSession(s, goal.Title, now))
+		}
+
+		if len(tags) == 0 {
+			tags = []string{untaggedKey}
+		}
+		for _, t := range tags {
+			byTag[t] += seconds
+		}
+
+		year, week := s.StartedAt.ISOWeek()
+		byWeek[fmt.Sprintf("%04d-W%02d", year, week)] += seconds
+	}
+
+	output.Total = formatDuration(output.TotalSeconds)
+	output.ByGoal = make([]types.GoalTimeEntry, 0, len(byGoal))
+	for _, entry := range byGoal {
+		entry.Duration = formatDuration(entry.Seconds)
+		output.ByGoal = append(output.ByGoal, *entry)
+	}
+	sort.Slice(output.ByGoal, func(i, j int) bool {
+		a, b := output.ByGoal[i], output.ByGoal[j]
+		if a.Seconds != b.Seconds {
+			return a.Seconds > b.Seconds
+		}
+		return a.GoalID < b.GoalID
+	})
+
+	output.ByTag = timeEntries(byTag)
+	sort.SliceStable(output.ByTag, func(i, j int) bool {
+		return output.ByTag[i].Seconds > output.ByTag[j].Seconds
+	})
+	output.ByWeek = timeEntries(byWeek)
+
+	return nil, output, nil
+}
+
+// parseDay reads a date in local time, which session timestamps are stored
+// in.
+func parseDay(value string) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", value)
+	}
+	return date, nil
+}
+
+// timeEntries converts totals into entries sorted by key.
+func timeEntries(totals map[string]int64) []types.TimeEntry {
+	entries := make([]types.TimeEntry, 0, len(totals))
+	for key, seconds := range totals {
+		entries = append(entries, types.TimeEntry{Key: key, Seconds: seconds, Duration: formatDuration(seconds)})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+// sessionSeconds returns the length of a session, counting open sessions up
+// to now.
+func sessionSeconds(s models.GoalSession, now time.Time) int64 {
+	end := now
+	if s.EndedAt != nil {
+		end = *s.EndedAt
+	}
+	if end.Before(s.StartedAt) {
+		return 0
+	}
+	return int64(end.Sub(s.StartedAt) / time.Second)
+}
+
+// formatDuration renders seconds as hours and minutes, e.g. "3h05m".
+func formatDuration(seconds int64) string {
+	minutes := seconds / 60
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
+
+func toTypesSession(s models.GoalSession, title string, now time.Time) types.GoalSession {
+	endedAt := ""
+	if s.EndedAt != nil {
+		endedAt = s.EndedAt.Format("2006-01-02 15:04:05")
+	}
+	return types.GoalSession{
+		ID:        int(s.ID),
+		GoalID:    int(s.GoalID),
+		Title:     title,
+		StartedAt: s.StartedAt.Format("2006-01-02 15:04:05"),
+		EndedAt:   endedAt,
+		Seconds:   sessionSeconds(s, now),
+		Note:      s.Note,
+	}
+}
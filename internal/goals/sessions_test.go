@@ -0,0 +1,157 @@
+package goals
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestGoalsHandler_GoalsStartStop(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	ctx := context.Background()
+
+	a := addTestGoal(t, handler, "A", 1)
+	b := addTestGoal(t, handler, "B", 1)
+
+	if _, _, err := handler.GoalsStop(ctx, nil, types.GoalsStopInput{}); err == nil {
+		t.Errorf("GoalsStop() without an open session expected error, got nil")
+	}
+	if _, _, err := handler.GoalsStart(ctx, nil, types.GoalsStartInput{GoalID: 999}); err == nil {
+		t.Errorf("GoalsStart() on unknown goal expected error, got nil")
+	}
+
+	_, first, err := handler.GoalsStart(ctx, nil, types.GoalsStartInput{GoalID: a})
+	if err != nil {
+		t.Fatalf("GoalsStart() unexpected error: %v", err)
+	}
+	if first.Paused != nil || first.Session.GoalID != a || first.Session.EndedAt != "" {
+		t.Errorf("GoalsStart() = %+v, want an open session on goal %d", first, a)
+	}
+
+	// Starting the same goal again keeps the open session
+	_, again, err := handler.GoalsStart(ctx, nil, types.GoalsStartInput{GoalID: a})
+	if err != nil {
+		t.Fatalf("GoalsStart() unexpected error: %v", err)
+	}
+	if again.Session.ID != first.Session.ID || again.Paused != nil {
+		t.Errorf("GoalsStart() again = %+v, want session %d unchanged", again, first.Session.ID)
+	}
+
+	// Starting another goal pauses the open session
+	_, second, err := handler.GoalsStart(ctx, nil, types.GoalsStartInput{GoalID: b})
+	if err != nil {
+		t.Fatalf("GoalsStart() unexpected error: %v", err)
+	}
+	if second.Paused == nil || second.Paused.ID != first.Session.ID || second.Paused.EndedAt == "" {
+		t.Errorf("GoalsStart() = %+v, want session %d paused", second, first.Session.ID)
+	}
+
+	if _, _, err := handler.GoalsStop(ctx, nil, types.GoalsStopInput{GoalID: &a}); err == nil {
+		t.Errorf("GoalsStop() on goal without open session expected error, got nil")
+	}
+	_, stopped, err := handler.GoalsStop(ctx, nil, types.GoalsStopInput{})
+	if err != nil {
+		t.Fatalf("GoalsStop() unexpected error: %v", err)
+	}
+	if stopped.Session.GoalID != b || stopped.Session.EndedAt == "" || stopped.Session.Title != "B" {
+		t.Errorf("GoalsStop() = %+v, want closed session on goal %d", stopped, b)
+	}
+}
+
+func TestGoalsHandler_GoalsTimeReport(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	ctx := context.Background()
+
+	_, api, err := handler.GoalsAdd(ctx, nil, types.GoalsAddInput{Title: "API", Tags: []string{"backend", "api"}})
+	if err != nil {
+		t.Fatalf("GoalsAdd() unexpected error: %v", err)
+	}
+	docs := addTestGoal(t, handler, "Docs", 1)
+
+	// 2025-06-30 is the Monday of ISO week 27
+	day := time.Date(2025, 6, 30, 9, 0, 0, 0, time.Local)
+	end := func(t time.Time, d time.Duration) *time.Time { e := t.Add(d); return &e }
+	sessions := []models.GoalSession{
+		{GoalID: uint(api.ID), StartedAt: day, EndedAt: end(day, 2*time.Hour)},
+		{GoalID: uint(docs), StartedAt: day.AddDate(0, 0, 3), EndedAt: end(day.AddDate(0, 0, 3), 30*time.Minute)},
+		{GoalID: uint(api.ID), StartedAt: day.AddDate(0, 0, 7), EndedAt: end(day.AddDate(0, 0, 7), 45*time.Minute)},
+		{GoalID: uint(docs), StartedAt: time.Now().Add(-10 * time.Hour)},
+	}
+	for _, s := range sessions {
+		if err := srv.GetDB().Create(&s).Error; err != nil {
+			t.Fatalf("Failed to create session: %v", err)
+		}
+	}
+
+	_, report, err := handler.GoalsTimeReport(ctx, nil, types.GoalsTimeReportInput{Until: "2025-12-31"})
+	if err != nil {
+		t.Fatalf("GoalsTimeReport() unexpected error: %v", err)
+	}
+	if report.Total != "3h15m" || len(report.LeftOpen) != 0 {
+		t.Errorf("GoalsTimeReport() total = %q, left open = %v; want 3h15m and none", report.Total, report.LeftOpen)
+	}
+	if len(report.ByGoal) != 2 || report.ByGoal[0].GoalID != api.ID || report.ByGoal[0].Duration != "2h45m" || report.ByGoal[0].Sessions != 2 {
+		t.Errorf("GoalsTimeReport() by goal = %+v, want API first with 2h45m over 2 sessions", report.ByGoal)
+	}
+	wantTags := []types.TimeEntry{
+		{Key: "api", Seconds: 9900, Duration: "2h45m"},
+		{Key: "backend", Seconds: 9900, Duration: "2h45m"},
+		{Key: untaggedKey, Seconds: 1800, Duration: "30m"},
+	}
+	if len(report.ByTag) != len(wantTags) {
+		t.Fatalf("GoalsTimeReport() by tag = %+v, want %+v", report.ByTag, wantTags)
+	}
+	for i := range wantTags {
+		if report.ByTag[i] != wantTags[i] {
+			t.Errorf("GoalsTimeReport() by tag[%d] = %+v, want %+v", i, report.ByTag[i], wantTags[i])
+		}
+	}
+	if len(report.ByWeek) != 2 || report.ByWeek[0].Key != "2025-W27" || report.ByWeek[0].Duration != "2h30m" || report.ByWeek[1].Key != "2025-W28" {
+		t.Errorf("GoalsTimeReport() by week = %+v, want 2025-W27 (2h30m) then 2025-W28", report.ByWeek)
+	}
+
+	// The session started ten hours ago was never stopped
+	_, report, err = handler.GoalsTimeReport(ctx, nil, types.GoalsTimeReportInput{Since: time.Now().AddDate(0, 0, -2).Format("2006-01-02"), StaleHours: 4})
+	if err != nil {
+		t.Fatalf("GoalsTimeReport() unexpected error: %v", err)
+	}
+	if len(report.LeftOpen) != 1 || len(report.ByGoal) != 1 || !report.ByGoal[0].LeftOpen {
+		t.Errorf("GoalsTimeReport() = %+v, want the docs session flagged as left open", report)
+	}
+
+	// Dates are days in local time, like the session timestamps
+	if day, err := parseDay("2025-07-10"); err != nil || !day.Equal(time.Date(2025, 7, 10, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("parseDay() = %v, %v, want local midnight", day, err)
+	}
+	if _, _, err := handler.GoalsTimeReport(ctx, nil, types.GoalsTimeReportInput{Since: "2025-07-10T00:00:00Z"}); err == nil {
+		t.Errorf("GoalsTimeReport() with a timestamp for since succeeded, want an error")
+	}
+
+	_, report, err = handler.GoalsTimeReport(ctx, nil, types.GoalsTimeReportInput{Tag: "API", Until: "2025-12-31"})
+	if err != nil {
+		t.Fatalf("GoalsTimeReport() unexpected error: %v", err)
+	}
+	if report.Total != "2h45m" {
+		t.Errorf("GoalsTimeReport(tag) total = %q, want 2h45m", report.Total)
+	}
+}
@@ -0,0 +1,280 @@
+package goals
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/markdown"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+// sparkRamp are the characters of an ASCII sparkline, lowest first.
+const sparkRamp = "_.-=+*#@"
+
+// goalTimeline is when a goal was created, when work on it started and when
+// it was closed (nil while open).
+type goalTimeline struct {
+	goal      models.Goal
+	created   time.Time
+	started   time.Time
+	completed *time.Time
+	closed    *time.Time
+}
+
+// openAt reports whether the goal existed and was still open just before t.
+func (g goalTimeline) openAt(t time.Time) bool {
+	return g.created.Before(t) && (g.closed == nil || !g.closed.Before(t))
+}
+
+// GoalsStats reports weekly throughput, cycle and lead times, open versus
+// closed trends and, for a milestone, a daily burndown.
+//
+// Cycle time runs from the first move into an active status to done; goals
+// that never went through an active status count from their creation. The
+// result is returned as structured data and as a markdown report with
+// sparkline charts.
+func (h *GoalsHandler) GoalsStats(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsStatsInput) (*mcp.CallToolResult, types.GoalsStatsOutput, error) {
+	weeks := input.Weeks
+	if weeks <= 0 {
+		weeks = 12
+	}
+
+	timelines, err := h.loadTimelines()
+	if err != nil {
+		return nil, types.GoalsStatsOutput{}, err
+	}
+
+	now := time.Now()
+	first := weekStart(now).AddDate(0, 0, -7*(weeks-1))
+	output := types.GoalsStatsOutput{Weeks: make([]types.GoalsWeekStats, 0, weeks)}
+
+	for i := 0; i < weeks; i++ {
+		start := first.AddDate(0, 0, 7*i)
+		end := start.AddDate(0, 0, 7)
+		year, week := start.ISOWeek()
+		stats := types.GoalsWeekStats{Week: fmt.Sprintf("%04d-W%02d", year, week)}
+		for _, g := range timelines {
+			if within(g.created, start, end) {
+				stats.Created++
+			}
+			if g.completed != nil && within(*g.completed, start, end) {
+				stats.Completed++
+			}
+			if g.openAt(end) {
+				stats.Open++
+			} else if g.created.Before(end) {
+				stats.Closed++
+			}
+		}
+		output.Weeks = append(output.Weeks, stats)
+	}
+
+	var cycle, lead time.Duration
+	for _, g := range timelines {
+		if g.completed == nil || g.completed.Before(first) {
+			continue
+		}
+		output.Completed++
+		cycle += g.completed.Sub(g.started)
+		lead += g.completed.Sub(g.created)
+	}
+	if output.Completed > 0 {
+		output.AvgCycleTimeDays = roundDays(cycle / time.Duration(output.Completed))
+		output.AvgLeadTimeDays = roundDays(lead / time.Duration(output.Completed))
+	}
+
+	if input.Milestone != "" {
+		burndown, err := milestoneBurndown(timelines, input.Milestone, now)
+		if err != nil {
+			return nil, types.GoalsStatsOutput{}, err
+		}
+		output.Burndown = burndown
+	}
+
+	output.Markdown = statsMarkdown(output)
+	return nil, output, nil
+}
+
+// loadTimelines reconstructs each goal's timeline from its timestamps and
+// status history.
+func (h *GoalsHandler) loadTimelines() ([]goalTimeline, error) {
+	db := h.server.GetDB()
+	w, err := loadWorkflow(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var goals []models.Goal
+	if err := db.Order("id ASC").Find(&goals).Error; err != nil {
+		return nil, err
+	}
+	var changes []models.GoalStatusChange
+	if err := db.Order("changed_at ASC, id ASC").Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	history := make(map[uint][]models.GoalStatusChange)
+	for _, c := range changes {
+		history[c.GoalID] = append(history[c.GoalID], c)
+	}
+
+	active := w.statusesIn(categoryActive)
+	cancelled := w.statusesIn(categoryCancelled)
+	timelines := make([]goalTimeline, 0, len(goals))
+	for _, g := range goals {
+		t := goalTimeline{goal: g, created: g.CreatedAt, started: g.CreatedAt, completed: g.CompletedAt, closed: g.CompletedAt}
+		for _, c := range history[g.ID] {
+			if slices.Contains(active, c.ToStatus) {
+				t.started = c.ChangedAt
+				break
+			}
+		}
+		if slices.Contains(cancelled, g.Status) {
+			closed := g.UpdatedAt
+			for _, c := range history[g.ID] {
+				if c.ToStatus == g.Status {
+					closed = c.ChangedAt
+				}
+			}
+			t.closed = &closed
+		}
+		timelines = append(timelines, t)
+	}
+	return timelines, nil
+}
+
+// milestoneBurndown counts the milestone's open goals at the end of every day
+// from its first goal's creation until today.
+func milestoneBurndown(timelines []goalTimeline, milestone string, now time.Time) (*types.GoalsBurndown, error) {
+	var goals []goalTimeline
+	for _, g := range timelines {
+		if g.goal.Milestone == milestone {
+			goals = append(goals, g)
+		}
+	}
+	if len(goals) == 0 {
+		return nil, fmt.Errorf("milestone %q has no goals", milestone)
+	}
+
+	first := goals[0].created
+	for _, g := range goals {
+		if g.created.Before(first) {
+			first = g.created
+		}
+	}
+
+	burndown := &types.GoalsBurndown{Milestone: milestone, Total: len(goals), Days: []types.GoalsBurndownDay{}}
+	for day := dayStart(first); !day.After(now); day = day.AddDate(0, 0, 1) {
+		end := day.AddDate(0, 0, 1)
+		remaining := 0
+		for _, g := range goals {
+			if g.openAt(end) {
+				remaining++
+			}
+		}
+		burndown.Days = append(burndown.Days, types.GoalsBurndownDay{Date: day.Format("2006-01-02"), Remaining: remaining})
+	}
+	for _, g := range goals {
+		if g.closed == nil {
+			burndown.Remaining++
+		}
+	}
+	return burndown, nil
+}
+
+func statsMarkdown(stats types.GoalsStatsOutput) string {
+	md := markdown.NewBuilder()
+	md.AddHeader(1, "Goal stats")
+	if len(stats.Weeks) > 0 {
+		md.AddParagraph(fmt.Sprintf("Weeks %s to %s.", stats.Weeks[0].Week, stats.Weeks[len(stats.Weeks)-1].Week))
+	}
+
+	created := make([]int, len(stats.Weeks))
+	completed := make([]int, len(stats.Weeks))
+	open := make([]int, len(stats.Weeks))
+	closed := make([]int, len(stats.Weeks))
+	rows := make([][]string, len(stats.Weeks))
+	for i, w := range stats.Weeks {
+		created[i], completed[i], open[i], closed[i] = w.Created, w.Completed, w.Open, w.Closed
+		rows[i] = []string{w.Week, fmt.Sprint(w.Created), fmt.Sprint(w.Completed), fmt.Sprint(w.Open), fmt.Sprint(w.Closed)}
+	}
+
+	md.AddHeader(2, "Throughput")
+	md.AddCodeBlock("", strings.Join([]string{
+		sparklineRow("created", created),
+		sparklineRow("completed", completed),
+	}, "\n"))
+	md.AddParagraph(fmt.Sprintf("%d goals completed. Average cycle time %.1f days, average lead time %.1f days.",
+		stats.Completed, stats.AvgCycleTimeDays, stats.AvgLeadTimeDays))
+
+	md.AddHeader(2, "Open versus closed")
+	md.AddCodeBlock("", strings.Join([]string{
+		sparklineRow("open", open),
+		sparklineRow("closed", closed),
+	}, "\n"))
+	md.AddTable([]string{"Week", "Created", "Completed", "Open", "Closed"}, rows)
+
+	if b := stats.Burndown; b != nil {
+		remaining := make([]int, len(b.Days))
+		for i, d := range b.Days {
+			remaining[i] = d.Remaining
+		}
+		md.AddHeader(2, "Burndown: "+b.Milestone)
+		md.AddParagraph(fmt.Sprintf("%d of %d goals remaining.", b.Remaining, b.Total))
+		md.AddCodeBlock("", sparklineRow("remaining", remaining))
+	}
+
+	return md.String()
+}
+
+// sparklineRow labels a sparkline and shows the range it was scaled to.
+func sparklineRow(label string, values []int) string {
+	high := 0
+	for _, v := range values {
+		high = max(high, v)
+	}
+	return fmt.Sprintf("%-10s %s  (max %d)", label, sparkline(values), high)
+}
+
+// sparkline draws values as a row of ASCII characters scaled between zero
+// and the largest value.
+func sparkline(values []int) string {
+	high := 0
+	for _, v := range values {
+		high = max(high, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if high > 0 && v > 0 {
+			level = (v*(len(sparkRamp)-1) + high - 1) / high
+		}
+		b.WriteByte(sparkRamp[level])
+	}
+	return b.String()
+}
+
+// weekStart returns midnight on the Monday of t's ISO week.
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return dayStart(t).AddDate(0, 0, -offset)
+}
+
+func dayStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func within(t, start, end time.Time) bool {
+	return !t.Before(start) && t.Before(end)
+}
+
+func roundDays(d time.Duration) float64 {
+	return math.Round(d.Hours()/24*10) / 10
+}
@@ -0,0 +1,118 @@
+package goals
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		want   string
+	}{
+		{name: "Empty", values: nil, want: ""},
+		{name: "All zero", values: []int{0, 0, 0}, want: "___"},
+		{name: "Scaled to the maximum", values: []int{0, 1, 2, 4}, want: "_-+@"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sparkline(tt.values); got != tt.want {
+				t.Errorf("sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoalsHandler_GoalsStats(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	ctx := context.Background()
+	db := srv.GetDB()
+
+	str := func(s string) *string { return &s }
+	_, shipped, err := handler.GoalsAdd(ctx, nil, types.GoalsAddInput{Title: "Shipped", Milestone: str("v1")})
+	if err != nil {
+		t.Fatalf("GoalsAdd() unexpected error: %v", err)
+	}
+	_, open, err := handler.GoalsAdd(ctx, nil, types.GoalsAddInput{Title: "Still open", Milestone: str("v1")})
+	if err != nil {
+		t.Fatalf("GoalsAdd() unexpected error: %v", err)
+	}
+
+	// Completing a goal records when it happened, reopening clears it again
+	for _, status := range []string{"done", "active", "done"} {
+		if _, _, err := handler.GoalsUpdate(ctx, nil, types.GoalsUpdateInput{ID: shipped.ID, Status: str(status)}); err != nil {
+			t.Fatalf("GoalsUpdate(%s) unexpected error: %v", status, err)
+		}
+		var goal models.Goal
+		db.First(&goal, shipped.ID)
+		if (goal.CompletedAt != nil) != (status == "done") {
+			t.Errorf("after moving to %s, completed at = %v", status, goal.CompletedAt)
+		}
+	}
+
+	// Move the goals back in time: the first was created two weeks ago and
+	// finished a week later, the second was created last week
+	thisWeek := weekStart(time.Now())
+	created := thisWeek.AddDate(0, 0, -14).Add(time.Hour)
+	completed := thisWeek.AddDate(0, 0, -7).Add(time.Hour)
+	db.Model(&models.Goal{}).Where("id = ?", shipped.ID).Updates(map[string]interface{}{"created_at": created, "completed_at": completed})
+	db.Model(&models.GoalStatusChange{}).Where("goal_id = ?", shipped.ID).Update("changed_at", created)
+	db.Model(&models.Goal{}).Where("id = ?", open.ID).Update("created_at", thisWeek.AddDate(0, 0, -7).Add(2*time.Hour))
+
+	if _, _, err := handler.GoalsStats(ctx, nil, types.GoalsStatsInput{Milestone: "v9"}); err == nil {
+		t.Errorf("GoalsStats() with unknown milestone expected error, got nil")
+	}
+
+	_, stats, err := handler.GoalsStats(ctx, nil, types.GoalsStatsInput{Weeks: 3, Milestone: "v1"})
+	if err != nil {
+		t.Fatalf("GoalsStats() unexpected error: %v", err)
+	}
+
+	want := []types.GoalsWeekStats{
+		{Created: 1, Completed: 0, Open: 1, Closed: 0},
+		{Created: 1, Completed: 1, Open: 1, Closed: 1},
+		{Created: 0, Completed: 0, Open: 1, Closed: 1},
+	}
+	if len(stats.Weeks) != len(want) {
+		t.Fatalf("GoalsStats() weeks = %+v, want %d weeks", stats.Weeks, len(want))
+	}
+	for i, w := range want {
+		w.Week = stats.Weeks[i].Week
+		if stats.Weeks[i] != w {
+			t.Errorf("GoalsStats() week %d = %+v, want %+v", i, stats.Weeks[i], w)
+		}
+	}
+	if stats.Completed != 1 || stats.AvgCycleTimeDays != 7 || stats.AvgLeadTimeDays != 7 {
+		t.Errorf("GoalsStats() completed = %d, cycle = %v, lead = %v; want 1, 7, 7", stats.Completed, stats.AvgCycleTimeDays, stats.AvgLeadTimeDays)
+	}
+
+	b := stats.Burndown
+	if b == nil || b.Total != 2 || b.Remaining != 1 {
+		t.Fatalf("GoalsStats() burndown = %+v, want 1 of 2 remaining", b)
+	}
+	if b.Days[0].Date != created.Format("2006-01-02") || b.Days[0].Remaining != 1 || b.Days[len(b.Days)-1].Remaining != 1 {
+		t.Errorf("GoalsStats() burndown days = %+v, want 1 remaining from %s", b.Days, created.Format("2006-01-02"))
+	}
+
+	for _, section := range []string{"## Throughput", "completed  ", "## Open versus closed", "## Burndown: v1", "1 of 2 goals remaining."} {
+		if !strings.Contains(stats.Markdown, section) {
+			t.Errorf("GoalsStats() markdown missing %q:\n%s", section, stats.Markdown)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package goals
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
+)
+
+// todoSource is the Goal.Source of goals created by goals_import_todos.
+const todoSource = "todo"
+
+// todoRemoved is the reason recorded when a goal is completed because its
+// comment disappeared, so that it is reopened if the comment comes back.
+const todoRemoved = "comment_removed"
+
+// maxTodoFileSize skips generated or vendored blobs that are unlikely to hold
+// hand-written comments.
+const maxTodoFileSize = 1 << 20
+
+// todoPattern matches a marker at the start of a comment, optionally followed
+// by an author in parentheses and a colon.
+var todoPattern = regexp.MustCompile(`(?://|#|/\*|<!--|--|;)\s*\b(TODO|FIXME)\b(?:\(([^)]*)\))?:?\s*(.*)$`)
+
+// todoComment is a marker comment found in the source tree.
+type todoComment struct {
+	marker      string
+	author      string
+	text        string
+	file        string
+	line        int
+	fingerprint string
+}
+
+// GoalsImportTodos scans the repository for TODO and FIXME comments and keeps
+// a goal for each of them.
+//
+// Files ignored by git are skipped. Each comment is identified by a
+// fingerprint of its file, marker and text, so a goal survives the comment
+// moving to another line. Goals whose comment has disappeared are moved to the
+// workflow's done status, and back to its initial status if the comment
+// reappears; goals closed by hand stay closed.
+func (h *GoalsHandler) GoalsImportTodos(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsImportTodosInput) (*mcp.CallToolResult, types.GoalsImportTodosOutput, error) {
+	root := h.server.GetRepoRoot()
+	dir := ""
+	if input.Path != nil && *input.Path != "" {
+		dir = path.Clean(filepath.ToSlash(*input.Path))
+		if dir == "." {
+			dir = ""
+		}
+		if strings.HasPrefix(dir, "../") || dir == ".." || path.IsAbs(dir) {
+			return nil, types.GoalsImportTodosOutput{}, fmt.Errorf("path must be inside the repository")
+		}
+	}
+
+	files, err := listRepoFiles(ctx, root, dir)
+	if err != nil {
+		return nil, types.GoalsImportTodosOutput{}, fmt.Errorf("failed to list files: %w", err)
+	}
+	sort.Strings(files)
+
+	var comments []todoComment
+	for _, file := range files {
+		found, err := scanTodos(root, file)
+		if err != nil {
+			return nil, types.GoalsImportTodosOutput{}, err
+		}
+		comments = append(comments, found...)
+	}
+
+	db := h.server.GetDB()
+	w, err := loadWorkflow(db)
+	if err != nil {
+		return nil, types.GoalsImportTodosOutput{}, err
+	}
+
+	var existing []models.Goal
+	if err := db.Where("source = ?", todoSource).Find(&existing).Error; err != nil {
+		return nil, types.GoalsImportTodosOutput{}, err
+	}
+	byFingerprint := make(map[string]models.Goal, len(existing))
+	for _, g := range existing {
+		byFingerprint[g.ExternalID] = g
+	}
+
+	var output types.GoalsImportTodosOutput
+	closed := w.statusesIn(categoryDone, categoryCancelled)
+	done := w.statusesIn(categoryDone)[0]
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		seen := make(map[string]bool, len(comments))
+		var stuckOpen []string
+		for _, c := range comments {
+			seen[c.fingerprint] = true
+			change := types.ImportedTodo{Marker: c.marker, Author: c.author, Text: c.text, File: c.file, Line: c.line}
+
+			goal, ok := byFingerprint[c.fingerprint]
+			if !ok {
+				goal = models.Goal{
+					Title:      c.text,
+					Priority:   todoPriority(c.marker),
+					Status:     w.initial(),
+					Notes:      fmt.Sprintf("%s comment in %s", c.marker, c.ref()),
+					Tags:       joinTags([]string{c.marker}),
+					Owner:      c.author,
+					Source:     todoSource,
+					ExternalID: c.fingerprint,
+					SourceRef:  c.ref(),
+				}
+				if err := tx.Create(&goal).Error; err != nil {
+					return err
+				}
+				if err := tx.Create(&models.GoalStatusChange{GoalID: goal.ID, ToStatus: goal.Status}).Error; err != nil {
+					return err
+				}
+				change.GoalID, change.Action = int(goal.ID), "created"
+				output.Created++
+				output.Changes = append(output.Changes, change)
+				continue
+			}
+
+			// A goal completed here because its comment was gone is reopened
+			// when the comment comes back; one closed by hand stays closed
+			reopened := false
+			if slices.Contains(closed, goal.Status) {
+				var last models.GoalStatusChange
+				if err := tx.Where("goal_id = ?", goal.ID).Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+					return err
+				}
+				switch {
+				case last.Reason != todoRemoved || last.ToStatus != goal.Status:
+				case !w.canTransition(goal.Status, w.initial()):
+					stuckOpen = append(stuckOpen, fmt.Sprintf("#%d (%s)", goal.ID, goal.Status))
+				default:
+					if err := changeStatus(tx, w, goal.ID, goal.Status, w.initial(), ""); err != nil {
+						return err
+					}
+					reopened = true
+				}
+			}
+
+			if goal.SourceRef == c.ref() && goal.Owner == c.author {
+				if reopened {
+					change.GoalID, change.Action = int(goal.ID), "reopened"
+					output.Reopened++
+					output.Changes = append(output.Changes, change)
+				} else {
+					output.Unchanged++
+				}
+				continue
+			}
+			updates := map[string]interface{}{
+				"source_ref": c.ref(),
+				"owner":      c.author,
+				"notes":      fmt.Sprintf("%s comment in %s", c.marker, c.ref()),
+			}
+			if err := tx.Model(&goal).Updates(updates).Error; err != nil {
+				return err
+			}
+			change.GoalID, change.Action = int(goal.ID), "updated"
+			if reopened {
+				change.Action = "reopened"
+				output.Reopened++
+			} else {
+				output.Updated++
+			}
+			output.Changes = append(output.Changes, change)
+		}
+
+		// Goals whose comment is gone, limited to the scanned directory
+		var stuck []string
+		for _, goal := range existing {
+			file, _, _ := strings.Cut(goal.SourceRef, ":")
+			if seen[goal.ExternalID] || !inDir(file, dir) || slices.Contains(closed, goal.Status) {
+				continue
+			}
+			if !w.canTransition(goal.Status, done) {
+				stuck = append(stuck, fmt.Sprintf("#%d (%s)", goal.ID, goal.Status))
+				continue
+			}
+			if err := changeStatus(tx, w, goal.ID, goal.Status, done, todoRemoved); err != nil {
+				return err
+			}
+			output.Completed++
+			output.Changes = append(output.Changes, types.ImportedTodo{
+				File:   file,
+				Text:   goal.Title,
+				GoalID: int(goal.ID),
+				Action: "completed",
+			})
+		}
+		var messages []string
+		if len(stuck) > 0 {
+			messages = append(messages, fmt.Sprintf("comments removed, but the workflow does not allow moving these goals to %q: %s", done, strings.Join(stuck, ", ")))
+		}
+		if len(stuckOpen) > 0 {
+			messages = append(messages, fmt.Sprintf("comments restored, but the workflow does not allow moving these goals to %q: %s", w.initial(), strings.Join(stuckOpen, ", ")))
+		}
+		output.Message = strings.Join(messages, "; ")
+		return nil
+	})
+	if err != nil {
+		return nil, types.GoalsImportTodosOutput{}, err
+	}
+
+	if output.Changes == nil {
+		output.Changes = []types.ImportedTodo{}
+	}
+	return nil, output, nil
+}
+
+// scanTodos returns the marker comments in a file. Binary and very large
+// files are skipped.
+func scanTodos(root, file string) ([]todoComment, error) {
+	full := filepath.Join(root, filepath.FromSlash(file))
+	info, err := os.Stat(full)
+	if err != nil || info.Size() > maxTodoFileSize {
+		return nil, nil
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return nil, nil
+	}
+
+	var comments []todoComment
+	occurrences := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxTodoFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		m := todoPattern.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		text := strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(m[3]), "*/"), "-->"))
+		text = strings.Join(strings.Fields(text), " ")
+		if text == "" {
+			continue
+		}
+
+		// Identical comments in one file are told apart by their order
+		key := m[1] + "\x00" + text
+		occurrences[key]++
+		comments = append(comments, todoComment{
+			marker:      m[1],
+			author:      strings.TrimSpace(m[2]),
+			text:        text,
+			file:        file,
+			line:        line,
+			fingerprint: todoFingerprint(file, m[1], text, occurrences[key]),
+		})
+	}
+	return comments, scanner.Err()
+}
+
+// todoFingerprint identifies a comment independently of its line number.
+func todoFingerprint(file, marker, text string, occurrence int) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%d", file, marker, text, occurrence)))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (c todoComment) ref() string {
+	return fmt.Sprintf("%s:%d", c.file, c.line)
+}
+
+// todoPriority ranks FIXMEs above TODOs.
+func todoPriority(marker string) int {
+	if marker == "FIXME" {
+		return 50
+	}
+	return 100
+}
+
+func inDir(file, dir string) bool {
+	return dir == "" || file == dir || strings.HasPrefix(file, dir+"/")
+}
@@ -0,0 +1,171 @@
+package goals
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func writeTestFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	full := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		t.Fatalf("Failed to create directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+func TestWalkRepoFiles(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, ".gitignore", "*.log\n/build/\nvendor/\n!keep.log\n")
+	writeTestFile(t, root, "main.go", "")
+	writeTestFile(t, root, "debug.log", "")
+	writeTestFile(t, root, "keep.log", "")
+	writeTestFile(t, root, "build/out.go", "")
+	writeTestFile(t, root, "pkg/build/gen.go", "")
+	writeTestFile(t, root, "pkg/vendor/dep.go", "")
+	writeTestFile(t, root, "pkg/.gitignore", "local_*.go\n")
+	writeTestFile(t, root, "pkg/local_test.go", "")
+	writeTestFile(t, root, "pkg/sub/local_x.go", "")
+	writeTestFile(t, root, "local_root.go", "")
+
+	tests := []struct {
+		name string
+		dir  string
+		want []string
+	}{
+		{
+			name: "Whole tree",
+			dir:  "",
+			want: []string{".gitignore", "keep.log", "local_root.go", "main.go", "pkg/.gitignore", "pkg/build/gen.go"},
+		},
+		{
+			name: "Subdirectory still applies parent rules",
+			dir:  "pkg",
+			want: []string{"pkg/.gitignore", "pkg/build/gen.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := walkRepoFiles(root, tt.dir)
+			if err != nil {
+				t.Fatalf("walkRepoFiles() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("walkRepoFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoalsHandler_GoalsImportTodos(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	ctx := context.Background()
+
+	writeTestFile(t, tempDir, ".gitignore", "vendor/\n")
+	writeTestFile(t, tempDir, "vendor/lib.go", "// TODO: not ours\n")
+	writeTestFile(t, tempDir, "main.go", "package main\n\n// TODO(sam): handle   signals\nfunc main() {} // FIXME crashes on empty input\n")
+	writeTestFile(t, tempDir, "scripts/build.sh", "# TODO: cache downloads\necho \"TODO: not a comment\"\n")
+
+	_, output, err := handler.GoalsImportTodos(ctx, nil, types.GoalsImportTodosInput{})
+	if err != nil {
+		t.Fatalf("GoalsImportTodos() unexpected error: %v", err)
+	}
+	if output.Created != 3 {
+		t.Fatalf("GoalsImportTodos() created = %d, want 3 (changes: %+v)", output.Created, output.Changes)
+	}
+	want := types.ImportedTodo{Marker: "TODO", Author: "sam", Text: "handle signals", File: "main.go", Line: 3, GoalID: output.Changes[0].GoalID, Action: "created"}
+	if output.Changes[0] != want {
+		t.Errorf("GoalsImportTodos() first change = %+v, want %+v", output.Changes[0], want)
+	}
+
+	// Moving a comment updates its goal instead of creating a new one
+	writeTestFile(t, tempDir, "main.go", "package main\n\nimport \"os\"\n\n// TODO(sam): handle signals\nfunc main() { os.Exit(0) }\n")
+	_, output, err = handler.GoalsImportTodos(ctx, nil, types.GoalsImportTodosInput{})
+	if err != nil {
+		t.Fatalf("GoalsImportTodos() unexpected error: %v", err)
+	}
+	if output.Created != 0 || output.Updated != 1 || output.Completed != 1 || output.Unchanged != 1 {
+		t.Errorf("GoalsImportTodos() = %+v, want 1 updated, 1 completed, 1 unchanged", output)
+	}
+
+	_, list, err := handler.GoalsList(ctx, nil, types.GoalsListInput{Owner: "sam"})
+	if err != nil {
+		t.Fatalf("GoalsList() unexpected error: %v", err)
+	}
+	if len(list.Goals) != 1 || list.Goals[0].SourceRef != "main.go:5" || list.Goals[0].Source != "todo" {
+		t.Errorf("GoalsList() = %+v, want the TODO goal at main.go:5", list.Goals)
+	}
+
+	// Scanning a subdirectory leaves goals elsewhere alone
+	writeTestFile(t, tempDir, "main.go", "package main\n")
+	_, output, err = handler.GoalsImportTodos(ctx, nil, types.GoalsImportTodosInput{Path: func() *string { s := "scripts"; return &s }()})
+	if err != nil {
+		t.Fatalf("GoalsImportTodos() unexpected error: %v", err)
+	}
+	if output.Completed != 0 || output.Unchanged != 1 {
+		t.Errorf("GoalsImportTodos(scripts) = %+v, want nothing completed", output)
+	}
+
+	// A comment that comes back reopens the goal completed when it was gone,
+	// while a goal closed by hand stays closed
+	_, output, err = handler.GoalsImportTodos(ctx, nil, types.GoalsImportTodosInput{})
+	if err != nil {
+		t.Fatalf("GoalsImportTodos() unexpected error: %v", err)
+	}
+	if output.Completed != 1 {
+		t.Fatalf("GoalsImportTodos() = %+v, want the signals goal completed", output)
+	}
+	signals := output.Changes[0].GoalID
+	_, list, err = handler.GoalsList(ctx, nil, types.GoalsListInput{Query: "cache downloads"})
+	if err != nil || len(list.Goals) != 1 {
+		t.Fatalf("GoalsList() = %+v, %v, want the cache goal", list.Goals, err)
+	}
+	cache := list.Goals[0].ID
+	if _, _, err := handler.GoalsUpdate(ctx, nil, types.GoalsUpdateInput{ID: cache, Status: func() *string { s := "done"; return &s }()}); err != nil {
+		t.Fatalf("GoalsUpdate() unexpected error: %v", err)
+	}
+
+	writeTestFile(t, tempDir, "main.go", "package main\n\n// TODO(sam): handle signals\n")
+	_, output, err = handler.GoalsImportTodos(ctx, nil, types.GoalsImportTodosInput{})
+	if err != nil {
+		t.Fatalf("GoalsImportTodos() unexpected error: %v", err)
+	}
+	if output.Reopened != 1 || output.Unchanged != 1 || len(output.Changes) != 1 || output.Changes[0].GoalID != signals || output.Changes[0].Action != "reopened" {
+		t.Errorf("GoalsImportTodos() = %+v, want the signals goal reopened and the cache goal unchanged", output)
+	}
+	_, history, err := handler.GoalsHistory(ctx, nil, types.GoalsHistoryInput{ID: signals})
+	if err != nil {
+		t.Fatalf("GoalsHistory() unexpected error: %v", err)
+	}
+	if last := history.Changes[len(history.Changes)-1]; last.From != "done" || last.To != "active" {
+		t.Errorf("GoalsHistory() last change = %+v, want done to active", last)
+	}
+	if closed := history.Changes[len(history.Changes)-2]; closed.Reason != todoRemoved {
+		t.Errorf("GoalsHistory() completion = %+v, want reason %s", closed, todoRemoved)
+	}
+	_, cacheHistory, _ := handler.GoalsHistory(ctx, nil, types.GoalsHistoryInput{ID: cache})
+	if last := cacheHistory.Changes[len(cacheHistory.Changes)-1]; last.To != "done" {
+		t.Errorf("GoalsHistory() of the goal closed by hand = %+v, want it still done", cacheHistory.Changes)
+	}
+
+	if _, _, err := handler.GoalsImportTodos(ctx, nil, types.GoalsImportTodosInput{Path: func() *string { s := "../elsewhere"; return &s }()}); err == nil {
+		t.Errorf("GoalsImportTodos() with path outside the repository expected error, got nil")
+	}
+}
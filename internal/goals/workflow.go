@@ -0,0 +1,398 @@
+package goals
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thornzero/project-manager/internal/models"
+	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
+)
+
+// Status categories give configurable statuses a fixed meaning for planning:
+// todo and active goals are actionable, waiting goals are parked, and done and
+// cancelled goals are closed.
+const (
+	categoryTodo      = "todo"
+	categoryActive    = "active"
+	categoryWaiting   = "waiting"
+	categoryDone      = "done"
+	categoryCancelled = "cancelled"
+)
+
+var validCategories = []string{categoryTodo, categoryActive, categoryWaiting, categoryDone, categoryCancelled}
+
+// workflowPresets are the built-in workflows accepted by goals_workflow_set.
+// "simple" matches the statuses goals have always had and is used until a
+// project configures its own workflow.
+var workflowPresets = map[string]types.GoalsWorkflowSetInput{
+	"simple": {
+		Statuses: []types.GoalWorkflowStatus{
+			{Name: "active", Category: categoryActive, Initial: true},
+			{Name: "paused", Category: categoryWaiting},
+			{Name: "done", Category: categoryDone},
+		},
+		Transitions: []types.GoalWorkflowTransition{
+			{From: "active", To: "paused"},
+			{From: "active", To: "done"},
+			{From: "paused", To: "active"},
+			{From: "paused", To: "done"},
+			{From: "done", To: "active"},
+		},
+	},
+	"kanban": {
+		Statuses: []types.GoalWorkflowStatus{
+			{Name: "todo", Category: categoryTodo, Initial: true},
+			{Name: "in_progress", Category: categoryActive},
+			{Name: "blocked", Category: categoryWaiting},
+			{Name: "in_review", Category: categoryActive},
+			{Name: "done", Category: categoryDone},
+			{Name: "cancelled", Category: categoryCancelled},
+		},
+		Transitions: []types.GoalWorkflowTransition{
+			{From: "todo", To: "in_progress"},
+			{From: "todo", To: "cancelled"},
+			{From: "in_progress", To: "todo"},
+			{From: "in_progress", To: "blocked"},
+			{From: "in_progress", To: "in_review"},
+			{From: "in_progress", To: "cancelled"},
+			{From: "blocked", To: "in_progress"},
+			{From: "blocked", To: "cancelled"},
+			{From: "in_review", To: "in_progress"},
+			{From: "in_review", To: "done"},
+			{From: "done", To: "in_progress"},
+			{From: "cancelled", To: "todo"},
+		},
+	},
+}
+
+// workflow is the set of goal statuses and allowed transitions for a project.
+type workflow struct {
+	statuses    []models.GoalStatus
+	transitions map[string]map[string]bool
+}
+
+// loadWorkflow reads the configured workflow, falling back to the "simple"
+// preset when the project has not configured one.
+func loadWorkflow(db *gorm.DB) (*workflow, error) {
+	var statuses []models.GoalStatus
+	if err := db.Order("position ASC").Find(&statuses).Error; err != nil {
+		return nil, err
+	}
+	if len(statuses) == 0 {
+		return buildWorkflow(workflowPresets["simple"])
+	}
+
+	var transitions []models.GoalTransition
+	if err := db.Find(&transitions).Error; err != nil {
+		return nil, err
+	}
+
+	w := &workflow{statuses: statuses, transitions: make(map[string]map[string]bool)}
+	for _, t := range transitions {
+		w.allow(t.FromStatus, t.ToStatus)
+	}
+	return w, nil
+}
+
+// buildWorkflow validates a workflow definition and turns it into a workflow.
+func buildWorkflow(def types.GoalsWorkflowSetInput) (*workflow, error) {
+	if len(def.Statuses) == 0 {
+		return nil, fmt.Errorf("at least one status is required")
+	}
+
+	w := &workflow{transitions: make(map[string]map[string]bool)}
+	initial := -1
+	hasDone := false
+	for i, st := range def.Statuses {
+		name := strings.TrimSpace(st.Name)
+		if name == "" {
+			return nil, fmt.Errorf("status names cannot be empty")
+		}
+		if _, ok := w.status(name); ok {
+			return nil, fmt.Errorf("duplicate status %q", name)
+		}
+		if !slices.Contains(validCategories, st.Category) {
+			return nil, fmt.Errorf("status %q has invalid category %q (allowed: %s)", name, st.Category, strings.Join(validCategories, ", "))
+		}
+		if st.Initial {
+			if initial >= 0 {
+				return nil, fmt.Errorf("only one status can be initial, got %q and %q", w.statuses[initial].Name, name)
+			}
+			initial = i
+		}
+		hasDone = hasDone || st.Category == categoryDone
+		w.statuses = append(w.statuses, models.GoalStatus{
+			Name:     name,
+			Category: st.Category,
+			Position: i,
+			Initial:  st.Initial,
+		})
+	}
+	if initial < 0 {
+		w.statuses[0].Initial = true
+	}
+	if !hasDone {
+		return nil, fmt.Errorf("at least one status must have category %q", categoryDone)
+	}
+
+	for _, t := range def.Transitions {
+		for _, name := range []string{t.From, t.To} {
+			if _, ok := w.status(name); !ok {
+				return nil, fmt.Errorf("transition %s -> %s references unknown status %q", t.From, t.To, name)
+			}
+		}
+		if t.From == t.To {
+			return nil, fmt.Errorf("transition %s -> %s does not change status", t.From, t.To)
+		}
+		w.allow(t.From, t.To)
+	}
+
+	return w, nil
+}
+
+func (w *workflow) allow(from, to string) {
+	if w.transitions[from] == nil {
+		w.transitions[from] = make(map[string]bool)
+	}
+	w.transitions[from][to] = true
+}
+
+// status looks up a status by name.
+func (w *workflow) status(name string) (models.GoalStatus, bool) {
+	for _, st := range w.statuses {
+		if st.Name == name {
+			return st, true
+		}
+	}
+	return models.GoalStatus{}, false
+}
+
+// initial returns the status new goals start in.
+func (w *workflow) initial() string {
+	for _, st := range w.statuses {
+		if st.Initial {
+			return st.Name
+		}
+	}
+	return w.statuses[0].Name
+}
+
+// statusFor returns the first status in a category, for mapping states from
+// other tools. Workflows without such a status fall back to the closest one:
+// cancelled goals become done, everything else starts out in the initial
+// status.
+func (w *workflow) statusFor(category string) string {
+	if statuses := w.statusesIn(category); len(statuses) > 0 {
+		return statuses[0]
+	}
+	if category == categoryCancelled {
+		return w.statusesIn(categoryDone)[0]
+	}
+	return w.initial()
+}
+
+// canTransition reports whether a goal may move between two statuses. Goals
+// left in a status the workflow no longer knows about may move anywhere.
+func (w *workflow) canTransition(from, to string) bool {
+	if _, ok := w.status(from); !ok {
+		return true
+	}
+	return w.transitions[from][to]
+}
+
+// statusesIn returns the names of all statuses in the given categories.
+func (w *workflow) statusesIn(categories ...string) []string {
+	names := []string{}
+	for _, st := range w.statuses {
+		if slices.Contains(categories, st.Category) {
+			names = append(names, st.Name)
+		}
+	}
+	return names
+}
+
+// names returns all status names in workflow order.
+func (w *workflow) names() []string {
+	names := make([]string, 0, len(w.statuses))
+	for _, st := range w.statuses {
+		names = append(names, st.Name)
+	}
+	return names
+}
+
+// toTypes converts the workflow into its MCP representation, with
+// transitions sorted by workflow order so the output is stable.
+func (w *workflow) toTypes() ([]types.GoalWorkflowStatus, []types.GoalWorkflowTransition) {
+	statuses := make([]types.GoalWorkflowStatus, 0, len(w.statuses))
+	position := make(map[string]int)
+	for i, st := range w.statuses {
+		position[st.Name] = i
+		statuses = append(statuses, types.GoalWorkflowStatus{
+			Name:     st.Name,
+			Category: st.Category,
+			Initial:  st.Initial,
+		})
+	}
+
+	transitions := []types.GoalWorkflowTransition{}
+	for from, tos := range w.transitions {
+		for to := range tos {
+			transitions = append(transitions, types.GoalWorkflowTransition{From: from, To: to})
+		}
+	}
+	sort.Slice(transitions, func(i, j int) bool {
+		a, b := transitions[i], transitions[j]
+		if a.From != b.From {
+			return position[a.From] < position[b.From]
+		}
+		return position[a.To] < position[b.To]
+	})
+
+	return statuses, transitions
+}
+
+// changeStatus moves a goal to a new status and records the transition,
+// with the reason an importer made it, if any. Entering a done status sets
+// the goal's completion time; leaving it clears the time again.
+func changeStatus(tx *gorm.DB, w *workflow, goalID uint, from, to, reason string) error {
+	updates := map[string]interface{}{"status": to}
+	fromStatus, _ := w.status(from)
+	toStatus, _ := w.status(to)
+	switch {
+	case toStatus.Category == categoryDone && fromStatus.Category != categoryDone:
+		updates["completed_at"] = time.Now()
+	case toStatus.Category != categoryDone && fromStatus.Category == categoryDone:
+		updates["completed_at"] = nil
+	}
+
+	err := tx.Model(&models.Goal{}).Where("id = ?", goalID).Updates(updates).Error
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.GoalStatusChange{GoalID: goalID, FromStatus: from, ToStatus: to, Reason: reason}).Error
+}
+
+// GoalsWorkflowGet returns the project's goal statuses and allowed transitions.
+func (h *GoalsHandler) GoalsWorkflowGet(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsWorkflowGetInput) (*mcp.CallToolResult, types.GoalsWorkflowGetOutput, error) {
+	w, err := loadWorkflow(h.server.GetDB())
+	if err != nil {
+		return nil, types.GoalsWorkflowGetOutput{}, err
+	}
+
+	statuses, transitions := w.toTypes()
+	return nil, types.GoalsWorkflowGetOutput{Statuses: statuses, Transitions: transitions}, nil
+}
+
+// GoalsWorkflowSet replaces the project's goal workflow.
+//
+// Existing goals must end up in a status of the new workflow: either their
+// status still exists, or the input remaps it to one that does. Remapped goals
+// get a status change recorded like any other transition.
+func (h *GoalsHandler) GoalsWorkflowSet(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsWorkflowSetInput) (*mcp.CallToolResult, types.GoalsWorkflowSetOutput, error) {
+	def := input
+	if input.Preset != nil && *input.Preset != "" {
+		preset, ok := workflowPresets[*input.Preset]
+		if !ok {
+			return nil, types.GoalsWorkflowSetOutput{}, fmt.Errorf("unknown preset %q (available: simple, kanban)", *input.Preset)
+		}
+		def.Statuses = preset.Statuses
+		def.Transitions = preset.Transitions
+	}
+
+	w, err := buildWorkflow(def)
+	if err != nil {
+		return nil, types.GoalsWorkflowSetOutput{}, err
+	}
+	for from, to := range input.Remap {
+		if _, ok := w.status(to); !ok {
+			return nil, types.GoalsWorkflowSetOutput{}, fmt.Errorf("remap %s -> %s targets unknown status %q", from, to, to)
+		}
+	}
+
+	remapped := 0
+	err = h.server.GetDB().Transaction(func(tx *gorm.DB) error {
+		var goals []models.Goal
+		if err := tx.Where("status NOT IN ?", w.names()).Find(&goals).Error; err != nil {
+			return err
+		}
+		var unmapped []string
+		for _, g := range goals {
+			to, ok := input.Remap[g.Status]
+			if !ok {
+				if !slices.Contains(unmapped, g.Status) {
+					unmapped = append(unmapped, g.Status)
+				}
+				continue
+			}
+			if err := changeStatus(tx, w, g.ID, g.Status, to, ""); err != nil {
+				return err
+			}
+			remapped++
+		}
+		if len(unmapped) > 0 {
+			return fmt.Errorf("existing goals use statuses not in the new workflow: %s (use remap to move them)", strings.Join(unmapped, ", "))
+		}
+
+		if err := tx.Where("1 = 1").Delete(&models.GoalTransition{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("1 = 1").Delete(&models.GoalStatus{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&w.statuses).Error; err != nil {
+			return err
+		}
+		for from, tos := range w.transitions {
+			for to := range tos {
+				if err := tx.Create(&models.GoalTransition{FromStatus: from, ToStatus: to}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, types.GoalsWorkflowSetOutput{}, err
+	}
+
+	statuses, transitions := w.toTypes()
+	return nil, types.GoalsWorkflowSetOutput{
+		Statuses:    statuses,
+		Transitions: transitions,
+		Remapped:    remapped,
+	}, nil
+}
+
+// GoalsHistory returns the recorded status transitions of a goal.
+func (h *GoalsHandler) GoalsHistory(ctx context.Context, req *mcp.CallToolRequest, input types.GoalsHistoryInput) (*mcp.CallToolResult, types.GoalsHistoryOutput, error) {
+	if input.ID == 0 {
+		return nil, types.GoalsHistoryOutput{}, fmt.Errorf("id required")
+	}
+
+	var changes []models.GoalStatusChange
+	err := h.server.GetDB().
+		Where("goal_id = ?", input.ID).
+		Order("changed_at ASC, id ASC").
+		Find(&changes).Error
+	if err != nil {
+		return nil, types.GoalsHistoryOutput{}, err
+	}
+
+	result := make([]types.GoalStatusChange, 0, len(changes))
+	for _, c := range changes {
+		result = append(result, types.GoalStatusChange{
+			From:      c.FromStatus,
+			To:        c.ToStatus,
+			Reason:    c.Reason,
+			ChangedAt: c.ChangedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return nil, types.GoalsHistoryOutput{Changes: result}, nil
+}
@@ -0,0 +1,168 @@
+package goals
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thornzero/project-manager/internal/server"
+	"github.com/thornzero/project-manager/internal/types"
+)
+
+func TestGoalsHandler_GoalsWorkflowSet(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	ctx := context.Background()
+
+	// A goal created under the default workflow starts out "active"
+	legacy := addTestGoal(t, handler, "Legacy goal", 1)
+
+	kanban := "kanban"
+	tests := []struct {
+		name      string
+		input     types.GoalsWorkflowSetInput
+		wantError bool
+	}{
+		{
+			name:      "Existing goals need a remap",
+			input:     types.GoalsWorkflowSetInput{Preset: &kanban},
+			wantError: true,
+		},
+		{
+			name:      "Unknown preset",
+			input:     types.GoalsWorkflowSetInput{Preset: func() *string { s := "waterfall"; return &s }()},
+			wantError: true,
+		},
+		{
+			name: "Workflow without a done status",
+			input: types.GoalsWorkflowSetInput{
+				Statuses: []types.GoalWorkflowStatus{{Name: "open", Category: "todo"}},
+			},
+			wantError: true,
+		},
+		{
+			name: "Transition to unknown status",
+			input: types.GoalsWorkflowSetInput{
+				Statuses:    []types.GoalWorkflowStatus{{Name: "open", Category: "todo"}, {Name: "closed", Category: "done"}},
+				Transitions: []types.GoalWorkflowTransition{{From: "open", To: "shipped"}},
+			},
+			wantError: true,
+		},
+		{
+			name: "Kanban preset with remap",
+			input: types.GoalsWorkflowSetInput{
+				Preset: &kanban,
+				Remap:  map[string]string{"active": "in_progress"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := handler.GoalsWorkflowSet(ctx, nil, tt.input)
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("GoalsWorkflowSet() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("GoalsWorkflowSet() unexpected error: %v", err)
+				return
+			}
+
+			if output.Remapped != 1 {
+				t.Errorf("GoalsWorkflowSet() remapped = %v, want 1", output.Remapped)
+			}
+		})
+	}
+
+	_, wf, err := handler.GoalsWorkflowGet(ctx, nil, types.GoalsWorkflowGetInput{})
+	if err != nil {
+		t.Fatalf("GoalsWorkflowGet() unexpected error: %v", err)
+	}
+	if len(wf.Statuses) != 6 || wf.Statuses[0].Name != "todo" || !wf.Statuses[0].Initial {
+		t.Errorf("GoalsWorkflowGet() statuses = %+v, want kanban statuses starting with todo", wf.Statuses)
+	}
+
+	_, history, err := handler.GoalsHistory(ctx, nil, types.GoalsHistoryInput{ID: legacy})
+	if err != nil {
+		t.Fatalf("GoalsHistory() unexpected error: %v", err)
+	}
+	if len(history.Changes) != 2 || history.Changes[1].From != "active" || history.Changes[1].To != "in_progress" {
+		t.Errorf("GoalsHistory() = %+v, want creation then active -> in_progress", history.Changes)
+	}
+}
+
+func TestGoalsHandler_GoalsUpdateTransitions(t *testing.T) {
+	// Setup
+	tempDir := t.TempDir()
+	srv, err := server.NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	handler := NewGoalsHandler(srv)
+	ctx := context.Background()
+
+	kanban := "kanban"
+	if _, _, err := handler.GoalsWorkflowSet(ctx, nil, types.GoalsWorkflowSetInput{Preset: &kanban}); err != nil {
+		t.Fatalf("GoalsWorkflowSet() unexpected error: %v", err)
+	}
+
+	id := addTestGoal(t, handler, "Ship it", 1)
+
+	status := func(s string) *string { return &s }
+	tests := []struct {
+		name      string
+		status    *string
+		wantError bool
+	}{
+		{name: "Unknown status", status: status("paused"), wantError: true},
+		{name: "Skipping review is not allowed", status: status("done"), wantError: true},
+		{name: "Start work", status: status("in_progress")},
+		{name: "Same status is a no-op", status: status("in_progress")},
+		{name: "Request review", status: status("in_review")},
+		{name: "Finish", status: status("done")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := handler.GoalsUpdate(ctx, nil, types.GoalsUpdateInput{ID: id, Status: tt.status})
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("GoalsUpdate() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("GoalsUpdate() unexpected error: %v", err)
+			}
+		})
+	}
+
+	_, history, err := handler.GoalsHistory(ctx, nil, types.GoalsHistoryInput{ID: id})
+	if err != nil {
+		t.Fatalf("GoalsHistory() unexpected error: %v", err)
+	}
+	want := []string{"todo", "in_progress", "in_review", "done"}
+	if len(history.Changes) != len(want) {
+		t.Fatalf("GoalsHistory() = %+v, want %d changes", history.Changes, len(want))
+	}
+	for i, change := range history.Changes {
+		if change.To != want[i] {
+			t.Errorf("GoalsHistory() change %d to = %q, want %q", i, change.To, want[i])
+		}
+	}
+}
@@ -195,10 +195,10 @@ func (b *Builder) AddChecklist(items []string, checked []bool) *Builder {
 	for i, item := range items {
 		listItem := &ast.ListItem{}
 
-		// Add checkbox
-		checkbox := "- [ ]"
+		// Add checkbox; the list marker itself is written by String
+		checkbox := "[ ]"
 		if i < len(checked) && checked[i] {
-			checkbox = "- [x]"
+			checkbox = "[x]"
 		}
 
 		paragraph := &ast.Paragraph{}
@@ -225,6 +225,10 @@ func (b *Builder) String() string {
 	// Walk the AST and build markdown string
 	ast.WalkFunc(b.doc, func(node ast.Node, entering bool) ast.WalkStatus {
 		if !entering {
+			// Separate lists from whatever follows them
+			if _, ok := node.(*ast.List); ok {
+				result.WriteString("\n")
+			}
 			return ast.GoToNext
 		}
 
@@ -129,6 +129,18 @@ func TestBuilder_AddLink(t *testing.T) {
 	}
 }
 
+func TestBuilder_AddChecklist(t *testing.T) {
+	builder := NewBuilder()
+	builder.AddChecklist([]string{"Done item", "Open item"}, []bool{true})
+	builder.AddHeader(2, "Next")
+
+	result := builder.String()
+	expected := "- [x] Done item\n- [ ] Open item\n\n## Next\n\n"
+	if result != expected {
+		t.Errorf("AddChecklist() = %q, want %q", result, expected)
+	}
+}
+
 func TestBuilder_AddSection(t *testing.T) {
 	builder := NewBuilder()
 	builder.AddSection(2, "Test Section", func(b *Builder) {
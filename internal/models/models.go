@@ -6,29 +6,206 @@ import (
 
 // Goal represents a project goal
 type Goal struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Title        string     `gorm:"not null" json:"title"`
+	Priority     int        `gorm:"default:100" json:"priority"`
+	Status       string     `gorm:"not null;default:active" json:"status"` // one of the GoalStatus names
+	Notes        string     `json:"notes"`
+	DueAt        *time.Time `json:"due_at"`
+	Tags         string     `gorm:"default:''" json:"tags"` // comma-separated tags
+	Owner        string     `gorm:"default:''" json:"owner"`
+	Milestone    string     `gorm:"default:''" json:"milestone"`
+	AutoComplete bool       `gorm:"default:false" json:"auto_complete"`                  // move to done once all criteria are checked
+	Source       string     `gorm:"default:'';index:idx_goal_source" json:"source"`      // where the goal was imported from, empty for manual goals
+	ExternalID   string     `gorm:"default:'';index:idx_goal_source" json:"external_id"` // stable ID within the source, used to dedupe imports
+	SourceRef    string     `gorm:"default:''" json:"source_ref"`                        // human-readable location in the source, e.g. file:line
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	CompletedAt  *time.Time `gorm:"index" json:"completed_at"` // set while the goal is in a done status
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// GoalItem represents an acceptance criterion of a goal
+type GoalItem struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	GoalID    uint       `gorm:"not null;index" json:"goal_id"`
+	Position  int        `gorm:"default:0" json:"position"`
+	Text      string     `gorm:"not null" json:"text"`
+	Checked   bool       `gorm:"default:false" json:"checked"`
+	CheckedAt *time.Time `json:"checked_at"`
+}
+
+// GoalDependency represents a "blocked by" edge between two goals
+type GoalDependency struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	GoalID      uint      `gorm:"not null;uniqueIndex:idx_goal_dependency" json:"goal_id"`       // the blocked goal
+	DependsOnID uint      `gorm:"not null;uniqueIndex:idx_goal_dependency" json:"depends_on_id"` // the goal it waits on
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// GoalStatus represents a status in the project's goal workflow
+type GoalStatus struct {
+	Name     string `gorm:"primaryKey" json:"name"`
+	Category string `gorm:"check:category IN ('todo','active','waiting','done','cancelled');not null" json:"category"`
+	Position int    `gorm:"default:0" json:"position"`
+	Initial  bool   `gorm:"default:false" json:"initial"`
+}
+
+// GoalTransition represents an allowed move between two goal statuses
+type GoalTransition struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	FromStatus string `gorm:"not null;uniqueIndex:idx_goal_transition" json:"from_status"`
+	ToStatus   string `gorm:"not null;uniqueIndex:idx_goal_transition" json:"to_status"`
+}
+
+// GoalStatusChange records a goal moving from one status to another
+type GoalStatusChange struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	GoalID     uint      `gorm:"not null;index" json:"goal_id"`
+	FromStatus string    `json:"from_status"` // empty when the goal was created
+	ToStatus   string    `gorm:"not null" json:"to_status"`
+	Reason     string    `gorm:"default:''" json:"reason"` // why an importer made the change, empty when it was asked for
+	ChangedAt  time.Time `gorm:"autoCreateTime" json:"changed_at"`
+}
+
+// GoalSession records a period of work on a goal
+type GoalSession struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	GoalID    uint       `gorm:"not null;index" json:"goal_id"`
+	StartedAt time.Time  `gorm:"not null;index" json:"started_at"`
+	EndedAt   *time.Time `gorm:"index" json:"ended_at"` // nil while the session is open
+	Note      string     `json:"note"`
+}
+
+// GoalLink connects a goal to something outside the goals table: an ADR, a
+// changelog entry, a file or a git commit
+type GoalLink struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
-	Title     string    `gorm:"not null" json:"title"`
-	Priority  int       `gorm:"default:100" json:"priority"`
-	Status    string    `gorm:"check:status IN ('active','paused','done');default:active" json:"status"`
-	Notes     string    `json:"notes"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	GoalID    uint      `gorm:"not null;uniqueIndex:idx_goal_link" json:"goal_id"`
+	Kind      string    `gorm:"check:kind IN ('adr','changelog','file','commit');not null;uniqueIndex:idx_goal_link" json:"kind"`
+	Target    string    `gorm:"not null;uniqueIndex:idx_goal_link" json:"target"` // ADR ID, changelog entry ID, repo-relative path or commit SHA
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
 
 // ADR represents an Architecture Decision Record
 type ADR struct {
-	ID        string    `gorm:"primaryKey" json:"id"`
-	Title     string    `gorm:"not null" json:"title"`
-	Content   string    `gorm:"type:text" json:"content"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID           string    `gorm:"primaryKey" json:"id"`
+	Number       int       `gorm:"not null;default:0;index" json:"number"` // the NNN of ADR-NNN
+	Title        string    `gorm:"not null" json:"title"`
+	Status       string    `gorm:"not null;default:proposed" json:"status"`
+	Date         time.Time `json:"date"`                       // when the decision was made
+	Deciders     string    `gorm:"default:''" json:"deciders"` // comma-separated names
+	Scope        string    `gorm:"default:''" json:"scope"`    // comma-separated path globs the decision governs
+	Context      string    `gorm:"type:text" json:"context"`
+	Decision     string    `gorm:"type:text" json:"decision"`
+	Consequences string    `gorm:"type:text" json:"consequences"`
+	FilePath     string    `gorm:"default:''" json:"file_path"` // markdown file, relative to the repo root
+	Content      string    `gorm:"type:text" json:"content"`    // the rendered markdown
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// ADRRelation records how one ADR relates to another, e.g. that it
+// supersedes it
+type ADRRelation struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	FromID    string    `gorm:"not null;uniqueIndex:idx_adr_relation" json:"from_id"`
+	Kind      string    `gorm:"not null;uniqueIndex:idx_adr_relation" json:"kind"`
+	ToID      string    `gorm:"not null;uniqueIndex:idx_adr_relation" json:"to_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
 
 // CIRun represents a CI test run
 type CIRun struct {
-	ID         uint       `gorm:"primaryKey" json:"id"`
-	Scope      string     `json:"scope"`
-	Status     string     `gorm:"check:status IN ('pass','fail','error');not null" json:"status"`
-	StartedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"started_at"`
-	FinishedAt *time.Time `json:"finished_at"`
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	Scope      string         `json:"scope"`
+	Runner     string         `gorm:"default:'go'" json:"runner"` // go, npm, vitest, jest, pytest or cargo
+	Run        string         `json:"run"`                        // -run pattern the run was limited to, for reruns and benchmark runs
+	RerunOf    uint           `gorm:"default:0" json:"rerun_of"`  // run whose failed test this run retried
+	Commit     string         `json:"commit"`                     // HEAD when the run started
+	Tree       string         `gorm:"index" json:"tree"`          // hash of the working tree, uncommitted changes included
+	Status     string         `gorm:"check:chk_ci_runs_status,status IN ('running','pass','fail','error','timeout','cancelled');not null" json:"status"`
+	StartedAt  time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"started_at"`
+	FinishedAt *time.Time     `json:"finished_at"`
+	Output     string         `gorm:"type:text" json:"output"` // output not belonging to any test, such as build errors
+	Coverage   *float64       `json:"coverage"`                // percentage of statements covered, when collected
+	Results    []CITestResult `gorm:"foreignKey:RunID;constraint:OnDelete:CASCADE" json:"results"`
+}
+
+// CITestResult is the outcome of one package or test in a CI run, from the
+// go test -json event stream
+type CITestResult struct {
+	ID      uint    `gorm:"primaryKey" json:"id"`
+	RunID   uint    `gorm:"not null;index" json:"run_id"`
+	Package string  `gorm:"not null;index" json:"package"`
+	Test    string  `gorm:"default:'';index" json:"test"` // empty for the package itself
+	Status  string  `gorm:"check:status IN ('pass','fail','skip');not null" json:"status"`
+	Elapsed float64 `json:"elapsed"` // seconds
+	Output  string  `gorm:"type:text" json:"output"`
+}
+
+// CICoverage is the statement coverage of a package, or of a function when
+// Function is set, in a CI run with coverage
+type CICoverage struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	RunID      uint   `gorm:"not null;index" json:"run_id"`
+	Package    string `gorm:"not null" json:"package"`
+	Function   string `json:"function"` // empty for the package row
+	File       string `json:"file"`     // relative to the repository root
+	Line       int    `json:"line"`
+	Statements int    `json:"statements"`
+	Covered    int    `json:"covered"`
+}
+
+// CICoverageBlock is one block of a cover profile, kept to check the
+// coverage of changed lines
+type CICoverageBlock struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	RunID      uint   `gorm:"not null;index" json:"run_id"`
+	File       string `gorm:"not null" json:"file"` // relative to the repository root
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+	Statements int    `json:"statements"`
+	Count      int    `json:"count"`
+}
+
+// CIBenchmark is one sample of a benchmark in a CI run, keyed by name and
+// commit for comparison across runs
+type CIBenchmark struct {
+	ID          uint    `gorm:"primaryKey" json:"id"`
+	RunID       uint    `gorm:"not null;index" json:"run_id"`
+	Package     string  `gorm:"not null" json:"package"`
+	Name        string  `gorm:"not null;index:idx_ci_benchmarks_name_commit" json:"name"` // without the GOMAXPROCS suffix
+	Commit      string  `gorm:"index:idx_ci_benchmarks_name_commit" json:"commit"`
+	Procs       int     `json:"procs"`
+	Iterations  int64   `json:"iterations"`
+	NsPerOp     float64 `json:"ns_per_op"`
+	BytesPerOp  float64 `json:"bytes_per_op"`
+	AllocsPerOp float64 `json:"allocs_per_op"`
+}
+
+// CIVetRun is one run of go vet and the linters installed over a scope
+type CIVetRun struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Scope       string         `json:"scope"`
+	Tools       string         `json:"tools"` // comma-separated tools that ran
+	Commit      string         `json:"commit"`
+	Tree        string         `gorm:"index" json:"tree"`
+	Status      string         `gorm:"check:chk_ci_vet_runs_status,status IN ('pass','fail','error');not null" json:"status"`
+	StartedAt   time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"started_at"`
+	FinishedAt  *time.Time     `json:"finished_at"`
+	Output      string         `gorm:"type:text" json:"output"` // output the tools printed besides their findings
+	Diagnostics []CIDiagnostic `gorm:"foreignKey:RunID;constraint:OnDelete:CASCADE" json:"diagnostics"`
+}
+
+// CIDiagnostic is one finding of a static analysis tool in a vet run
+type CIDiagnostic struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	RunID    uint   `gorm:"not null;index" json:"run_id"`
+	Tool     string `gorm:"not null" json:"tool"`     // vet, staticcheck or golangci-lint
+	Analyzer string `gorm:"not null" json:"analyzer"` // analyzer, check code or linter
+	File     string `json:"file"`                     // relative to the repository root
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Message  string `gorm:"type:text" json:"message"`
 }
 
 // MarkdownTemplate represents a markdown template
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/thornzero/project-manager/internal/models"
 	"gorm.io/driver/sqlite"
@@ -12,6 +15,9 @@ import (
 	"gorm.io/gorm/logger"
 )
 
+// adrNumber matches the number at the end of an ADR ID such as ADR-012.
+var adrNumber = regexp.MustCompile(`\d+$`)
+
 type Server struct {
 	db       *gorm.DB
 	repoRoot string
@@ -37,8 +43,22 @@ func NewServer(repoRoot string) (*Server, error) {
 	// Auto-migrate the schema
 	err = db.AutoMigrate(
 		&models.Goal{},
+		&models.GoalDependency{},
+		&models.GoalItem{},
+		&models.GoalStatus{},
+		&models.GoalTransition{},
+		&models.GoalStatusChange{},
+		&models.GoalSession{},
+		&models.GoalLink{},
 		&models.ADR{},
+		&models.ADRRelation{},
 		&models.CIRun{},
+		&models.CITestResult{},
+		&models.CICoverage{},
+		&models.CICoverageBlock{},
+		&models.CIBenchmark{},
+		&models.CIVetRun{},
+		&models.CIDiagnostic{},
 		&models.MarkdownTemplate{},
 		&models.TemplateVariable{},
 		&models.PreferredTool{},
@@ -49,12 +69,98 @@ func NewServer(repoRoot string) (*Server, error) {
 		return nil, err
 	}
 
+	// Goal statuses are configurable per project, so the check constraint
+	// older databases were created with has to go
+	if db.Migrator().HasConstraint(&models.Goal{}, "chk_goals_status") {
+		if err := db.Migrator().DropConstraint(&models.Goal{}, "chk_goals_status"); err != nil {
+			return nil, fmt.Errorf("failed to drop legacy goal status constraint: %v", err)
+		}
+	}
+
+	if err := migrateCIRunStatus(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate CI run status constraint: %v", err)
+	}
+
+	if err := backfillGoalTimestamps(db); err != nil {
+		return nil, fmt.Errorf("failed to backfill goal timestamps: %v", err)
+	}
+
+	if err := backfillADRNumbers(db); err != nil {
+		return nil, fmt.Errorf("failed to backfill ADR numbers: %v", err)
+	}
+
 	server := &Server{db: db, repoRoot: repoRoot}
 	server.migrateChangelogToDB()
 
 	return server, nil
 }
 
+// migrateCIRunStatus recreates the CI run status check constraint on
+// databases created before runs could be running, time out or be cancelled.
+func migrateCIRunStatus(db *gorm.DB) error {
+	var table string
+	if err := db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", "ci_runs").Scan(&table).Error; err != nil {
+		return err
+	}
+	if strings.Contains(table, "'timeout'") {
+		return nil
+	}
+	if db.Migrator().HasConstraint(&models.CIRun{}, "chk_ci_runs_status") {
+		if err := db.Migrator().DropConstraint(&models.CIRun{}, "chk_ci_runs_status"); err != nil {
+			return err
+		}
+	}
+	return db.Migrator().CreateConstraint(&models.CIRun{}, "chk_ci_runs_status")
+}
+
+// backfillGoalTimestamps fills in creation and completion times for goals
+// created before they were recorded, using the status history where there is
+// one and the last update time otherwise.
+func backfillGoalTimestamps(db *gorm.DB) error {
+	err := db.Exec(`UPDATE goals SET created_at = COALESCE(
+		(SELECT MIN(changed_at) FROM goal_status_changes c WHERE c.goal_id = goals.id),
+		updated_at, CURRENT_TIMESTAMP) WHERE created_at IS NULL`).Error
+	if err != nil {
+		return err
+	}
+
+	var done []string
+	if err := db.Model(&models.GoalStatus{}).Where("category = ?", "done").Pluck("name", &done).Error; err != nil {
+		return err
+	}
+	if len(done) == 0 {
+		// Projects without a configured workflow use the default one
+		done = []string{"done"}
+	}
+	return db.Exec(`UPDATE goals SET completed_at = COALESCE(
+		(SELECT MAX(changed_at) FROM goal_status_changes c WHERE c.goal_id = goals.id AND c.to_status = goals.status),
+		updated_at, CURRENT_TIMESTAMP) WHERE completed_at IS NULL AND status IN ?`, done).Error
+}
+
+// backfillADRNumbers derives the number of ADRs stored before it was a
+// column from the digits their ID ends in, so new ADRs are numbered after
+// them.
+func backfillADRNumbers(db *gorm.DB) error {
+	var adrs []models.ADR
+	if err := db.Select("id").Where("number = 0").Find(&adrs).Error; err != nil {
+		return err
+	}
+	for _, adr := range adrs {
+		digits := adrNumber.FindString(adr.ID)
+		if digits == "" {
+			continue
+		}
+		number, err := strconv.Atoi(digits)
+		if err != nil {
+			continue
+		}
+		if err := db.Model(&models.ADR{}).Where("id = ?", adr.ID).UpdateColumn("number", number).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *Server) Close() error {
 	sqlDB, err := s.db.DB()
 	if err != nil {
@@ -85,6 +191,35 @@ func (s *Server) GetDocsOutputPath() string {
 	return filepath.Join(s.repoRoot, "docs")
 }
 
+// GetCITimeout returns how long a CI run may take before it is stopped
+// Priority: 1. Environment variable MCP_CI_TIMEOUT (e.g. "15m"), 2. Default 10 minutes
+func (s *Server) GetCITimeout() time.Duration {
+	if env := os.Getenv("MCP_CI_TIMEOUT"); env != "" {
+		if timeout, err := time.ParseDuration(env); err == nil && timeout > 0 {
+			return timeout
+		}
+	}
+	return 10 * time.Minute
+}
+
+// GetCIRunner returns the test runner CI uses when a run does not name one
+// Priority: 1. Environment variable MCP_CI_RUNNER (go, npm, vitest, jest, pytest or cargo), 2. Empty, to choose by project type
+func (s *Server) GetCIRunner() string {
+	return strings.TrimSpace(os.Getenv("MCP_CI_RUNNER"))
+}
+
+// GetADRPath returns the directory ADR markdown files are written to
+// Priority: 1. Environment variable MCP_ADR_PATH, 2. Default "adr" under the docs output path
+func (s *Server) GetADRPath() string {
+	if envPath := os.Getenv("MCP_ADR_PATH"); envPath != "" {
+		if filepath.IsAbs(envPath) {
+			return envPath
+		}
+		return filepath.Join(s.repoRoot, envPath)
+	}
+	return filepath.Join(s.GetDocsOutputPath(), "adr")
+}
+
 func (s *Server) migrateChangelogToDB() {
 	changelogPath := filepath.Join(s.repoRoot, "CHANGELOG.md")
 	content, err := os.ReadFile(changelogPath)
@@ -4,6 +4,10 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/thornzero/project-manager/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 func TestNewServer(t *testing.T) {
@@ -137,3 +141,185 @@ func TestServer_Close(t *testing.T) {
 		t.Errorf("Close() error: %v", err)
 	}
 }
+
+func TestNewServer_DropsLegacyGoalStatusConstraint(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Create a database the way older versions did, with a fixed status list
+	agentDir := filepath.Join(tempDir, ".agent")
+	if err := os.MkdirAll(agentDir, 0755); err != nil {
+		t.Fatalf("MkdirAll() error: %v", err)
+	}
+	legacy, err := gorm.Open(sqlite.Open(filepath.Join(agentDir, "state.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error: %v", err)
+	}
+	err = legacy.Exec("CREATE TABLE `goals` (`id` integer PRIMARY KEY AUTOINCREMENT,`title` text NOT NULL,`priority` integer DEFAULT 100," +
+		"`status` text DEFAULT \"active\",`notes` text,`updated_at` datetime," +
+		"CONSTRAINT `chk_goals_status` CHECK (status IN ('active','paused','done')))").Error
+	if err != nil {
+		t.Fatalf("creating legacy goals table: %v", err)
+	}
+	if err := legacy.Exec("INSERT INTO goals (title, status) VALUES ('Old goal', 'paused')").Error; err != nil {
+		t.Fatalf("inserting legacy goal: %v", err)
+	}
+	sqlDB, _ := legacy.DB()
+	sqlDB.Close()
+
+	server, err := NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("NewServer() error: %v", err)
+	}
+	defer server.Close()
+
+	if server.GetDB().Migrator().HasConstraint(&models.Goal{}, "chk_goals_status") {
+		t.Errorf("NewServer() kept the legacy goal status constraint")
+	}
+
+	goal := models.Goal{Title: "New goal", Status: "in_progress"}
+	if err := server.GetDB().Create(&goal).Error; err != nil {
+		t.Errorf("creating goal with custom status: %v", err)
+	}
+
+	var count int64
+	server.GetDB().Model(&models.Goal{}).Where("title = ?", "Old goal").Count(&count)
+	if count != 1 {
+		t.Errorf("NewServer() lost existing goals during migration")
+	}
+}
+
+func TestNewServer_BackfillsGoalTimestamps(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Create a database from before creation and completion times were recorded
+	agentDir := filepath.Join(tempDir, ".agent")
+	if err := os.MkdirAll(agentDir, 0755); err != nil {
+		t.Fatalf("MkdirAll() error: %v", err)
+	}
+	legacy, err := gorm.Open(sqlite.Open(filepath.Join(agentDir, "state.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error: %v", err)
+	}
+	err = legacy.Exec("CREATE TABLE `goals` (`id` integer PRIMARY KEY AUTOINCREMENT,`title` text NOT NULL,`priority` integer DEFAULT 100," +
+		"`status` text DEFAULT \"active\",`notes` text,`updated_at` datetime)").Error
+	if err != nil {
+		t.Fatalf("creating legacy goals table: %v", err)
+	}
+	err = legacy.Exec("INSERT INTO goals (title, status, updated_at) VALUES " +
+		"('Open goal', 'active', '2025-01-02 10:00:00'), ('Finished goal', 'done', '2025-01-03 10:00:00')").Error
+	if err != nil {
+		t.Fatalf("inserting legacy goals: %v", err)
+	}
+	sqlDB, _ := legacy.DB()
+	sqlDB.Close()
+
+	server, err := NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("NewServer() error: %v", err)
+	}
+	defer server.Close()
+
+	var goals []models.Goal
+	if err := server.GetDB().Order("id ASC").Find(&goals).Error; err != nil {
+		t.Fatalf("loading goals: %v", err)
+	}
+	if len(goals) != 2 {
+		t.Fatalf("NewServer() left %d goals, want 2", len(goals))
+	}
+	for _, g := range goals {
+		if g.CreatedAt.IsZero() {
+			t.Errorf("goal %q has no creation time", g.Title)
+		}
+	}
+	if goals[0].CompletedAt != nil {
+		t.Errorf("open goal has completion time %v", goals[0].CompletedAt)
+	}
+	if goals[1].CompletedAt == nil || goals[1].CompletedAt.Format("2006-01-02") != "2025-01-03" {
+		t.Errorf("finished goal completion time = %v, want 2025-01-03", goals[1].CompletedAt)
+	}
+}
+
+func TestNewServer_BackfillsADRNumbers(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Create a database from before ADR numbers were stored
+	agentDir := filepath.Join(tempDir, ".agent")
+	if err := os.MkdirAll(agentDir, 0755); err != nil {
+		t.Fatalf("MkdirAll() error: %v", err)
+	}
+	legacy, err := gorm.Open(sqlite.Open(filepath.Join(agentDir, "state.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error: %v", err)
+	}
+	err = legacy.Exec("CREATE TABLE `adrs` (`id` text,`title` text NOT NULL,`content` text,`updated_at` datetime,PRIMARY KEY (`id`))").Error
+	if err != nil {
+		t.Fatalf("creating legacy adrs table: %v", err)
+	}
+	err = legacy.Exec("INSERT INTO adrs (id, title, updated_at) VALUES " +
+		"('ADR-007', 'Use SQLite', '2025-01-02 10:00:00'), ('caching', 'Cache responses', '2025-01-03 10:00:00')").Error
+	if err != nil {
+		t.Fatalf("inserting legacy adrs: %v", err)
+	}
+	sqlDB, _ := legacy.DB()
+	sqlDB.Close()
+
+	server, err := NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("NewServer() error: %v", err)
+	}
+	defer server.Close()
+
+	var adrs []models.ADR
+	if err := server.GetDB().Order("id ASC").Find(&adrs).Error; err != nil {
+		t.Fatalf("loading adrs: %v", err)
+	}
+	if len(adrs) != 2 || adrs[0].Number != 7 || adrs[1].Number != 0 {
+		t.Errorf("NewServer() adrs = %+v, want ADR-007 numbered 7 and caching unnumbered", adrs)
+	}
+	if adrs[0].Status != "proposed" {
+		t.Errorf("legacy ADR status = %q, want the default", adrs[0].Status)
+	}
+}
+
+func TestNewServer_MigratesCIRunStatus(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Create a database from before runs could time out
+	agentDir := filepath.Join(tempDir, ".agent")
+	if err := os.MkdirAll(agentDir, 0755); err != nil {
+		t.Fatalf("MkdirAll() error: %v", err)
+	}
+	legacy, err := gorm.Open(sqlite.Open(filepath.Join(agentDir, "state.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error: %v", err)
+	}
+	err = legacy.Exec("CREATE TABLE `ci_runs` (`id` integer PRIMARY KEY AUTOINCREMENT,`scope` text,`status` text NOT NULL," +
+		"`started_at` datetime DEFAULT CURRENT_TIMESTAMP,`finished_at` datetime," +
+		"CONSTRAINT `chk_ci_runs_status` CHECK (status IN ('pass','fail','error')))").Error
+	if err != nil {
+		t.Fatalf("creating legacy ci_runs table: %v", err)
+	}
+	if err := legacy.Exec("INSERT INTO ci_runs (scope, status) VALUES ('./...', 'fail')").Error; err != nil {
+		t.Fatalf("inserting legacy ci run: %v", err)
+	}
+	sqlDB, _ := legacy.DB()
+	sqlDB.Close()
+
+	server, err := NewServer(tempDir)
+	if err != nil {
+		t.Fatalf("NewServer() error: %v", err)
+	}
+	defer server.Close()
+
+	if err := server.GetDB().Create(&models.CIRun{Scope: "./...", Status: "timeout"}).Error; err != nil {
+		t.Errorf("creating a timed out run: %v", err)
+	}
+	if err := server.GetDB().Create(&models.CIRun{Scope: "./...", Status: "flaky"}).Error; err == nil {
+		t.Errorf("creating a run with an unknown status succeeded, want the constraint to reject it")
+	}
+	var count int64
+	server.GetDB().Model(&models.CIRun{}).Where("status = ?", "fail").Count(&count)
+	if count != 1 {
+		t.Errorf("legacy runs = %d, want the failed run kept", count)
+	}
+}
@@ -22,8 +22,9 @@ func NewSetupHandler(s *server.Server) *SetupHandler {
 	return &SetupHandler{server: s}
 }
 
-// detectProjectType analyzes the project to determine what type of rules to create
-func (h *SetupHandler) detectProjectType(projectPath string) string {
+// DetectProjectType analyzes the project to determine what type of rules to
+// create and which test runner CI uses: go, nodejs, python, rust or generic
+func DetectProjectType(projectPath string) string {
 	// Check for Go project
 	if _, err := os.Stat(filepath.Join(projectPath, "go.mod")); err == nil {
 		return "go"
@@ -98,7 +99,7 @@ func (h *SetupHandler) SetupProjectManager(ctx context.Context, req *mcp.CallToo
 	}
 
 	// Detect project type
-	projectType := h.detectProjectType(projectPath)
+	projectType := DetectProjectType(projectPath)
 
 	// Determine what rules to create
 	shouldCreateGeneric := h.shouldCreateGenericRules(projectPath)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -13,6 +14,7 @@ import (
 	"github.com/thornzero/project-manager/internal/models"
 	"github.com/thornzero/project-manager/internal/server"
 	"github.com/thornzero/project-manager/internal/types"
+	"gorm.io/gorm"
 )
 
 type StateHandler struct {
@@ -23,6 +25,8 @@ func NewStateHandler(s *server.Server) *StateHandler {
 	return &StateHandler{server: s}
 }
 
+// StateLogChange records a changelog entry. With a goal_id the entry is also
+// linked to that goal, so it shows up in goals_get.
 func (h *StateHandler) StateLogChange(ctx context.Context, req *mcp.CallToolRequest, input types.StateLogChangeInput) (*mcp.CallToolResult, types.StateLogChangeOutput, error) {
 	if strings.TrimSpace(input.Summary) == "" {
 		return nil, types.StateLogChangeOutput{}, fmt.Errorf("summary required")
@@ -34,12 +38,34 @@ func (h *StateHandler) StateLogChange(ctx context.Context, req *mcp.CallToolRequ
 		Files:   strings.Join(input.Files, ", "),
 	}
 
-	err := h.server.GetDB().Create(&entry).Error
+	err := h.server.GetDB().Transaction(func(tx *gorm.DB) error {
+		if input.GoalID != 0 {
+			var count int64
+			if err := tx.Model(&models.Goal{}).Where("id = ?", input.GoalID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return fmt.Errorf("goal %d not found", input.GoalID)
+			}
+		}
+
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+
+		if input.GoalID != 0 {
+			link := models.GoalLink{GoalID: uint(input.GoalID), Kind: "changelog", Target: strconv.Itoa(int(entry.ID))}
+			if err := tx.Create(&link).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, types.StateLogChangeOutput{}, err
 	}
 
-	return nil, types.StateLogChangeOutput{OK: true}, nil
+	return nil, types.StateLogChangeOutput{OK: true, ID: int(entry.ID)}, nil
 }
 
 func (h *StateHandler) ChangelogGenerate(ctx context.Context, req *mcp.CallToolRequest, input types.ChangelogGenerateInput) (*mcp.CallToolResult, types.ChangelogGenerateOutput, error) {
@@ -2,26 +2,61 @@ package types
 
 // Goal management inputs and outputs
 type GoalsListInput struct {
-	Limit int `json:"limit,omitempty" jsonschema:"Maximum number of goals to return (0 = no limit)"`
+	Limit     int      `json:"limit,omitempty" jsonschema:"Maximum number of goals to return (defaults to 10)"`
+	Filter    string   `json:"filter,omitempty" jsonschema:"Filter expression, e.g. 'status:todo,in_progress tag:api owner:sam milestone:v1 overdue due<2025-07-01 sort:-due login bug'; bare words are a text query"`
+	Statuses  []string `json:"statuses,omitempty" jsonschema:"Only goals in these statuses (defaults to all open statuses)"`
+	Completed bool     `json:"completed,omitempty" jsonschema:"Only goals in a done status, most recently updated first (for retros)"`
+	Tag       string   `json:"tag,omitempty" jsonschema:"Only goals with this tag"`
+	Owner     string   `json:"owner,omitempty" jsonschema:"Only goals with this owner"`
+	Milestone string   `json:"milestone,omitempty" jsonschema:"Only goals in this milestone"`
+	Overdue   bool     `json:"overdue,omitempty" jsonschema:"Only open goals due before today"`
+	DueBefore string   `json:"due_before,omitempty" jsonschema:"Only goals due before this date (YYYY-MM-DD)"`
+	Query     string   `json:"query,omitempty" jsonschema:"Text to search for in goal titles and notes"`
+	Sort      string   `json:"sort,omitempty" jsonschema:"Sort field: priority (default), due, updated, created or title; prefix with - to reverse"`
+	Cursor    string   `json:"cursor,omitempty" jsonschema:"Cursor from a previous response to fetch the next page"`
 }
 
 type GoalsListOutput struct {
-	Goals []Goal `json:"goals" jsonschema:"List of active goals"`
+	Goals      []Goal `json:"goals" jsonschema:"List of goals matching the filter"`
+	NextCursor string `json:"next_cursor,omitempty" jsonschema:"Cursor for the next page (empty on the last page)"`
 }
 
 type Goal struct {
-	ID        int    `json:"id" jsonschema:"Unique goal identifier"`
-	Title     string `json:"title" jsonschema:"Goal title or description"`
-	Priority  int    `json:"priority" jsonschema:"Goal priority (lower number = higher priority)"`
-	Status    string `json:"status" jsonschema:"Current goal status (active, paused, done)"`
-	Notes     string `json:"notes" jsonschema:"Additional notes or details about the goal"`
-	UpdatedAt string `json:"updated_at" jsonschema:"Last update timestamp"`
+	ID           int        `json:"id" jsonschema:"Unique goal identifier"`
+	Title        string     `json:"title" jsonschema:"Goal title or description"`
+	Priority     int        `json:"priority" jsonschema:"Goal priority (lower number = higher priority)"`
+	Status       string     `json:"status" jsonschema:"Current goal status (see goals_workflow_get)"`
+	Notes        string     `json:"notes" jsonschema:"Additional notes or details about the goal"`
+	DueDate      string     `json:"due_date,omitempty" jsonschema:"Due date (YYYY-MM-DD)"`
+	Tags         []string   `json:"tags,omitempty" jsonschema:"Goal tags"`
+	Owner        string     `json:"owner,omitempty" jsonschema:"Person or agent responsible for the goal"`
+	Milestone    string     `json:"milestone,omitempty" jsonschema:"Milestone or release the goal belongs to"`
+	Criteria     []GoalItem `json:"criteria,omitempty" jsonschema:"Acceptance criteria checklist"`
+	AutoComplete bool       `json:"auto_complete,omitempty" jsonschema:"Whether the goal moves to done once all criteria are checked"`
+	Source       string     `json:"source,omitempty" jsonschema:"Where the goal was imported from (e.g. todo)"`
+	SourceRef    string     `json:"source_ref,omitempty" jsonschema:"Location of the goal in its source (e.g. file:line)"`
+	CreatedAt    string     `json:"created_at" jsonschema:"Creation timestamp"`
+	CompletedAt  string     `json:"completed_at,omitempty" jsonschema:"When the goal reached a done status"`
+	UpdatedAt    string     `json:"updated_at" jsonschema:"Last update timestamp"`
+}
+
+type GoalItem struct {
+	ID        int    `json:"id" jsonschema:"Acceptance criterion identifier"`
+	Text      string `json:"text" jsonschema:"What has to be true for the goal to be finished"`
+	Checked   bool   `json:"checked" jsonschema:"Whether the criterion has been met"`
+	CheckedAt string `json:"checked_at,omitempty" jsonschema:"When the criterion was checked off"`
 }
 
 type GoalsAddInput struct {
-	Title    string  `json:"title" jsonschema:"Goal title or description (required)"`
-	Priority *int    `json:"priority,omitempty" jsonschema:"Goal priority (lower number = higher priority, defaults to 0)"`
-	Notes    *string `json:"notes,omitempty" jsonschema:"Additional notes or context for the goal"`
+	Title        string   `json:"title" jsonschema:"Goal title or description (required)"`
+	Priority     *int     `json:"priority,omitempty" jsonschema:"Goal priority (lower number = higher priority, defaults to 0)"`
+	Notes        *string  `json:"notes,omitempty" jsonschema:"Additional notes or context for the goal"`
+	DueDate      *string  `json:"due_date,omitempty" jsonschema:"Due date (YYYY-MM-DD or RFC 3339)"`
+	Tags         []string `json:"tags,omitempty" jsonschema:"Tags for the goal"`
+	Owner        *string  `json:"owner,omitempty" jsonschema:"Person or agent responsible for the goal"`
+	Milestone    *string  `json:"milestone,omitempty" jsonschema:"Milestone or release the goal belongs to"`
+	Criteria     []string `json:"criteria,omitempty" jsonschema:"Acceptance criteria that must be checked off before the goal is finished"`
+	AutoComplete *bool    `json:"auto_complete,omitempty" jsonschema:"Move the goal to done automatically when all criteria are checked (defaults to false)"`
 }
 
 type GoalsAddOutput struct {
@@ -29,19 +64,344 @@ type GoalsAddOutput struct {
 }
 
 type GoalsUpdateInput struct {
-	ID       int     `json:"id" jsonschema:"Goal ID to update (required)"`
-	Status   *string `json:"status,omitempty" jsonschema:"New status (active, paused, done)"`
-	Notes    *string `json:"notes,omitempty" jsonschema:"Updated notes or context"`
-	Priority *int    `json:"priority,omitempty" jsonschema:"Updated priority (lower number = higher priority)"`
+	ID           int      `json:"id" jsonschema:"Goal ID to update (required)"`
+	Title        *string  `json:"title,omitempty" jsonschema:"Updated title"`
+	Status       *string  `json:"status,omitempty" jsonschema:"New status; must be an allowed transition in the goal workflow"`
+	Notes        *string  `json:"notes,omitempty" jsonschema:"Updated notes or context"`
+	Priority     *int     `json:"priority,omitempty" jsonschema:"Updated priority (lower number = higher priority)"`
+	DueDate      *string  `json:"due_date,omitempty" jsonschema:"Updated due date (YYYY-MM-DD or RFC 3339, empty string clears it)"`
+	Tags         []string `json:"tags,omitempty" jsonschema:"Replacement tags (an empty list clears them)"`
+	Owner        *string  `json:"owner,omitempty" jsonschema:"Updated owner (empty string clears it)"`
+	Milestone    *string  `json:"milestone,omitempty" jsonschema:"Updated milestone (empty string clears it)"`
+	AutoComplete *bool    `json:"auto_complete,omitempty" jsonschema:"Move the goal to done automatically when all criteria are checked"`
 }
 
 type GoalsUpdateOutput struct {
 	Updated int `json:"updated" jsonschema:"Number of rows updated"`
 }
 
+// Goal acceptance criteria inputs and outputs
+type GoalsAddItemsInput struct {
+	GoalID int      `json:"goal_id" jsonschema:"Goal ID to add acceptance criteria to (required)"`
+	Items  []string `json:"items" jsonschema:"Acceptance criteria to append (required)"`
+}
+
+type GoalsAddItemsOutput struct {
+	Items []GoalItem `json:"items" jsonschema:"All acceptance criteria of the goal"`
+}
+
+type GoalsCheckItemInput struct {
+	GoalID  int   `json:"goal_id" jsonschema:"Goal ID the criterion belongs to (required)"`
+	ItemID  int   `json:"item_id" jsonschema:"Acceptance criterion ID to check off (required)"`
+	Checked *bool `json:"checked,omitempty" jsonschema:"Set to false to uncheck the item (defaults to true)"`
+}
+
+type GoalsCheckItemOutput struct {
+	Items      []GoalItem `json:"items" jsonschema:"All acceptance criteria of the goal"`
+	AllChecked bool       `json:"all_checked" jsonschema:"Whether every criterion is now checked"`
+	Status     string     `json:"status" jsonschema:"Goal status after the update"`
+	Message    string     `json:"message,omitempty" jsonschema:"What happened to the goal status, if anything"`
+}
+
+// Goal dependency inputs and outputs
+type GoalsDependInput struct {
+	ID        int `json:"id" jsonschema:"Goal ID that is blocked (required)"`
+	DependsOn int `json:"depends_on" jsonschema:"Goal ID that must be done first (required)"`
+}
+
+type GoalsDependOutput struct {
+	Added bool `json:"added" jsonschema:"Whether the dependency was added (false if it already existed)"`
+}
+
+type GoalsUndependInput struct {
+	ID        int `json:"id" jsonschema:"Goal ID that is blocked (required)"`
+	DependsOn int `json:"depends_on" jsonschema:"Goal ID to remove from its dependencies (required)"`
+}
+
+type GoalsUndependOutput struct {
+	Removed int `json:"removed" jsonschema:"Number of dependencies removed"`
+}
+
+type GoalsNextInput struct {
+	Limit int `json:"limit,omitempty" jsonschema:"Maximum number of goals to return (defaults to 5)"`
+}
+
+type GoalsNextOutput struct {
+	Goals []Goal `json:"goals" jsonschema:"Highest-priority active goals whose dependencies are all done"`
+}
+
+type GoalsBlockedInput struct {
+	Limit int `json:"limit,omitempty" jsonschema:"Maximum number of goals to return (0 = no limit)"`
+}
+
+type GoalsBlockedOutput struct {
+	Blocked []BlockedGoal `json:"blocked" jsonschema:"Goals that are waiting on unfinished dependencies"`
+}
+
+type BlockedGoal struct {
+	Goal      Goal          `json:"goal" jsonschema:"The blocked goal"`
+	WaitingOn []GoalSummary `json:"waiting_on" jsonschema:"Dependencies that are not done yet"`
+}
+
+type GoalSummary struct {
+	ID     int    `json:"id" jsonschema:"Goal identifier"`
+	Title  string `json:"title" jsonschema:"Goal title"`
+	Status string `json:"status" jsonschema:"Current goal status"`
+}
+
+// Goal workflow inputs and outputs
+type GoalWorkflowStatus struct {
+	Name     string `json:"name" jsonschema:"Status name (e.g., in_progress)"`
+	Category string `json:"category" jsonschema:"Status category: todo, active, waiting, done or cancelled"`
+	Initial  bool   `json:"initial,omitempty" jsonschema:"Whether new goals start in this status"`
+}
+
+type GoalWorkflowTransition struct {
+	From string `json:"from" jsonschema:"Status the goal is leaving"`
+	To   string `json:"to" jsonschema:"Status the goal is entering"`
+}
+
+type GoalsWorkflowGetInput struct{}
+
+type GoalsWorkflowGetOutput struct {
+	Statuses    []GoalWorkflowStatus     `json:"statuses" jsonschema:"Statuses in workflow order"`
+	Transitions []GoalWorkflowTransition `json:"transitions" jsonschema:"Allowed status transitions"`
+}
+
+type GoalsWorkflowSetInput struct {
+	Preset      *string                  `json:"preset,omitempty" jsonschema:"Use a built-in workflow instead of explicit statuses: simple (active, paused, done) or kanban (todo, in_progress, blocked, in_review, done, cancelled)"`
+	Statuses    []GoalWorkflowStatus     `json:"statuses,omitempty" jsonschema:"Statuses in workflow order (required unless preset is given)"`
+	Transitions []GoalWorkflowTransition `json:"transitions,omitempty" jsonschema:"Allowed status transitions (required unless preset is given)"`
+	Remap       map[string]string        `json:"remap,omitempty" jsonschema:"Map of old status to new status for existing goals whose status is not in the new workflow"`
+}
+
+type GoalsWorkflowSetOutput struct {
+	Statuses    []GoalWorkflowStatus     `json:"statuses" jsonschema:"Statuses in workflow order"`
+	Transitions []GoalWorkflowTransition `json:"transitions" jsonschema:"Allowed status transitions"`
+	Remapped    int                      `json:"remapped" jsonschema:"Number of existing goals moved to a new status"`
+}
+
+type GoalsHistoryInput struct {
+	ID int `json:"id" jsonschema:"Goal ID to show the status history of (required)"`
+}
+
+type GoalsHistoryOutput struct {
+	Changes []GoalStatusChange `json:"changes" jsonschema:"Status changes, oldest first"`
+}
+
+type GoalStatusChange struct {
+	From      string `json:"from" jsonschema:"Previous status (empty when the goal was created)"`
+	To        string `json:"to" jsonschema:"New status"`
+	Reason    string `json:"reason,omitempty" jsonschema:"Why an importer made the change, e.g. comment_removed; empty when it was asked for"`
+	ChangedAt string `json:"changed_at" jsonschema:"When the change happened"`
+}
+
+type GoalsImportTodosInput struct {
+	Path *string `json:"path,omitempty" jsonschema:"Directory to scan, relative to the repository root (defaults to the whole repository)"`
+}
+
+type GoalsImportTodosOutput struct {
+	Created   int            `json:"created" jsonschema:"Number of goals created for new comments"`
+	Updated   int            `json:"updated" jsonschema:"Number of goals whose comment moved or changed author"`
+	Completed int            `json:"completed" jsonschema:"Number of goals moved to done because their comment disappeared"`
+	Reopened  int            `json:"reopened" jsonschema:"Number of goals completed here whose comment came back, moved to the initial status"`
+	Unchanged int            `json:"unchanged" jsonschema:"Number of comments that already had an up-to-date goal"`
+	Changes   []ImportedTodo `json:"changes" jsonschema:"Comments whose goal was created, updated, completed or reopened"`
+	Message   string         `json:"message,omitempty" jsonschema:"Notes about goals that could not be completed or reopened"`
+}
+
+type ImportedTodo struct {
+	Marker string `json:"marker" jsonschema:"Comment marker (TODO or FIXME)"`
+	Author string `json:"author,omitempty" jsonschema:"Name given in parentheses after the marker"`
+	Text   string `json:"text" jsonschema:"Comment text"`
+	File   string `json:"file" jsonschema:"File path relative to the repository root"`
+	Line   int    `json:"line" jsonschema:"Line number of the comment"`
+	GoalID int    `json:"goal_id" jsonschema:"Goal the comment is tracked by"`
+	Action string `json:"action" jsonschema:"What happened to the goal: created, updated, completed or reopened"`
+}
+
+type GoalsExportInput struct {
+	Formats   []string `json:"formats,omitempty" jsonschema:"What to write: roadmap (ROADMAP.md), board (BOARD.md), html (board.html), todotxt (todo.txt), taskwarrior (tasks.json) and/or github (issues.json); defaults to roadmap, board and html"`
+	OutputDir *string  `json:"output_dir,omitempty" jsonschema:"Directory to write to, relative to the repository root (defaults to the docs output path)"`
+}
+
+type GoalsImportInput struct {
+	Format string `json:"format" jsonschema:"Format of the file: todotxt, taskwarrior (task export JSON) or github (gh issue list --json output) (required)"`
+	Path   string `json:"path" jsonschema:"File to import, inside the repository and relative to its root (required)"`
+	DryRun bool   `json:"dry_run,omitempty" jsonschema:"Report what would be created or updated without changing anything"`
+}
+
+type GoalsImportOutput struct {
+	Created   int            `json:"created" jsonschema:"Number of goals created"`
+	Updated   int            `json:"updated" jsonschema:"Number of existing goals updated"`
+	Unchanged int            `json:"unchanged" jsonschema:"Number of tasks whose goal was already up to date"`
+	Goals     []ImportedGoal `json:"goals" jsonschema:"What happened to each task in the file"`
+	DryRun    bool           `json:"dry_run" jsonschema:"Whether this was a dry run"`
+}
+
+type ImportedGoal struct {
+	ExternalID string `json:"external_id" jsonschema:"ID of the task in the source format"`
+	Title      string `json:"title" jsonschema:"Goal title"`
+	Status     string `json:"status" jsonschema:"Status the goal was given"`
+	GoalID     int    `json:"goal_id,omitempty" jsonschema:"Goal ID (empty for goals a dry run would create)"`
+	Action     string `json:"action" jsonschema:"created, updated or unchanged"`
+}
+
+type GoalsExportOutput struct {
+	Files []ExportedFile `json:"files" jsonschema:"Files that were written"`
+	Goals int            `json:"goals" jsonschema:"Number of goals exported"`
+}
+
+type ExportedFile struct {
+	Format  string `json:"format" jsonschema:"Export format"`
+	Path    string `json:"path" jsonschema:"Path of the written file"`
+	Changed bool   `json:"changed" jsonschema:"Whether the file content differs from what was there before"`
+}
+
+type GoalsStartInput struct {
+	GoalID int     `json:"goal_id" jsonschema:"Goal ID to start working on (required)"`
+	Note   *string `json:"note,omitempty" jsonschema:"What the session is about"`
+}
+
+type GoalsStartOutput struct {
+	Session GoalSession  `json:"session" jsonschema:"The open session"`
+	Paused  *GoalSession `json:"paused,omitempty" jsonschema:"Session on another goal that was closed to start this one"`
+}
+
+type GoalsStopInput struct {
+	GoalID *int `json:"goal_id,omitempty" jsonschema:"Goal ID to stop working on (defaults to whichever goal has an open session)"`
+}
+
+type GoalsStopOutput struct {
+	Session GoalSession `json:"session" jsonschema:"The closed session"`
+}
+
+type GoalSession struct {
+	ID        int    `json:"id" jsonschema:"Session identifier"`
+	GoalID    int    `json:"goal_id" jsonschema:"Goal the session belongs to"`
+	Title     string `json:"title" jsonschema:"Goal title"`
+	StartedAt string `json:"started_at" jsonschema:"When the session started"`
+	EndedAt   string `json:"ended_at,omitempty" jsonschema:"When the session ended (empty while open)"`
+	Seconds   int64  `json:"seconds" jsonschema:"Session length in seconds (up to now for open sessions)"`
+	Note      string `json:"note,omitempty" jsonschema:"What the session was about"`
+}
+
+type GoalsTimeReportInput struct {
+	Since      string `json:"since,omitempty" jsonschema:"Only sessions started on or after this date (YYYY-MM-DD)"`
+	Until      string `json:"until,omitempty" jsonschema:"Only sessions started before this date (YYYY-MM-DD)"`
+	Tag        string `json:"tag,omitempty" jsonschema:"Only sessions on goals with this tag"`
+	StaleHours int    `json:"stale_hours,omitempty" jsonschema:"Flag sessions open for longer than this many hours as left open (defaults to 8)"`
+}
+
+type GoalsTimeReportOutput struct {
+	TotalSeconds int64           `json:"total_seconds" jsonschema:"Time across all matching sessions"`
+	Total        string          `json:"total" jsonschema:"Total time, human readable"`
+	ByGoal       []GoalTimeEntry `json:"by_goal" jsonschema:"Time per goal, most time first"`
+	ByTag        []TimeEntry     `json:"by_tag" jsonschema:"Time per tag, most time first; goals with several tags count towards each"`
+	ByWeek       []TimeEntry     `json:"by_week" jsonschema:"Time per ISO week of the session start, oldest first"`
+	LeftOpen     []GoalSession   `json:"left_open" jsonschema:"Sessions that have been open for longer than stale_hours"`
+}
+
+type GoalTimeEntry struct {
+	GoalID   int    `json:"goal_id" jsonschema:"Goal ID"`
+	Title    string `json:"title" jsonschema:"Goal title"`
+	Seconds  int64  `json:"seconds" jsonschema:"Time spent in seconds"`
+	Duration string `json:"duration" jsonschema:"Time spent, human readable"`
+	Sessions int    `json:"sessions" jsonschema:"Number of sessions"`
+	LeftOpen bool   `json:"left_open,omitempty" jsonschema:"Whether the goal has a session that was left open"`
+}
+
+type TimeEntry struct {
+	Key      string `json:"key" jsonschema:"Tag or ISO week (e.g. 2025-W27)"`
+	Seconds  int64  `json:"seconds" jsonschema:"Time spent in seconds"`
+	Duration string `json:"duration" jsonschema:"Time spent, human readable"`
+}
+
+type GoalsStatsInput struct {
+	Weeks     int    `json:"weeks,omitempty" jsonschema:"Number of weeks to report on, ending with the current week (defaults to 12)"`
+	Milestone string `json:"milestone,omitempty" jsonschema:"Milestone to compute a daily burndown for"`
+}
+
+type GoalsStatsOutput struct {
+	Weeks            []GoalsWeekStats `json:"weeks" jsonschema:"Per-week throughput and open/closed counts, oldest first"`
+	Completed        int              `json:"completed" jsonschema:"Goals completed in the reported weeks"`
+	AvgCycleTimeDays float64          `json:"avg_cycle_time_days" jsonschema:"Average days from starting work (first active status) to done, for goals completed in the reported weeks"`
+	AvgLeadTimeDays  float64          `json:"avg_lead_time_days" jsonschema:"Average days from creation to done, for goals completed in the reported weeks"`
+	Burndown         *GoalsBurndown   `json:"burndown,omitempty" jsonschema:"Daily burndown of the requested milestone"`
+	Markdown         string           `json:"markdown" jsonschema:"The same statistics as a markdown report with sparkline charts"`
+}
+
+type GoalsWeekStats struct {
+	Week      string `json:"week" jsonschema:"ISO week (e.g. 2025-W27)"`
+	Created   int    `json:"created" jsonschema:"Goals created during the week"`
+	Completed int    `json:"completed" jsonschema:"Goals completed during the week (throughput)"`
+	Open      int    `json:"open" jsonschema:"Goals open at the end of the week"`
+	Closed    int    `json:"closed" jsonschema:"Goals done or cancelled by the end of the week"`
+}
+
+type GoalsBurndown struct {
+	Milestone string             `json:"milestone" jsonschema:"Milestone name"`
+	Total     int                `json:"total" jsonschema:"Goals in the milestone"`
+	Remaining int                `json:"remaining" jsonschema:"Goals in the milestone that are still open"`
+	Days      []GoalsBurndownDay `json:"days" jsonschema:"Remaining goals at the end of each day, from the milestone's first goal until today"`
+}
+
+type GoalsBurndownDay struct {
+	Date      string `json:"date" jsonschema:"Date (YYYY-MM-DD)"`
+	Remaining int    `json:"remaining" jsonschema:"Open goals at the end of the day"`
+}
+
+// Goal link inputs and outputs
+type LinksAddInput struct {
+	GoalID int    `json:"goal_id" jsonschema:"Goal ID to link from (required)"`
+	Kind   string `json:"kind" jsonschema:"What is linked: adr, changelog, file or commit (required)"`
+	Target string `json:"target" jsonschema:"ADR ID, changelog entry ID, repo-relative file path or commit SHA (required)"`
+}
+
+type LinksAddOutput struct {
+	Added bool     `json:"added" jsonschema:"Whether the link was added (false if it already existed)"`
+	Link  GoalLink `json:"link" jsonschema:"The link, with the target normalized (e.g. full commit SHA)"`
+}
+
+type GoalLink struct {
+	ID        int    `json:"id" jsonschema:"Link identifier"`
+	GoalID    int    `json:"goal_id" jsonschema:"Linked goal"`
+	Kind      string `json:"kind" jsonschema:"What is linked: adr, changelog, file or commit"`
+	Target    string `json:"target" jsonschema:"ADR ID, changelog entry ID, file path or commit SHA"`
+	CreatedAt string `json:"created_at" jsonschema:"When the link was added"`
+}
+
+type GoalsGetInput struct {
+	ID int `json:"id" jsonschema:"Goal ID to retrieve (required)"`
+}
+
+type GoalsGetOutput struct {
+	Goal      Goal           `json:"goal" jsonschema:"The goal with its acceptance criteria"`
+	DependsOn []GoalSummary  `json:"depends_on,omitempty" jsonschema:"Goals this goal is blocked by"`
+	ADRs      []LinkedADR    `json:"adrs" jsonschema:"Linked architecture decision records"`
+	Changes   []LinkedChange `json:"changes" jsonschema:"Linked changelog entries, oldest first"`
+	Files     []string       `json:"files" jsonschema:"Linked files and the files of linked changelog entries"`
+	Commits   []string       `json:"commits" jsonschema:"Linked git commit SHAs"`
+}
+
+type LinkedADR struct {
+	ID    string `json:"id" jsonschema:"ADR identifier"`
+	Title string `json:"title" jsonschema:"ADR title, empty if the ADR no longer exists"`
+}
+
+type LinkedChange struct {
+	ID        int      `json:"id" jsonschema:"Changelog entry identifier"`
+	Summary   string   `json:"summary" jsonschema:"Summary of the change"`
+	Files     []string `json:"files,omitempty" jsonschema:"Files the change touched"`
+	CreatedAt string   `json:"created_at" jsonschema:"When the change was logged"`
+}
+
 // ADR management inputs and outputs
 type ADRsListInput struct {
-	Query *string `json:"query,omitempty" jsonschema:"Search query to filter ADRs by title or content"`
+	Query     *string  `json:"query,omitempty" jsonschema:"Search query to filter ADRs by title or content"`
+	Statuses  []string `json:"statuses,omitempty" jsonschema:"Only ADRs in these statuses (proposed, accepted, rejected, deprecated, superseded)"`
+	Relation  string   `json:"relation,omitempty" jsonschema:"Only ADRs with a relation of this kind: supersedes, superseded-by, amends, amended-by or relates-to"`
+	RelatedTo string   `json:"related_to,omitempty" jsonschema:"Only ADRs related to this ADR ID (combine with relation to narrow the kind)"`
 }
 
 type ADRsListOutput struct {
@@ -49,10 +409,21 @@ type ADRsListOutput struct {
 }
 
 type ADR struct {
-	ID        string `json:"id" jsonschema:"ADR identifier (e.g., ADR-001)"`
-	Title     string `json:"title" jsonschema:"ADR title or subject"`
-	Content   string `json:"content" jsonschema:"Full content of the ADR document"`
-	UpdatedAt string `json:"updated_at" jsonschema:"Last modification timestamp"`
+	ID        string    `json:"id" jsonschema:"ADR identifier (e.g., ADR-001)"`
+	Title     string    `json:"title" jsonschema:"ADR title or subject"`
+	Status    string    `json:"status" jsonschema:"Decision status: proposed, accepted, rejected, deprecated or superseded"`
+	Date      string    `json:"date,omitempty" jsonschema:"When the decision was made (YYYY-MM-DD)"`
+	Deciders  []string  `json:"deciders,omitempty" jsonschema:"People involved in the decision"`
+	Scope     []string  `json:"scope,omitempty" jsonschema:"Path globs or packages the decision governs, relative to the repository root"`
+	Path      string    `json:"path,omitempty" jsonschema:"Markdown file of the ADR, relative to the repository root"`
+	Relations []ADRLink `json:"relations,omitempty" jsonschema:"How this ADR relates to others"`
+	Content   string    `json:"content" jsonschema:"Full content of the ADR document"`
+	UpdatedAt string    `json:"updated_at" jsonschema:"Last modification timestamp"`
+}
+
+type ADRLink struct {
+	Kind string `json:"kind" jsonschema:"Relation as seen from this ADR: supersedes, superseded-by, amends, amended-by or relates-to"`
+	ID   string `json:"id" jsonschema:"The other ADR"`
 }
 
 type ADRsGetInput struct {
@@ -60,29 +431,487 @@ type ADRsGetInput struct {
 }
 
 type ADRsGetOutput struct {
-	ID      string `json:"id" jsonschema:"ADR identifier"`
-	Title   string `json:"title" jsonschema:"ADR title"`
-	Content string `json:"content" jsonschema:"Full content of the ADR document"`
+	ID        string    `json:"id" jsonschema:"ADR identifier"`
+	Title     string    `json:"title" jsonschema:"ADR title"`
+	Status    string    `json:"status" jsonschema:"Decision status"`
+	Date      string    `json:"date,omitempty" jsonschema:"When the decision was made (YYYY-MM-DD)"`
+	Deciders  []string  `json:"deciders,omitempty" jsonschema:"People involved in the decision"`
+	Scope     []string  `json:"scope,omitempty" jsonschema:"Path globs or packages the decision governs, relative to the repository root"`
+	Path      string    `json:"path,omitempty" jsonschema:"Markdown file of the ADR, relative to the repository root"`
+	Relations []ADRLink `json:"relations,omitempty" jsonschema:"How this ADR relates to others"`
+	Content   string    `json:"content" jsonschema:"Full content of the ADR document"`
+}
+
+type ADRsCreateInput struct {
+	Title        string   `json:"title" jsonschema:"Short title of the decision (required)"`
+	Context      string   `json:"context,omitempty" jsonschema:"The forces at play and why a decision is needed"`
+	Decision     string   `json:"decision,omitempty" jsonschema:"What was decided"`
+	Consequences string   `json:"consequences,omitempty" jsonschema:"What becomes easier or harder because of the decision"`
+	Status       *string  `json:"status,omitempty" jsonschema:"proposed (default), accepted, rejected or deprecated"`
+	Date         *string  `json:"date,omitempty" jsonschema:"When the decision was made (YYYY-MM-DD, defaults to today)"`
+	Scope        []string `json:"scope,omitempty" jsonschema:"Path globs or packages the decision governs, e.g. internal/server or internal/**/*_test.go"`
+}
+
+type ADRsCreateOutput struct {
+	ID   string `json:"id" jsonschema:"ID of the created ADR (e.g., ADR-004)"`
+	Path string `json:"path" jsonschema:"Markdown file written for the ADR"`
+}
+
+type ADRsUpdateInput struct {
+	ID           string   `json:"id" jsonschema:"ADR ID to update (required)"`
+	Title        *string  `json:"title,omitempty" jsonschema:"Updated title; the markdown file is renamed to match"`
+	Context      *string  `json:"context,omitempty" jsonschema:"Updated context"`
+	Decision     *string  `json:"decision,omitempty" jsonschema:"Updated decision"`
+	Consequences *string  `json:"consequences,omitempty" jsonschema:"Updated consequences"`
+	Status       *string  `json:"status,omitempty" jsonschema:"Updated status: proposed, accepted, rejected or deprecated (use adrs_supersede to supersede)"`
+	Date         *string  `json:"date,omitempty" jsonschema:"Updated decision date (YYYY-MM-DD)"`
+	Scope        []string `json:"scope,omitempty" jsonschema:"Replacement scope globs or packages; an empty list clears the scope"`
+}
+
+type ADRsUpdateOutput struct {
+	ID   string `json:"id" jsonschema:"ID of the updated ADR"`
+	Path string `json:"path" jsonschema:"Markdown file of the ADR"`
+}
+
+type ADRsSupersedeInput struct {
+	ID string `json:"id" jsonschema:"ADR ID that is being replaced (required)"`
+	By string `json:"by" jsonschema:"ADR ID of the decision that replaces it (required)"`
+}
+
+type ADRsSupersedeOutput struct {
+	Paths []string `json:"paths" jsonschema:"Markdown files rewritten for both ADRs"`
+}
+
+type ADRsRelateInput struct {
+	From string `json:"from" jsonschema:"ADR ID the relation is stated by (required)"`
+	Kind string `json:"kind" jsonschema:"supersedes, amends or relates-to (required)"`
+	To   string `json:"to" jsonschema:"ADR ID the relation points to (required)"`
+}
+
+type ADRsRelateOutput struct {
+	Added bool     `json:"added" jsonschema:"Whether the relation was added (false if it already existed)"`
+	Paths []string `json:"paths" jsonschema:"Markdown files rewritten for both ADRs"`
+}
+
+type ADRsGraphInput struct {
+	Format   string   `json:"format,omitempty" jsonschema:"mermaid (default) or dot"`
+	Statuses []string `json:"statuses,omitempty" jsonschema:"Only include ADRs in these statuses"`
+}
+
+type ADRsGraphOutput struct {
+	Format string `json:"format" jsonschema:"Format of the graph"`
+	Graph  string `json:"graph" jsonschema:"The decision graph"`
+	Nodes  int    `json:"nodes" jsonschema:"Number of ADRs in the graph"`
+	Edges  int    `json:"edges" jsonschema:"Number of relations in the graph"`
+}
+
+type ADRsForPathInput struct {
+	Path     string `json:"path" jsonschema:"File or package to look up, relative to the repository root or as a Go import path (required)"`
+	Inactive bool   `json:"inactive,omitempty" jsonschema:"Also return rejected, deprecated and superseded ADRs"`
+}
+
+type ADRsForPathOutput struct {
+	Path string `json:"path" jsonschema:"The path as matched, relative to the repository root"`
+	ADRs []ADR  `json:"adrs" jsonschema:"ADRs whose scope covers the path"`
+}
+
+type ADRsIndexInput struct{}
+
+type ADRsIndexOutput struct {
+	Path string `json:"path" jsonschema:"README.md that was written"`
+	ADRs int    `json:"adrs" jsonschema:"Number of ADRs in the index"`
+}
+
+type ADRsLintInput struct{}
+
+type ADRsLintOutput struct {
+	Dir     string      `json:"dir" jsonschema:"ADR directory that was checked"`
+	Checked int         `json:"checked" jsonschema:"Number of ADR files checked"`
+	Issues  []LintIssue `json:"issues" jsonschema:"Problems found (rules adr-sections, adr-status, adr-scope, adr-numbering and adr-links)"`
+}
+
+type ADRsSyncInput struct{}
+
+type ADRsSyncOutput struct {
+	Dir       string           `json:"dir" jsonschema:"ADR directory that was read"`
+	Created   int              `json:"created" jsonschema:"ADRs added from new files"`
+	Updated   int              `json:"updated" jsonschema:"ADRs whose file changed"`
+	Removed   int              `json:"removed" jsonschema:"ADRs whose file was deleted"`
+	Unchanged int              `json:"unchanged" jsonschema:"ADRs already up to date"`
+	Relations int              `json:"relations" jsonschema:"Relations added from the files' status sections"`
+	Skipped   []SkippedADRFile `json:"skipped" jsonschema:"ADR files that could not be read"`
+}
+
+type SkippedADRFile struct {
+	Path   string `json:"path" jsonschema:"File that was skipped"`
+	Reason string `json:"reason" jsonschema:"Why it was skipped"`
 }
 
 // CI management inputs and outputs
 type CIRunTestsInput struct {
-	Scope *string `json:"scope,omitempty" jsonschema:"Test scope to run specific package or directory (e.g., ./cmd/jukebox)"`
+	Scope    *string `json:"scope,omitempty" jsonschema:"Test scope to run specific package or directory (e.g., ./cmd/jukebox), or affected for the packages the changes since base may break; test paths for npm, vitest, jest and pytest, packages for cargo"`
+	Runner   string  `json:"runner,omitempty" jsonschema:"Test runner: go, npm, vitest, jest, pytest or cargo (default MCP_CI_RUNNER, else chosen by project type)"`
+	Base     string  `json:"base,omitempty" jsonschema:"With scope affected, the commit to diff the working tree against (defaults to HEAD)"`
+	Timeout  *string `json:"timeout,omitempty" jsonschema:"How long the run may take, e.g. 90s or 15m (default MCP_CI_TIMEOUT or 10m)"`
+	Coverage bool    `json:"coverage,omitempty" jsonschema:"Collect a cover profile and store per-package and per-function coverage with the run"`
 }
 
 type CIRunTestsOutput struct {
-	Status string `json:"status" jsonschema:"Test execution status (success, failure, running)"`
-	Output string `json:"output" jsonschema:"Test output and results"`
+	RunID    uint              `json:"run_id" jsonschema:"ID of the recorded CI run"`
+	Status   string            `json:"status" jsonschema:"Test execution status (pass, fail, error, timeout or cancelled)"`
+	Runner   string            `json:"runner" jsonschema:"Test runner used"`
+	Passed   int               `json:"passed" jsonschema:"Number of tests that passed"`
+	Failed   int               `json:"failed" jsonschema:"Number of tests that failed"`
+	Skipped  int               `json:"skipped" jsonschema:"Number of tests that were skipped"`
+	Packages []CIPackageResult `json:"packages" jsonschema:"Outcome of each package"`
+	Failures []CITestFailure   `json:"failures" jsonschema:"Failing tests and packages with their output"`
+	Output   string            `json:"output,omitempty" jsonschema:"Output that does not belong to any test, such as build errors"`
+	Coverage *float64          `json:"coverage,omitempty" jsonschema:"Percentage of statements covered, when coverage was collected"`
+	Affected *CIAffected       `json:"affected,omitempty" jsonschema:"With scope affected, the changed files and the packages selected; no run is made when none are"`
+}
+
+type CIAffected struct {
+	Base     string              `json:"base" jsonschema:"Commit the working tree was compared with"`
+	Changed  []string            `json:"changed" jsonschema:"Files changed since base, untracked files included"`
+	Packages []CIAffectedPackage `json:"packages" jsonschema:"Packages selected for testing"`
+}
+
+type CIAffectedPackage struct {
+	Package string `json:"package" jsonschema:"Import path of the package"`
+	Reason  string `json:"reason" jsonschema:"Why it was selected: its changed files, or the affected package it imports"`
+}
+
+type CIPackageResult struct {
+	Package string  `json:"package" jsonschema:"Import path of the package; the test file, module or binary for other runners"`
+	Status  string  `json:"status" jsonschema:"pass, fail or skip (no test files)"`
+	Elapsed float64 `json:"elapsed" jsonschema:"Seconds the package's tests took"`
+}
+
+type CITestFailure struct {
+	Package string  `json:"package" jsonschema:"Import path of the package"`
+	Test    string  `json:"test,omitempty" jsonschema:"Name of the failing test; empty when the package itself failed, e.g. to build"`
+	Elapsed float64 `json:"elapsed" jsonschema:"Seconds the test took"`
+	Output  string  `json:"output" jsonschema:"Output of the test"`
+}
+
+type CIStartInput struct {
+	Scope    *string `json:"scope,omitempty" jsonschema:"Test scope to run specific package or directory (e.g., ./cmd/jukebox), or affected for the packages the changes since base may break; test paths for npm, vitest, jest and pytest, packages for cargo"`
+	Runner   string  `json:"runner,omitempty" jsonschema:"Test runner: go, npm, vitest, jest, pytest or cargo (default MCP_CI_RUNNER, else chosen by project type)"`
+	Base     string  `json:"base,omitempty" jsonschema:"With scope affected, the commit to diff the working tree against (defaults to HEAD)"`
+	Timeout  *string `json:"timeout,omitempty" jsonschema:"How long the run may take, e.g. 90s or 15m (default MCP_CI_TIMEOUT or 10m)"`
+	Coverage bool    `json:"coverage,omitempty" jsonschema:"Collect a cover profile and store per-package and per-function coverage with the run"`
+}
+
+type CIStartOutput struct {
+	JobID    uint        `json:"job_id" jsonschema:"ID of the job, which is also the ID of its CI run"`
+	Status   string      `json:"status" jsonschema:"running, or pass when the affected scope selected no packages; that job is recorded as finished and can be polled too"`
+	Runner   string      `json:"runner" jsonschema:"Test runner used"`
+	Scope    string      `json:"scope" jsonschema:"Test scope being run"`
+	Timeout  string      `json:"timeout" jsonschema:"How long the run may take"`
+	Affected *CIAffected `json:"affected,omitempty" jsonschema:"With scope affected, the changed files and the packages selected"`
+}
+
+type CIStatusInput struct {
+	JobID uint `json:"job_id" jsonschema:"ID of the job returned by ci_start"`
+}
+
+type CIStatusOutput struct {
+	JobID      uint    `json:"job_id" jsonschema:"ID of the job"`
+	Scope      string  `json:"scope" jsonschema:"Test scope being run"`
+	Status     string  `json:"status" jsonschema:"running, pass, fail, error, timeout or cancelled"`
+	Done       bool    `json:"done" jsonschema:"Whether the run has finished"`
+	StartedAt  string  `json:"started_at" jsonschema:"When the run started"`
+	FinishedAt *string `json:"finished_at,omitempty" jsonschema:"When the run finished"`
+	Elapsed    float64 `json:"elapsed" jsonschema:"Seconds the run has taken so far"`
+	Timeout    string  `json:"timeout,omitempty" jsonschema:"How long the run may take"`
+	Passed     int     `json:"passed" jsonschema:"Tests that passed so far"`
+	Failed     int     `json:"failed" jsonschema:"Tests that failed so far"`
+	Skipped    int     `json:"skipped" jsonschema:"Tests that were skipped so far"`
+}
+
+type CIOutputInput struct {
+	JobID  uint `json:"job_id" jsonschema:"ID of the job returned by ci_start"`
+	Offset *int `json:"offset,omitempty" jsonschema:"Byte offset to read from; pass the offset of the previous call to get only new output"`
+}
+
+type CIOutputOutput struct {
+	JobID  uint   `json:"job_id" jsonschema:"ID of the job"`
+	Status string `json:"status" jsonschema:"running, pass, fail, error, timeout or cancelled"`
+	Done   bool   `json:"done" jsonschema:"Whether the run has finished and all of its output has been read"`
+	Output string `json:"output" jsonschema:"Output from the offset on, as go test prints it"`
+	Offset int    `json:"offset" jsonschema:"Offset to pass to the next call"`
+}
+
+type CICancelInput struct {
+	JobID uint `json:"job_id" jsonschema:"ID of the job to cancel"`
+}
+
+type CICancelOutput struct {
+	JobID  uint   `json:"job_id" jsonschema:"ID of the job"`
+	Status string `json:"status" jsonschema:"Status of the run after cancelling, normally cancelled"`
+}
+
+type CIHistoryInput struct {
+	Scope  *string `json:"scope,omitempty" jsonschema:"Only runs of this test scope"`
+	Status string  `json:"status,omitempty" jsonschema:"Only runs with this status (running, pass, fail, error, timeout or cancelled)"`
+	Since  string  `json:"since,omitempty" jsonschema:"Only runs started on or after this date (YYYY-MM-DD)"`
+	Until  string  `json:"until,omitempty" jsonschema:"Only runs started before this date (YYYY-MM-DD)"`
+	Limit  int     `json:"limit,omitempty" jsonschema:"Maximum number of runs to return (defaults to 50)"`
+}
+
+type CIHistoryOutput struct {
+	Runs  []CIRunSummary `json:"runs" jsonschema:"Matching runs, newest first"`
+	Total int64          `json:"total" jsonschema:"Number of matching runs before the limit"`
+}
+
+type CIRunSummary struct {
+	RunID      uint     `json:"run_id" jsonschema:"ID of the run"`
+	Scope      string   `json:"scope" jsonschema:"Test scope that was run"`
+	Status     string   `json:"status" jsonschema:"running, pass, fail, error, timeout or cancelled"`
+	StartedAt  string   `json:"started_at" jsonschema:"When the run started"`
+	FinishedAt *string  `json:"finished_at,omitempty" jsonschema:"When the run finished"`
+	Duration   float64  `json:"duration" jsonschema:"Seconds the run took; 0 while running"`
+	Passed     int      `json:"passed" jsonschema:"Tests that passed"`
+	Failed     int      `json:"failed" jsonschema:"Tests that failed"`
+	Skipped    int      `json:"skipped" jsonschema:"Tests that were skipped"`
+	Coverage   *float64 `json:"coverage,omitempty" jsonschema:"Percentage of statements covered, when coverage was collected"`
+}
+
+type CITrendsInput struct {
+	Scope   *string `json:"scope,omitempty" jsonschema:"Only runs of this test scope"`
+	Package string  `json:"package,omitempty" jsonschema:"Only this package (import path)"`
+	Period  string  `json:"period,omitempty" jsonschema:"Group results by day or week (defaults to week)"`
+	Since   string  `json:"since,omitempty" jsonschema:"Only runs started on or after this date (YYYY-MM-DD; defaults to the last 12 periods)"`
+	Until   string  `json:"until,omitempty" jsonschema:"Only runs started before this date (YYYY-MM-DD)"`
+}
+
+type CITrendsOutput struct {
+	Period   string           `json:"period" jsonschema:"day or week"`
+	Since    string           `json:"since" jsonschema:"Start of the reported range"`
+	Packages []CIPackageTrend `json:"packages" jsonschema:"Trend of each package, by import path"`
+	Slowing  []string         `json:"slowing" jsonschema:"Packages whose median duration in the latest period is more than 20% above the first"`
+}
+
+type CIPackageTrend struct {
+	Package  string         `json:"package" jsonschema:"Import path of the package"`
+	Runs     int            `json:"runs" jsonschema:"Runs the package took part in"`
+	PassRate float64        `json:"pass_rate" jsonschema:"Percentage of those runs the package passed"`
+	P50      float64        `json:"p50" jsonschema:"Median seconds the package's tests took"`
+	P90      float64        `json:"p90" jsonschema:"90th percentile of the seconds the package's tests took"`
+	P99      float64        `json:"p99" jsonschema:"99th percentile of the seconds the package's tests took"`
+	Change   float64        `json:"change" jsonschema:"Percentage change of the median from the first period to the latest"`
+	Points   []CITrendPoint `json:"points" jsonschema:"Pass rate and durations per period, oldest first"`
+}
+
+type CITrendPoint struct {
+	Period   string  `json:"period" jsonschema:"Day (YYYY-MM-DD) or ISO week (e.g. 2025-W27)"`
+	Runs     int     `json:"runs" jsonschema:"Runs of the package in the period"`
+	PassRate float64 `json:"pass_rate" jsonschema:"Percentage of those runs the package passed"`
+	P50      float64 `json:"p50" jsonschema:"Median seconds"`
+	P90      float64 `json:"p90" jsonschema:"90th percentile in seconds"`
+}
+
+type CIFlakyInput struct {
+	Package string  `json:"package,omitempty" jsonschema:"Only tests of this package (import path)"`
+	Since   string  `json:"since,omitempty" jsonschema:"Only runs started on or after this date (YYYY-MM-DD)"`
+	Limit   int     `json:"limit,omitempty" jsonschema:"Maximum number of tests to return"`
+	Rerun   int     `json:"rerun,omitempty" jsonschema:"Retry each failing test of a run this many times (up to 10) to classify it as flaky or deterministic"`
+	RunID   uint    `json:"run_id,omitempty" jsonschema:"Run whose failures to retry (defaults to the latest failed run)"`
+	Timeout *string `json:"timeout,omitempty" jsonschema:"How long each retry may take, e.g. 90s (default MCP_CI_TIMEOUT or 10m)"`
+}
+
+type CIFlakyOutput struct {
+	Tests  []CIFlakyTest   `json:"tests" jsonschema:"Tests that both passed and failed on the same tree, flakiest first"`
+	Reruns []CIRerunResult `json:"reruns" jsonschema:"Outcome of retrying each failing test, when rerun is set"`
+}
+
+type CIFlakyTest struct {
+	Package    string  `json:"package" jsonschema:"Import path of the package"`
+	Test       string  `json:"test" jsonschema:"Name of the test"`
+	Score      float64 `json:"score" jsonschema:"Share (0-1) of the trees the test ran on more than once where it both passed and failed"`
+	Runs       int     `json:"runs" jsonschema:"Times the test passed or failed"`
+	Passed     int     `json:"passed" jsonschema:"Times the test passed"`
+	Failed     int     `json:"failed" jsonschema:"Times the test failed"`
+	Trees      int     `json:"trees" jsonschema:"Distinct working trees the test ran on"`
+	MixedTrees int     `json:"mixed_trees" jsonschema:"Trees on which the test both passed and failed"`
+	LastFlaked string  `json:"last_flaked" jsonschema:"Latest run on a tree where the test both passed and failed"`
+}
+
+type CIRerunResult struct {
+	Package  string `json:"package" jsonschema:"Import path of the package"`
+	Test     string `json:"test" jsonschema:"Name of the test"`
+	RunID    uint   `json:"run_id" jsonschema:"Run the test failed in"`
+	Attempts int    `json:"attempts" jsonschema:"Times the test was retried"`
+	Passed   int    `json:"passed" jsonschema:"Retries that passed"`
+	Failed   int    `json:"failed" jsonschema:"Retries that failed"`
+	Verdict  string `json:"verdict" jsonschema:"flaky if any retry passed, deterministic if all failed, stale if a retry passed but the tree had changed"`
+	Stale    bool   `json:"stale" jsonschema:"The working tree differs from the run's, so a retry that passes may have been fixed; stale retries do not count towards scores"`
+	RerunIDs []uint `json:"rerun_ids" jsonschema:"Recorded runs of the retries"`
+}
+
+type CICoverageInput struct {
+	RunID    uint   `json:"run_id,omitempty" jsonschema:"Run to report on (defaults to the latest run with coverage)"`
+	Baseline uint   `json:"baseline,omitempty" jsonschema:"Run to compare with (defaults to the previous run of the same scope with coverage)"`
+	Package  string `json:"package,omitempty" jsonschema:"Only this package (import path)"`
+	Base     string `json:"base,omitempty" jsonschema:"Git revision to diff the working tree against for the changed-lines check (defaults to HEAD)"`
+}
+
+type CICoverageOutput struct {
+	RunID         uint                 `json:"run_id" jsonschema:"Run reported on"`
+	Scope         string               `json:"scope" jsonschema:"Test scope of the run"`
+	Total         float64              `json:"total" jsonschema:"Percentage of statements covered"`
+	Statements    int                  `json:"statements" jsonschema:"Statements in the reported packages"`
+	Covered       int                  `json:"covered" jsonschema:"Statements covered"`
+	BaselineRunID uint                 `json:"baseline_run_id,omitempty" jsonschema:"Run compared with"`
+	BaselineTotal *float64             `json:"baseline_total,omitempty" jsonschema:"Total coverage of the baseline run"`
+	Delta         *float64             `json:"delta,omitempty" jsonschema:"Change of the total in percentage points since the baseline"`
+	Packages      []CIPackageCoverage  `json:"packages" jsonschema:"Coverage of each package"`
+	Uncovered     []CIFunctionCoverage `json:"uncovered" jsonschema:"Functions no test reaches"`
+	Changed       *CIChangedCoverage   `json:"changed,omitempty" jsonschema:"Coverage of the lines changed in the git diff and of untracked files; absent outside a git repository"`
+}
+
+type CIPackageCoverage struct {
+	Package    string   `json:"package" jsonschema:"Import path of the package"`
+	Percent    float64  `json:"percent" jsonschema:"Percentage of statements covered"`
+	Statements int      `json:"statements" jsonschema:"Statements in the package"`
+	Covered    int      `json:"covered" jsonschema:"Statements covered"`
+	Delta      *float64 `json:"delta,omitempty" jsonschema:"Change in percentage points since the baseline"`
+}
+
+type CIFunctionCoverage struct {
+	Package    string `json:"package" jsonschema:"Import path of the package"`
+	Function   string `json:"function" jsonschema:"Function name, as Func, T.Method or (*T).Method"`
+	File       string `json:"file" jsonschema:"File relative to the repository root"`
+	Line       int    `json:"line" jsonschema:"Line the function starts on"`
+	Statements int    `json:"statements" jsonschema:"Statements in the function"`
+}
+
+type CIChangedCoverage struct {
+	Base      string             `json:"base" jsonschema:"Revision the working tree was diffed against"`
+	Stale     bool               `json:"stale" jsonschema:"Whether the working tree changed since the run, so line numbers may be off"`
+	Lines     int                `json:"lines" jsonschema:"Changed lines that are statements"`
+	Covered   int                `json:"covered" jsonschema:"Changed lines the run covered"`
+	Percent   float64            `json:"percent" jsonschema:"Percentage of changed lines covered"`
+	Uncovered []CIUncoveredLines `json:"uncovered" jsonschema:"Changed lines no test reaches, by file"`
+}
+
+type CIUncoveredLines struct {
+	File  string `json:"file" jsonschema:"File relative to the repository root"`
+	Lines string `json:"lines" jsonschema:"Line numbers and ranges, e.g. 12-14, 20"`
+}
+
+type CIBenchInput struct {
+	Scope          *string  `json:"scope,omitempty" jsonschema:"Packages to benchmark (e.g., ./internal/...)"`
+	Bench          string   `json:"bench,omitempty" jsonschema:"Benchmarks to run, as a -bench pattern (defaults to all)"`
+	Count          int      `json:"count,omitempty" jsonschema:"Samples per benchmark, passed as -count (defaults to 6, at most 50)"`
+	Benchtime      string   `json:"benchtime,omitempty" jsonschema:"Passed as -benchtime, e.g. 1s or 1000x"`
+	Timeout        *string  `json:"timeout,omitempty" jsonschema:"How long the run may take, e.g. 90s or 15m (default MCP_CI_TIMEOUT or 10m)"`
+	RunID          uint     `json:"run_id,omitempty" jsonschema:"Compare a stored benchmark run instead of running the benchmarks"`
+	Baseline       uint     `json:"baseline,omitempty" jsonschema:"Benchmark run to compare with (defaults to the previous run of the same scope)"`
+	BaselineCommit string   `json:"baseline_commit,omitempty" jsonschema:"Compare with the latest benchmark run at this commit (hash or prefix)"`
+	Threshold      *float64 `json:"threshold,omitempty" jsonschema:"Percentage a metric must worsen by, significantly, to be a regression (defaults to 5)"`
+}
+
+type CIBenchOutput struct {
+	RunID          uint                `json:"run_id" jsonschema:"Run the benchmarks were recorded with"`
+	Status         string              `json:"status" jsonschema:"Status of that run"`
+	Commit         string              `json:"commit,omitempty" jsonschema:"Commit the benchmarks ran on"`
+	BaselineRunID  uint                `json:"baseline_run_id,omitempty" jsonschema:"Run compared with"`
+	BaselineCommit string              `json:"baseline_commit,omitempty" jsonschema:"Commit of the baseline run"`
+	Threshold      float64             `json:"threshold" jsonschema:"Regression threshold in percent"`
+	Benchmarks     []CIBenchResult     `json:"benchmarks" jsonschema:"Each benchmark with its metrics"`
+	Regressions    []CIBenchRegression `json:"regressions" jsonschema:"Metrics that got significantly worse by more than the threshold"`
+	Output         string              `json:"output,omitempty" jsonschema:"Output of the run when it did not pass"`
+}
+
+type CIBenchResult struct {
+	Package string          `json:"package" jsonschema:"Import path of the package"`
+	Name    string          `json:"name" jsonschema:"Benchmark name without the GOMAXPROCS suffix"`
+	Samples int             `json:"samples" jsonschema:"Samples taken"`
+	Metrics []CIBenchMetric `json:"metrics" jsonschema:"ns/op, B/op and allocs/op"`
+}
+
+type CIBenchMetric struct {
+	Unit        string   `json:"unit" jsonschema:"ns/op, B/op or allocs/op"`
+	Median      float64  `json:"median" jsonschema:"Median of the samples"`
+	Spread      float64  `json:"spread" jsonschema:"Largest deviation from the median, in percent (the ± of benchstat)"`
+	Baseline    *float64 `json:"baseline,omitempty" jsonschema:"Median of the baseline samples"`
+	Delta       *float64 `json:"delta,omitempty" jsonschema:"Change of the median in percent; positive is worse"`
+	P           *float64 `json:"p,omitempty" jsonschema:"p-value of the Mann-Whitney U test against the baseline"`
+	Significant bool     `json:"significant" jsonschema:"Whether p is below 0.05; otherwise benchstat would print ~"`
+	Regression  bool     `json:"regression" jsonschema:"Whether the change is significant and worse than the threshold"`
+}
+
+type CIBenchRegression struct {
+	Package string  `json:"package" jsonschema:"Import path of the package"`
+	Name    string  `json:"name" jsonschema:"Benchmark name"`
+	Unit    string  `json:"unit" jsonschema:"Metric that regressed"`
+	Delta   float64 `json:"delta" jsonschema:"Change of the median in percent"`
+	P       float64 `json:"p" jsonschema:"p-value of the change"`
+}
+
+type CIVetInput struct {
+	Scope          *string  `json:"scope,omitempty" jsonschema:"Packages to analyze (e.g., ./internal/...)"`
+	Tools          []string `json:"tools,omitempty" jsonschema:"Tools to run: vet, staticcheck, golangci-lint (defaults to vet and whichever linters are installed)"`
+	Timeout        *string  `json:"timeout,omitempty" jsonschema:"How long the tools may take, e.g. 90s or 15m (default MCP_CI_TIMEOUT or 10m)"`
+	RunID          uint     `json:"run_id,omitempty" jsonschema:"Report a stored vet run instead of running the tools"`
+	Baseline       uint     `json:"baseline,omitempty" jsonschema:"Vet run to compare with (defaults to the previous run of the same scope)"`
+	BaselineCommit string   `json:"baseline_commit,omitempty" jsonschema:"Compare with the latest vet run at this commit (hash or prefix)"`
+	All            bool     `json:"all,omitempty" jsonschema:"Also list the findings already in the baseline"`
+}
+
+type CIVetOutput struct {
+	RunID          uint           `json:"run_id" jsonschema:"Vet run the findings were stored with"`
+	Status         string         `json:"status" jsonschema:"pass without findings, fail with findings, error when a tool could not run"`
+	Commit         string         `json:"commit,omitempty" jsonschema:"Commit the tools ran on"`
+	Tools          []string       `json:"tools" jsonschema:"Tools that ran"`
+	BaselineRunID  uint           `json:"baseline_run_id,omitempty" jsonschema:"Run compared with"`
+	BaselineCommit string         `json:"baseline_commit,omitempty" jsonschema:"Commit of the baseline run"`
+	Total          int            `json:"total" jsonschema:"Number of findings in the run"`
+	Fixed          int            `json:"fixed" jsonschema:"Number of baseline findings that are gone"`
+	New            []CIDiagnostic `json:"new" jsonschema:"Findings not in the baseline; all findings without one"`
+	Diagnostics    []CIDiagnostic `json:"diagnostics,omitempty" jsonschema:"Every finding, with all"`
+	Output         string         `json:"output,omitempty" jsonschema:"Other output of the tools, such as errors"`
+}
+
+type CIDiagnostic struct {
+	Tool     string `json:"tool" jsonschema:"vet, staticcheck or golangci-lint"`
+	Analyzer string `json:"analyzer" jsonschema:"Analyzer, check code or linter that reported it; typecheck for errors loading the package"`
+	File     string `json:"file" jsonschema:"File relative to the repository root"`
+	Line     int    `json:"line" jsonschema:"Line number"`
+	Column   int    `json:"column" jsonschema:"Column number"`
+	Message  string `json:"message" jsonschema:"What is wrong"`
 }
 
 type CILastFailureInput struct {
-	RandomString *string `json:"random_string,omitempty" jsonschema:"Dummy parameter for no-parameter tools (optional)"`
+	Scope   *string `json:"scope,omitempty" jsonschema:"Only consider runs of this test scope"`
+	Context *int    `json:"context,omitempty" jsonschema:"Source lines to show around each location (default 3)"`
 }
 
 type CILastFailureOutput struct {
-	Status    string  `json:"status" jsonschema:"Last test failure status"`
-	Scope     *string `json:"scope,omitempty" jsonschema:"Test scope that failed (if available)"`
-	StartedAt *string `json:"started_at,omitempty" jsonschema:"Test start timestamp (if available)"`
+	Status       string            `json:"status" jsonschema:"Status of the failed run (fail, error or timeout), or none"`
+	RunID        uint              `json:"run_id,omitempty" jsonschema:"ID of the failed run"`
+	Scope        *string           `json:"scope,omitempty" jsonschema:"Test scope that failed (if available)"`
+	StartedAt    *string           `json:"started_at,omitempty" jsonschema:"Test start timestamp (if available)"`
+	Failures     []CIFailureDetail `json:"failures" jsonschema:"Failing tests and packages with output excerpts and source locations"`
+	Output       string            `json:"output,omitempty" jsonschema:"Output of the run that does not belong to any test"`
+	LatestRunID  uint              `json:"latest_run_id,omitempty" jsonschema:"Latest run of the same scope"`
+	LatestStatus string            `json:"latest_status,omitempty" jsonschema:"Status of the latest run of the same scope"`
+	StillFailing bool              `json:"still_failing" jsonschema:"Whether any of the failures is still present in the latest run of the same scope"`
+}
+
+type CIFailureDetail struct {
+	Package      string             `json:"package" jsonschema:"Import path of the package"`
+	Test         string             `json:"test,omitempty" jsonschema:"Name of the failing test; empty when the package itself failed"`
+	Excerpt      string             `json:"excerpt" jsonschema:"The relevant part of the output"`
+	Locations    []CISourceLocation `json:"locations" jsonschema:"file:line locations from test output, compiler errors and panics"`
+	StillFailing bool               `json:"still_failing" jsonschema:"Whether this test or package also fails in the latest run of the same scope"`
+}
+
+type CISourceLocation struct {
+	File    string `json:"file" jsonschema:"File relative to the repository root"`
+	Line    int    `json:"line" jsonschema:"Line number"`
+	Snippet string `json:"snippet,omitempty" jsonschema:"Source around the line, with the line marked by >"`
 }
 
 // Repository search inputs and outputs
@@ -106,10 +935,12 @@ type SearchResult struct {
 type StateLogChangeInput struct {
 	Summary string   `json:"summary" jsonschema:"Brief summary of the change made (required)"`
 	Files   []string `json:"files,omitempty" jsonschema:"List of files that were modified"`
+	GoalID  int      `json:"goal_id,omitempty" jsonschema:"Goal the change works towards; the entry is linked to it"`
 }
 
 type StateLogChangeOutput struct {
 	OK bool `json:"ok" jsonschema:"Whether the change was logged successfully"`
+	ID int  `json:"id" jsonschema:"ID of the changelog entry"`
 }
 
 // Markdown linting inputs and outputs
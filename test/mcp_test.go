@@ -87,6 +87,8 @@ func sendRequest(stdin io.WriteCloser, req MCPRequest) error {
 
 func readResponse(stdout io.ReadCloser) (*MCPResponse, error) {
 	scanner := bufio.NewScanner(stdout)
+	// tools/list outgrows the default 64KB line limit
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
 	if !scanner.Scan() {
 		return nil, scanner.Err()
 	}